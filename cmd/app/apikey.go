@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/infrastructure/metrics"
+)
+
+const apiKeyUsage = "usage: app apikey create NAME SCOPE[,SCOPE...] [TENANT]|list|revoke ID"
+
+// APIKey управляет ключами из командной строки. Так выпускается первый ключ
+// с правом admin, когда через API это сделать ещё некому.
+func (app *App) APIKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	validate, err := newValidator()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	service := appservice.NewAPIKeyService(validate, app.storage.apiKeys, metrics.New(), app.cfg.Tenancy.Enabled)
+
+	switch args[0] {
+	case "create":
+		if len(args) != 3 && len(args) != 4 {
+			return errors.New(apiKeyUsage)
+		}
+
+		req := dto.APIKeyRequest{
+			Name:   args[1],
+			Scopes: strings.Split(args[2], ","),
+		}
+		if len(args) == 4 {
+			req.TenantID = args[3]
+		}
+
+		resp, err := service.Issue(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("id: %d\nkey: %s\n", resp.ID, resp.Key)
+		return nil
+	case "list":
+		keys, err := service.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printAPIKeys(keys)
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid id %q: %w", args[1], err)
+		}
+		return service.Revoke(ctx, id)
+	default:
+		return errors.New(apiKeyUsage)
+	}
+}
+
+func printAPIKeys(keys []*dto.APIKeyResponse) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTENANT\tPREFIX\tSCOPES\tCREATED\tREVOKED")
+	for _, key := range keys {
+		revoked := ""
+		if key.RevokedAt != nil {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID,
+			key.Name,
+			key.TenantID,
+			key.Prefix,
+			strings.Join(key.Scopes, ","),
+			key.CreatedAt.Format(time.RFC3339),
+			revoked,
+		)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/infrastructure/metrics"
+	grpchandlers "github.com/noredis/subscriptions/internal/presentation/grpc/handlers"
+	"github.com/noredis/subscriptions/internal/presentation/grpc/interceptors"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// grpcServer — gRPC API с проверкой здоровья по протоколу grpc.health.v1.
+type grpcServer struct {
+	server *grpc.Server
+	health *health.Server
+}
+
+// newGRPCServer регистрирует сервисы gRPC API с теми же аутентификацией,
+// арендаторами, правами и ограничением частоты, что и у HTTP API. Проверка здоровья и рефлексия
+// доступны без аутентификации.
+func (app *App) newGRPCServer(
+	appMetrics *metrics.Metrics,
+	authenticator *appservice.Authenticator,
+	registry *httpext.ProblemRegistry,
+	uni *ut.UniversalTranslator,
+	subscriptionService *appservice.SubscriptionService,
+	costService *appservice.CostService,
+) *grpcServer {
+	public := []string{
+		healthpb.Health_ServiceDesc.ServiceName,
+		reflectionpb.ServerReflection_ServiceDesc.ServiceName,
+		reflectionv1alphapb.ServerReflection_ServiceDesc.ServiceName,
+	}
+
+	chain := []interceptors.Interceptor{
+		interceptors.RequestID(app.logger),
+		interceptors.Metrics(appMetrics),
+		interceptors.Logging(),
+		interceptors.Recovery(),
+	}
+	if app.cfg.RateLimit.Enabled {
+		chain = append(chain, interceptors.Skip(
+			interceptors.RateLimitByIP(app.storage.rateLimits, app.ipRateLimit(), app.logger),
+			public...,
+		))
+	}
+	if authenticator != nil {
+		chain = append(chain,
+			interceptors.Skip(interceptors.Authentication(authenticator, app.logger), public...),
+			interceptors.Skip(interceptors.RequireScopes(grpchandlers.Scopes), public...),
+		)
+	}
+	if app.cfg.Tenancy.Enabled {
+		chain = append(chain, interceptors.Skip(interceptors.Tenant(app.cfg.Tenancy.Header, app.logger), public...))
+	}
+	if app.cfg.RateLimit.Enabled {
+		chain = append(chain, interceptors.RateLimit(app.storage.rateLimits, app.grpcRateLimitRules(), app.logger))
+	}
+
+	server := grpc.NewServer(interceptors.ServerOptions(chain...)...)
+
+	problems := grpchandlers.NewProblems(registry, uni)
+	grpchandlers.NewSubscriptionHandler(subscriptionService, problems).Register(server)
+	grpchandlers.NewCostHandler(costService, problems).Register(server)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	if app.cfg.GRPC.Reflection {
+		reflection.Register(server)
+	}
+
+	return &grpcServer{server: server, health: healthServer}
+}
+
+// grpcRateLimitRules относит методы к классам маршрутов HTTP API по
+// требуемому праву: расчёт стоимости, чтение или запись.
+func (app *App) grpcRateLimitRules() map[string]interceptors.RateLimitRule {
+	limits := app.rateLimits()
+
+	rules := make(map[string]interceptors.RateLimitRule, len(grpchandlers.Scopes))
+	for method, scope := range grpchandlers.Scopes {
+		switch scope {
+		case auth.ScopeCostsRead:
+			rules[method] = interceptors.RateLimitRule{Class: middlewares.RateLimitClassCosts, Limit: limits.Costs}
+		case auth.ScopeSubscriptionsRead:
+			rules[method] = interceptors.RateLimitRule{Class: middlewares.RateLimitClassRead, Limit: limits.Read}
+		default:
+			rules[method] = interceptors.RateLimitRule{Class: middlewares.RateLimitClassWrite, Limit: limits.Write}
+		}
+	}
+	return rules
+}
+
+func (s *grpcServer) serve(port int) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(listener)
+}
+
+// shutdown отвечает NOT_SERVING на проверки здоровья и ждёт завершения
+// вызовов не дольше timeout, после чего обрывает оставшиеся, например
+// незавершённые ListSubscriptions.
+func (s *grpcServer) shutdown(timeout time.Duration) {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.server.Stop()
+		<-done
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/rs/zerolog"
+)
+
+// jobs запускает периодические фоновые задачи и останавливает их вместе
+// с приложением.
+type jobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *zerolog.Logger
+}
+
+func newJobs(logger *zerolog.Logger) *jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &jobs{
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logger,
+	}
+}
+
+// every выполняет run сразу и затем каждые interval, пока задачи не
+// остановлены. Ошибка run логируется и не прерывает задачу. Задачи работают
+// с данными всех арендаторов, в том числе под row-level security.
+func (j *jobs) every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	logger := j.logger.With().Str("job", name).Logger()
+	ctx := tenant.WithAllTenants(logger.WithContext(j.ctx))
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := run(ctx); err != nil && ctx.Err() == nil {
+				logger.Error().Err(err).Msg("job failed")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	logger.Info().Dur("interval", interval).Msg("job started")
+}
+
+// stop отменяет контекст задач и ждёт завершения текущих запусков.
+func (j *jobs) stop() {
+	j.cancel()
+	j.wg.Wait()
+}
@@ -2,32 +2,75 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/noredis/subscriptions/docs"
 	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/dto"
 	"github.com/noredis/subscriptions/internal/common/config"
+	"github.com/noredis/subscriptions/internal/domain/entity"
 	"github.com/noredis/subscriptions/internal/domain/service"
-	"github.com/noredis/subscriptions/internal/infrastructure/repository"
+	"github.com/noredis/subscriptions/internal/infrastructure/metrics"
+	"github.com/noredis/subscriptions/internal/infrastructure/webhook"
+	"github.com/noredis/subscriptions/internal/presentation/graphql"
 	"github.com/noredis/subscriptions/internal/presentation/http/handlers"
 	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
-	"github.com/noredis/subscriptions/pkg/postgres"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/noredis/subscriptions/pkg/jwtauth"
 	"github.com/noredis/subscriptions/pkg/rules"
+	"github.com/noredis/subscriptions/pkg/traceext"
 	"github.com/noredis/subscriptions/pkg/validatorext"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 )
 
+// @title                       Subscriptions Service
+// @description                 REST и GraphQL API для агрегации данных об онлайн подписках пользователей.
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT или API-ключ в формате "Bearer {token}"
 func main() {
-	app := NewApp()
+	storage := flag.String("storage", "", "storage driver (postgres|sqlite|memory), overrides STORAGE_DRIVER")
+	flag.Parse()
+
+	app := NewApp(*storage)
+
+	if args := flag.Args(); len(args) > 0 {
+		var err error
+		switch args[0] {
+		case "migrate":
+			err = app.Migrate(args[1:])
+		case "apikey":
+			err = app.APIKey(args[1:])
+		default:
+			log.Fatal().Msgf("unknown command %q", args[0])
+		}
+
+		app.storage.close()
+		if err != nil {
+			log.Fatal().Err(err).Msgf("%s failed", args[0])
+		}
+		return
+	}
+
+	if app.cfg.Migrate.OnStart && app.storage.migrator != nil {
+		if err := app.storage.migrator.Up(context.Background()); err != nil {
+			log.Fatal().Err(err).Msg("failed to apply migrations")
+		}
+		app.logger.Info().Msg("migrations applied")
+	}
 
 	if err := app.Init(); err != nil {
 		log.Fatal().Err(err).Msg("failed to init app")
@@ -39,65 +82,360 @@ func main() {
 }
 
 type App struct {
-	cfg      *config.Config
-	db       *pgxpool.Pool
-	logger   *zerolog.Logger
-	fiberApp *fiber.App
+	cfg             *config.Config
+	logger          *zerolog.Logger
+	fiberApp        *fiber.App
+	storage         *storage
+	health          *appservice.HealthService
+	streams         *appservice.SubscriptionStreamService
+	grpc            *grpcServer
+	jobs            *jobs
+	closers         []io.Closer
+	shutdownTracing func(context.Context) error
 }
 
-func NewApp() *App {
+func NewApp(storageDriver string) *App {
 	cfg := config.MustLoad()
+	if storageDriver != "" {
+		cfg.Storage.Driver = storageDriver
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid config")
+	}
 	log.Printf("config readed successfully")
 
 	logger := setupLogger(cfg.Logger)
 	logger.Info().Msg("logger configured")
 
-	ctx := context.Background()
-	db, err := postgres.New(ctx, cfg.DB.DSN(), cfg.DB.Attempts, cfg.DB.Delay, logger)
+	storage, err := newStorage(context.Background(), cfg, logger)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to connect to database")
 	}
-	logger.Info().Msg("app successfully connected to db")
+	logger.Info().Str("driver", cfg.Storage.Driver).Msg("app successfully connected to storage")
 
 	return &App{
-		cfg:    cfg,
-		logger: logger,
-		db:     db,
+		cfg:     cfg,
+		logger:  logger,
+		storage: storage,
 	}
 }
 
 func (app *App) Init() error {
-	app.fiberApp = fiber.New()
+	app.fiberApp = fiber.New(fiber.Config{
+		BodyLimit:    app.cfg.App.BodyLimit,
+		ErrorHandler: httpext.ErrorHandler,
+	})
+
+	appMetrics := metrics.New()
+	if app.storage.collector != nil {
+		if err := appMetrics.Register(app.storage.collector); err != nil {
+			return err
+		}
+	}
 
 	app.fiberApp.Use(recover.New())
-	app.fiberApp.Use(middlewares.Logging(app.logger))
+
+	if app.cfg.Tracing.Enabled {
+		shutdown, err := setupTracing(context.Background(), app.cfg.Tracing)
+		if err != nil {
+			return err
+		}
+		app.shutdownTracing = shutdown
+
+		app.fiberApp.Use(middlewares.Tracing())
+		app.logger.Info().Str("exporter", app.cfg.Tracing.Exporter).Msg("tracing enabled")
+	}
+
+	app.fiberApp.Use(middlewares.RequestID(app.logger))
+	app.fiberApp.Use(middlewares.Metrics(appMetrics))
+	app.fiberApp.Use(middlewares.Logging())
 
 	heartbeatHandler := handlers.NewHeartbeatHandler()
 	heartbeatHandler.Register(app.fiberApp)
 
-	validate := validator.New()
-	if err := validate.RegisterValidation("date_format", rules.DateFormat); err != nil {
+	app.health = appservice.NewHealthService(app.cfg.Health.Timeout, app.storage.checks...)
+	healthHandler := handlers.NewHealthHandler(app.health)
+	healthHandler.Register(app.fiberApp)
+
+	app.fiberApp.Get("/swagger/*", fiberSwagger.WrapHandler)
+	app.fiberApp.Get("/metrics", adaptor.HTTPHandler(appMetrics.Handler()))
+
+	validate, err := newValidator()
+	if err != nil {
 		return err
 	}
 
-	validate.RegisterTagNameFunc(validatorext.FieldTag)
+	apiKeyService := appservice.NewAPIKeyService(validate, app.storage.apiKeys, appMetrics, app.cfg.Tenancy.Enabled)
 
-	subscriptionRepo := repository.NewSubscriptionRepository(app.db)
-	subscriptionService := appservice.NewSubscriptionService(validate, subscriptionRepo)
-	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService, app.logger)
+	// Лимит по IP-адресу стоит перед аутентификацией, чтобы подбор ключей
+	// и токенов ограничивался так же, как остальные запросы.
+	if app.cfg.RateLimit.Enabled {
+		app.fiberApp.Use(middlewares.RateLimitByIP(app.storage.rateLimits, app.ipRateLimit(), app.logger))
+	}
+
+	var authenticator *appservice.Authenticator
+	if app.cfg.Auth.Enabled {
+		verifier, err := jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+			Algorithm:   app.cfg.Auth.Algorithm,
+			Secret:      app.cfg.Auth.Secret,
+			JWKSFile:    app.cfg.Auth.JWKSFile,
+			JWKSURL:     app.cfg.Auth.JWKSURL,
+			JWKSRefresh: app.cfg.Auth.JWKSRefresh,
+			Issuer:      app.cfg.Auth.Issuer,
+			Audience:    app.cfg.Auth.Audience,
+		})
+		if err != nil {
+			return err
+		}
+
+		authenticator = appservice.NewAuthenticator(verifier, apiKeyService, appservice.AuthClaims{
+			Roles:     app.cfg.Auth.RolesClaim,
+			AdminRole: app.cfg.Auth.AdminRole,
+			Tenant:    app.cfg.Tenancy.Claim,
+		})
+
+		app.fiberApp.Use(middlewares.Authentication(authenticator, app.logger))
+		app.logger.Info().Str("algorithm", app.cfg.Auth.Algorithm).Msg("authentication enabled")
+	}
+
+	if app.cfg.Tenancy.Enabled {
+		app.fiberApp.Use(middlewares.Tenant(app.cfg.Tenancy.Header, app.logger))
+		app.logger.Info().Str("header", app.cfg.Tenancy.Header).Msg("multi-tenancy enabled")
+	}
+
+	if app.cfg.RateLimit.Enabled {
+		app.fiberApp.Use(middlewares.RateLimit(app.storage.rateLimits, app.rateLimits(), app.logger))
+		app.logger.Info().Str("store", app.cfg.RateLimit.Store).Msg("rate limiting enabled")
+	}
+
+	app.fiberApp.Use(
+		middlewares.Idempotency(
+			app.storage.idempotency,
+			app.cfg.Idempotency.TTL,
+			app.cfg.Idempotency.Lease,
+			app.logger,
+		),
+	)
+
+	uni, err := validatorext.NewTranslator(validate, rules.Translations...)
+	if err != nil {
+		return err
+	}
+
+	problems := handlers.NewProblemRegistry(app.cfg.App.ProblemTypeBaseURI, uni)
+
+	webhookService := appservice.NewWebhookService(
+		validate,
+		app.storage.webhooks,
+		app.storage.deliveries,
+		appMetrics,
+	)
+
+	subscriptionRepo := app.storage.subscriptions
+	subscriptionService := appservice.NewSubscriptionService(
+		validate,
+		subscriptionRepo,
+		app.storage.txManager,
+		appMetrics,
+		app.storage.outbox,
+		app.storage.notifier,
+	)
+	app.streams = appservice.NewSubscriptionStreamService(
+		app.storage.outbox,
+		app.storage.notifier,
+		appMetrics,
+		authenticator,
+		appservice.SubscriptionStreamConfig{
+			PollInterval:      app.cfg.Stream.PollInterval,
+			BatchSize:         app.cfg.Stream.BatchSize,
+			AuthCheckInterval: app.cfg.Stream.AuthCheckInterval,
+			MaxPerPrincipal:   app.cfg.Stream.MaxPerPrincipal,
+		},
+	)
+	subscriptionHandler := handlers.NewSubscriptionHandler(
+		subscriptionService,
+		app.streams,
+		app.cfg.Stream.Heartbeat,
+		problems,
+	)
 	subscriptionHandler.Register(app.fiberApp)
 	log.Printf("VALIDATOR BEFORE: %#v\n", validate)
 
 	calculator := service.NewCostCalculator()
-	costService := appservice.NewCostService(validate, subscriptionRepo, calculator)
-	costHandler := handlers.NewCostHandler(app.logger, costService)
+	costService := appservice.NewCostService(validate, subscriptionRepo, calculator, appMetrics)
+	costHandler := handlers.NewCostHandler(costService, problems)
 	costHandler.Register(app.fiberApp)
 
-	app.fiberApp.Get("/swagger/*", fiberSwagger.WrapHandler)
+	if app.cfg.GraphQL.Enabled {
+		executor, err := graphql.NewExecutor(subscriptionService, costService, app.cfg.GraphQL.MaxComplexity)
+		if err != nil {
+			return err
+		}
+
+		graphQLHandler := handlers.NewGraphQLHandler(executor, problems)
+		graphQLHandler.Register(app.fiberApp)
+	}
+
+	if app.cfg.GRPC.Enabled {
+		app.grpc = app.newGRPCServer(appMetrics, authenticator, problems, uni, subscriptionService, costService)
+	}
+
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, problems)
+	apiKeyHandler.Register(app.fiberApp)
+
+	webhookHandler := handlers.NewWebhookHandler(webhookService, problems)
+	webhookHandler.Register(app.fiberApp)
+
+	dispatcher := appservice.NewWebhookDispatcher(
+		app.storage.webhooks,
+		app.storage.deliveries,
+		webhook.NewHTTPSender(app.cfg.Webhooks.Timeout),
+		appMetrics,
+		appservice.WebhookDispatcherConfig{
+			BatchSize:   app.cfg.Webhooks.BatchSize,
+			Timeout:     app.cfg.Webhooks.Timeout,
+			MaxAttempts: app.cfg.Webhooks.MaxAttempts,
+			BackoffBase: app.cfg.Webhooks.BackoffBase,
+			BackoffMax:  app.cfg.Webhooks.BackoffMax,
+		},
+	)
+
+	sinks, err := app.newEventSinks(webhookService)
+	if err != nil {
+		return err
+	}
+
+	outboxDispatcher := appservice.NewOutboxDispatcher(
+		app.storage.outbox,
+		sinks,
+		appMetrics,
+		appservice.OutboxDispatcherConfig{
+			BatchSize:   app.cfg.Outbox.BatchSize,
+			Lease:       app.cfg.Outbox.Lease,
+			BackoffBase: app.cfg.Outbox.BackoffBase,
+			BackoffMax:  app.cfg.Outbox.BackoffMax,
+		},
+	)
+
+	app.jobs = newJobs(app.logger)
+	app.jobs.every("outbox_dispatch", app.cfg.Outbox.DispatchInterval, func(ctx context.Context) error {
+		for ctx.Err() == nil {
+			n, err := outboxDispatcher.Dispatch(ctx)
+			if err != nil || n < app.cfg.Outbox.BatchSize {
+				return err
+			}
+		}
+		return nil
+	})
+	app.jobs.every("outbox_cleanup", time.Hour, func(ctx context.Context) error {
+		return outboxDispatcher.Cleanup(ctx, app.cfg.Outbox.Retention)
+	})
+	app.jobs.every("idempotency_cleanup", app.cfg.Idempotency.CleanupInterval, func(ctx context.Context) error {
+		deleted, err := app.storage.idempotency.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+		}
+		if deleted > 0 {
+			zerolog.Ctx(ctx).Info().Int("deleted", deleted).Msg("expired idempotency keys deleted")
+		}
+		return nil
+	})
+	if app.cfg.RateLimit.Enabled {
+		app.jobs.every("rate_limit_cleanup", time.Minute, func(ctx context.Context) error {
+			deleted, err := app.storage.rateLimits.DeleteExpired(ctx, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to delete expired rate limit buckets: %w", err)
+			}
+			if deleted > 0 {
+				zerolog.Ctx(ctx).Debug().Int("deleted", deleted).Msg("expired rate limit buckets deleted")
+			}
+			return nil
+		})
+	}
+	app.jobs.every("webhook_dispatch", app.cfg.Webhooks.DispatchInterval, func(ctx context.Context) error {
+		// Пачки отправляются подряд, пока очередь не опустеет.
+		for ctx.Err() == nil {
+			n, err := dispatcher.Dispatch(ctx)
+			if err != nil || n < app.cfg.Webhooks.BatchSize {
+				return err
+			}
+		}
+		return nil
+	})
+	app.jobs.every("subscription_ending_soon", app.cfg.Webhooks.EndingSoonInterval, func(ctx context.Context) error {
+		_, err := subscriptionService.PublishEndingSoon(ctx, time.Now(), app.cfg.Webhooks.EndingSoonWithin)
+		return err
+	})
+
+	if app.cfg.Reminders.Enabled {
+		reminderService := appservice.NewReminderService(
+			subscriptionRepo,
+			app.storage.reminders,
+			app.storage.locker,
+			app.newNotifier(),
+			appMetrics,
+			app.cfg.Reminders.Window,
+		)
+		app.jobs.every("reminders", app.cfg.Reminders.Interval, func(ctx context.Context) error {
+			_, err := reminderService.Run(ctx, time.Now())
+			return err
+		})
+		app.logger.Info().Str("notifier", app.cfg.Reminders.Notifier).Msg("reminders enabled")
+	}
 
 	return nil
 }
 
+func newValidator() (*validator.Validate, error) {
+	validate := validator.New()
+	if err := validate.RegisterValidation("date_format", rules.DateFormat); err != nil {
+		return nil, err
+	}
+	if err := validate.RegisterValidation("date_year", rules.DateYear); err != nil {
+		return nil, err
+	}
+	if err := validate.RegisterValidation("tenant_id", rules.TenantID); err != nil {
+		return nil, err
+	}
+
+	validate.RegisterStructValidation(
+		rules.DateRange("StartDate", "EndDate"),
+		dto.SubscriptionRequest{},
+		dto.SubscriptionFilterDTO{},
+		dto.CostFilterDTO{},
+	)
+
+	validate.RegisterTagNameFunc(validatorext.FieldTag)
+
+	return validate, nil
+}
+
+// rateLimits возвращает лимиты, общие для HTTP и gRPC API.
+func (app *App) rateLimits() middlewares.RateLimits {
+	return middlewares.RateLimits{
+		Read: entity.RateLimit{
+			Limit:  app.cfg.RateLimit.ReadLimit,
+			Period: app.cfg.RateLimit.ReadPeriod,
+		},
+		Write: entity.RateLimit{
+			Limit:  app.cfg.RateLimit.WriteLimit,
+			Period: app.cfg.RateLimit.WritePeriod,
+		},
+		Costs: entity.RateLimit{
+			Limit:  app.cfg.RateLimit.CostsLimit,
+			Period: app.cfg.RateLimit.CostsPeriod,
+		},
+	}
+}
+
+// ipRateLimit возвращает лимит запросов с IP-адреса до аутентификации.
+func (app *App) ipRateLimit() entity.RateLimit {
+	return entity.RateLimit{
+		Limit:  app.cfg.RateLimit.IPLimit,
+		Period: app.cfg.RateLimit.IPPeriod,
+	}
+}
+
 func (app *App) Start() error {
 	app.logger.Info().Msgf("app starting on port %d", app.cfg.App.Port)
 
@@ -107,31 +445,81 @@ func (app *App) Start() error {
 		}
 	}()
 
+	if app.grpc != nil {
+		app.logger.Info().Msgf("grpc server starting on port %d", app.cfg.GRPC.Port)
+
+		go func() {
+			if err := app.grpc.serve(app.cfg.GRPC.Port); err != nil {
+				app.logger.Fatal().Err(err).Msg("failed to start grpc server")
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	<-quit
 
 	app.logger.Info().Msg("received shutdown signal")
 
-	app.db.Close()
+	// Проба готовности начинает отвечать 503 до остановки сервера, чтобы
+	// балансировщик успел убрать экземпляр из ротации.
+	app.health.ShutDown()
+	if app.grpc != nil {
+		app.grpc.health.Shutdown()
+	}
+	if delay := app.cfg.App.ShutdownDelay; delay > 0 {
+		app.logger.Info().Dur("delay", delay).Msg("waiting before shutdown")
+		time.Sleep(delay)
+	}
+
+	err := app.Shutdown()
+
+	app.storage.close()
 	app.logger.Info().Msg("database connection closed")
 
-	return app.Shutdown()
+	return err
 }
 
 func (app *App) Shutdown() error {
 	app.logger.Info().Msg("shutting down...")
 
+	// Открытые потоки изменений не дают серверу завершить ответы.
+	if app.streams != nil {
+		app.streams.Close()
+	}
+
 	if err := app.fiberApp.Shutdown(); err != nil {
 		app.logger.Error().Err(err).Msg("fiber shutdown failed")
 	}
 
+	if app.grpc != nil {
+		app.grpc.shutdown(app.cfg.GRPC.ShutdownTimeout)
+		app.logger.Info().Msg("grpc server stopped")
+	}
+
+	if app.jobs != nil {
+		app.jobs.stop()
+		app.logger.Info().Msg("background jobs stopped")
+	}
+
+	for _, closer := range app.closers {
+		if err := closer.Close(); err != nil {
+			app.logger.Error().Err(err).Msg("failed to close event sink")
+		}
+	}
+
+	if app.shutdownTracing != nil {
+		if err := app.shutdownTracing(context.Background()); err != nil {
+			app.logger.Error().Err(err).Msg("tracing shutdown failed")
+		}
+	}
+
 	app.logger.Info().Msg("shutdown completed")
 	return nil
 }
 
 func setupLogger(cfg config.Logger) *zerolog.Logger {
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger().Hook(traceext.LogHook{})
 
 	level, err := zerolog.ParseLevel(cfg.Level)
 	if err != nil {
@@ -140,5 +528,9 @@ func setupLogger(cfg config.Logger) *zerolog.Logger {
 
 	logger = logger.Level(level)
 
+	// Код вне HTTP-запроса (команды, фоновые задачи) получает через
+	// zerolog.Ctx основной логгер.
+	zerolog.DefaultContextLogger = &logger
+
 	return &logger
 }
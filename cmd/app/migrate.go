@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: app migrate up|down|status|to N|force N"
+
+func (app *App) Migrate(args []string) error {
+	if app.storage.migrator == nil {
+		return fmt.Errorf("storage %q has no migrations", app.cfg.Storage.Driver)
+	}
+
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx := context.Background()
+	migrator := app.storage.migrator
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "status":
+		return app.printMigrationStatus(ctx)
+	case "to", "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+
+		if args[0] == "force" {
+			return migrator.Force(ctx, version)
+		}
+		return migrator.To(ctx, version)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func (app *App) printMigrationStatus(ctx context.Context) error {
+	status, err := app.storage.migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d, dirty: %t\n\n", status.Version, status.Dirty)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, migration.Name, state)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/noredis/subscriptions/internal/common/config"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/notifier"
+	"github.com/noredis/subscriptions/internal/infrastructure/webhook"
+)
+
+// newNotifier создаёт способ отправки напоминаний из REMINDER_NOTIFIER.
+func (app *App) newNotifier() interfaces.Notifier {
+	cfg := app.cfg.Reminders
+
+	switch cfg.Notifier {
+	case config.ReminderNotifierSMTP:
+		return notifier.NewSMTPNotifier(notifier.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			To:       cfg.SMTPTo,
+			Timeout:  cfg.Timeout,
+		})
+	case config.ReminderNotifierWebhook:
+		return notifier.NewWebhookNotifier(
+			cfg.WebhookURL,
+			cfg.WebhookSecret,
+			webhook.NewHTTPSender(cfg.Timeout),
+		)
+	default:
+		return notifier.NewLogNotifier()
+	}
+}
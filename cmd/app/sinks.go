@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/common/config"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/outbox"
+)
+
+// newEventSinks создаёт приёмники событий outbox в порядке OUTBOX_SINKS.
+// Адаптер брокера подключается здесь же через outbox.NewBrokerSink.
+func (app *App) newEventSinks(webhooks *appservice.WebhookService) ([]interfaces.EventSink, error) {
+	sinks := make([]interfaces.EventSink, 0, len(app.cfg.Outbox.Sinks))
+
+	for _, name := range app.cfg.Outbox.Sinks {
+		switch name {
+		case config.OutboxSinkWebhook:
+			sinks = append(sinks, webhooks)
+		case config.OutboxSinkStdout:
+			sinks = append(sinks, outbox.NewStdoutSink())
+		case config.OutboxSinkFile:
+			sink, err := outbox.NewFileSink(app.cfg.Outbox.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			app.closers = append(app.closers, sink)
+			sinks = append(sinks, sink)
+		}
+	}
+
+	app.logger.Info().Strs("sinks", app.cfg.Outbox.Sinks).Msg("outbox sinks configured")
+	return sinks, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/common/config"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/internal/infrastructure/health"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+	"github.com/noredis/subscriptions/internal/infrastructure/repository"
+	sqliterepo "github.com/noredis/subscriptions/internal/infrastructure/sqlite"
+	"github.com/noredis/subscriptions/migrations"
+	"github.com/noredis/subscriptions/pkg/migrate"
+	"github.com/noredis/subscriptions/pkg/postgres"
+	"github.com/noredis/subscriptions/pkg/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/rs/zerolog"
+)
+
+type storage struct {
+	txManager     interfaces.TxManager
+	subscriptions interfaces.SubscriptionRepository
+	idempotency   interfaces.IdempotencyRepository
+	apiKeys       interfaces.APIKeyRepository
+	webhooks      interfaces.WebhookRepository
+	deliveries    interfaces.WebhookDeliveryRepository
+	outbox        interfaces.OutboxRepository
+	notifier      interfaces.OutboxNotifier
+	reminders     interfaces.ReminderRepository
+	locker        interfaces.Locker
+	rateLimits    interfaces.RateLimitStore
+	collector     prometheus.Collector
+	checks        []interfaces.HealthCheck
+	migrator      *migrate.Migrator
+	close         func()
+}
+
+// allTenantsSetting передаёт политикам row-level security, что фоновая задача
+// обходит подписки всех арендаторов.
+func allTenantsSetting(ctx context.Context) string {
+	if tenant.AllTenants(ctx) {
+		return "on"
+	}
+	return "off"
+}
+
+func newStorage(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *zerolog.Logger,
+) (*storage, error) {
+	switch cfg.Storage.Driver {
+	case config.StorageDriverMemory:
+		subscriptions := memory.NewSubscriptionRepository()
+		deliveries := memory.NewWebhookDeliveryRepository()
+		outbox := memory.NewOutboxRepository()
+
+		return &storage{
+			txManager:     memory.NewTxManager(),
+			subscriptions: subscriptions,
+			idempotency:   memory.NewIdempotencyRepository(),
+			apiKeys:       memory.NewAPIKeyRepository(),
+			webhooks:      memory.NewWebhookRepository(deliveries),
+			deliveries:    deliveries,
+			outbox:        outbox,
+			notifier:      memory.NewOutboxNotifier(),
+			reminders:     memory.NewReminderRepository(),
+			locker:        memory.NewLocker(),
+			rateLimits:    memory.NewRateLimitStore(),
+			close:         func() {},
+		}, nil
+	case config.StorageDriverSQLite:
+		db, err := sqlite.New(ctx, cfg.SQLite.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		migrator, err := migrate.New(migrate.NewSQLiteDriver(db), migrations.SQLite(), logger)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		checks := []interfaces.HealthCheck{
+			health.NewPingCheck("sqlite", db.PingContext),
+			health.NewMigrationCheck(migrator),
+		}
+
+		return &storage{
+			txManager:     sqliterepo.NewTxManager(db),
+			subscriptions: sqliterepo.NewSubscriptionRepository(db),
+			idempotency:   sqliterepo.NewIdempotencyRepository(db),
+			apiKeys:       sqliterepo.NewAPIKeyRepository(db),
+			webhooks:      sqliterepo.NewWebhookRepository(db),
+			deliveries:    sqliterepo.NewWebhookDeliveryRepository(db),
+			outbox:        sqliterepo.NewOutboxRepository(db),
+			notifier:      memory.NewOutboxNotifier(),
+			reminders:     sqliterepo.NewReminderRepository(db),
+			locker:        memory.NewLocker(),
+			rateLimits:    memory.NewRateLimitStore(),
+			collector:     collectors.NewDBStatsCollector(db, "sqlite"),
+			checks:        checks,
+			migrator:      migrator,
+			close:         func() { db.Close() },
+		}, nil
+	default:
+		var opts []postgres.Option
+		if cfg.DB.RowSecurity {
+			opts = append(opts,
+				postgres.WithSessionSetting("app.tenant_id", tenant.FromContext),
+				postgres.WithSessionSetting("app.all_tenants", allTenantsSetting),
+			)
+		}
+
+		db, err := postgres.New(ctx, cfg.DB.DSN(), cfg.DB.Attempts, cfg.DB.Delay, logger, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		migrator, err := migrate.New(migrate.NewPostgresDriver(db), migrations.Postgres(), logger)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		var rateLimits interfaces.RateLimitStore = memory.NewRateLimitStore()
+		if cfg.RateLimit.Store == config.RateLimitStorePostgres {
+			rateLimits = repository.NewRateLimitStore(db)
+		}
+
+		listener := repository.NewOutboxListener(db, logger)
+
+		checks := []interfaces.HealthCheck{
+			health.NewPingCheck("postgres", db.Ping),
+			health.NewMigrationCheck(migrator),
+		}
+
+		return &storage{
+			txManager:     repository.NewTxManager(db),
+			subscriptions: repository.NewSubscriptionRepository(db),
+			idempotency:   repository.NewIdempotencyRepository(db),
+			apiKeys:       repository.NewAPIKeyRepository(db),
+			webhooks:      repository.NewWebhookRepository(db),
+			deliveries:    repository.NewWebhookDeliveryRepository(db),
+			outbox:        repository.NewOutboxRepository(db),
+			notifier:      repository.NewOutboxNotifier(db, listener),
+			reminders:     repository.NewReminderRepository(db),
+			locker:        repository.NewLocker(db),
+			rateLimits:    rateLimits,
+			collector:     postgres.NewStatsCollector(db),
+			checks:        checks,
+			migrator:      migrator,
+			close: func() {
+				listener.Close()
+				db.Close()
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/noredis/subscriptions/pkg/httpext"
+)
+
+type Client struct {
+	baseURL string
+	token   string
+	tenant  string
+	http    *http.Client
+}
+
+func NewClient(cfg *Config) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		token:   cfg.Token,
+		tenant:  cfg.Tenant,
+		http:    &http.Client{Timeout: cfg.Timeout.Duration},
+	}
+}
+
+type APIError struct {
+	Status int
+	Body   httpext.FiberError
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d %s: %s", e.Status, http.StatusText(e.Status), e.Body.Error)
+	for _, field := range e.Body.Fields {
+		fmt.Fprintf(&sb, "\n  %s: %s", field.Field, field.Description)
+	}
+	if e.Body.RequestID != "" {
+		fmt.Fprintf(&sb, "\n  request id: %s", e.Body.RequestID)
+	}
+	return sb.String()
+}
+
+func (client *Client) Do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body any,
+	out any,
+) error {
+	u := client.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	}
+	if client.tenant != "" {
+		req.Header.Set("X-Tenant-ID", client.tenant)
+	}
+
+	resp, err := client.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{Status: resp.StatusCode}
+		if err := json.Unmarshal(data, &apiErr.Body); err != nil || apiErr.Body.Error == "" {
+			apiErr.Body.Error = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
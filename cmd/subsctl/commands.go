@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/noredis/subscriptions/internal/application/dto"
+)
+
+type command struct {
+	client *Client
+	output string
+	out    io.Writer
+}
+
+type subscriptionFlags struct {
+	serviceName string
+	price       int
+	userID      string
+	startDate   string
+	endDate     string
+}
+
+func (sf *subscriptionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&sf.serviceName, "service", "", "service name")
+	fs.IntVar(&sf.price, "price", 0, "monthly price")
+	fs.StringVar(&sf.userID, "user", "", "user id (uuid)")
+	fs.StringVar(&sf.startDate, "start", "", "start date (MM-YYYY)")
+	fs.StringVar(&sf.endDate, "end", "", "end date (MM-YYYY)")
+}
+
+// apply переносит в запрос только явно заданные флаги.
+func (sf *subscriptionFlags) apply(fs *flag.FlagSet, req *dto.SubscriptionRequest) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "service":
+			req.ServiceName = sf.serviceName
+		case "price":
+			req.Price = sf.price
+		case "user":
+			req.UserID = sf.userID
+		case "start":
+			req.StartDate = sf.startDate
+		case "end":
+			req.EndDate = sf.endDate
+		}
+	})
+}
+
+type filterFlags struct {
+	serviceName string
+	userID      string
+	startDate   string
+	endDate     string
+}
+
+func (ff *filterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&ff.serviceName, "service", "", "filter by service name")
+	fs.StringVar(&ff.userID, "user", "", "filter by user id")
+	fs.StringVar(&ff.startDate, "start", "", "period start (MM-YYYY)")
+	fs.StringVar(&ff.endDate, "end", "", "period end (MM-YYYY)")
+}
+
+func (ff *filterFlags) query() url.Values {
+	q := url.Values{}
+	for key, value := range map[string]string{
+		"service_name": ff.serviceName,
+		"user_id":      ff.userID,
+		"start_date":   ff.startDate,
+		"end_date":     ff.endDate,
+	} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	return q
+}
+
+func (cmd *command) create(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	var sf subscriptionFlags
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req := dto.SubscriptionRequest{}
+	sf.apply(fs, &req)
+
+	var resp dto.SubscriptionResponse
+	if err := cmd.client.Do(ctx, http.MethodPost, "/subscriptions", nil, req, &resp); err != nil {
+		return err
+	}
+
+	return render(cmd.out, cmd.output, resp, subscriptionsTable(&resp))
+}
+
+func (cmd *command) get(ctx context.Context, args []string) error {
+	id, err := idArg(args)
+	if err != nil {
+		return err
+	}
+
+	var resp dto.SubscriptionResponse
+	if err := cmd.client.Do(ctx, http.MethodGet, subscriptionPath(id), nil, nil, &resp); err != nil {
+		return err
+	}
+
+	return render(cmd.out, cmd.output, resp, subscriptionsTable(&resp))
+}
+
+func (cmd *command) list(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	var ff filterFlags
+	ff.register(fs)
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 20, "page size")
+	all := fs.Bool("all", false, "fetch all pages")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	q := ff.query()
+	q.Set("limit", strconv.Itoa(*limit))
+
+	subs := make([]*dto.SubscriptionResponse, 0)
+	for p := *page; ; p++ {
+		q.Set("page", strconv.Itoa(p))
+
+		var resp dto.SubscriptionListResponse
+		if err := cmd.client.Do(ctx, http.MethodGet, "/subscriptions", q, nil, &resp); err != nil {
+			return err
+		}
+
+		subs = append(subs, resp.Data...)
+		if !*all || len(resp.Data) == 0 || p*resp.Limit >= resp.Total {
+			break
+		}
+	}
+
+	return render(cmd.out, cmd.output, subs, subscriptionsTable(subs...))
+}
+
+func (cmd *command) update(ctx context.Context, args []string) error {
+	id, err := idArg(args)
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	var sf subscriptionFlags
+	sf.register(fs)
+	clearEnd := fs.Bool("clear-end", false, "remove end date")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var current dto.SubscriptionResponse
+	if err := cmd.client.Do(ctx, http.MethodGet, subscriptionPath(id), nil, nil, &current); err != nil {
+		return err
+	}
+
+	req := dto.SubscriptionRequest{
+		ServiceName: current.ServiceName,
+		Price:       current.Price,
+		UserID:      current.UserID,
+		StartDate:   current.StartDate,
+		EndDate:     current.EndDate,
+	}
+	sf.apply(fs, &req)
+	if *clearEnd {
+		req.EndDate = ""
+	}
+
+	var resp dto.SubscriptionResponse
+	if err := cmd.client.Do(ctx, http.MethodPut, subscriptionPath(id), nil, req, &resp); err != nil {
+		return err
+	}
+
+	return render(cmd.out, cmd.output, resp, subscriptionsTable(&resp))
+}
+
+func (cmd *command) delete(ctx context.Context, args []string) error {
+	id, err := idArg(args)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.client.Do(ctx, http.MethodDelete, subscriptionPath(id), nil, nil, nil); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.out, "subscription %d deleted\n", id)
+	return nil
+}
+
+func (cmd *command) cost(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: subsctl cost total|breakdown [flags]")
+	}
+
+	fs := flag.NewFlagSet("cost "+args[0], flag.ContinueOnError)
+	var ff filterFlags
+	ff.register(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "total":
+		var resp dto.TotalCostResponse
+		if err := cmd.client.Do(ctx, http.MethodGet, "/costs/total", ff.query(), nil, &resp); err != nil {
+			return err
+		}
+
+		return render(cmd.out, cmd.output, resp, table{
+			headers: []string{"TOTAL"},
+			rows:    [][]string{{strconv.Itoa(resp.TotalCost)}},
+		})
+	case "breakdown":
+		var resp dto.CostBreakdownResponse
+		err := cmd.client.Do(ctx, http.MethodGet, "/costs/breakdown", ff.query(), nil, &resp)
+		if err != nil {
+			return err
+		}
+
+		return render(cmd.out, cmd.output, resp, breakdownTable(&resp))
+	default:
+		return fmt.Errorf("unknown cost command %q", args[0])
+	}
+}
+
+func idArg(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, errors.New("subscription id is required")
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid subscription id %q", args[0])
+	}
+	return id, nil
+}
+
+func subscriptionPath(id int) string {
+	return fmt.Sprintf("/subscriptions/%d", id)
+}
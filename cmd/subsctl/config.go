@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	defaultBaseURL = "http://localhost:8080"
+	defaultTimeout = 10 * time.Second
+)
+
+type Config struct {
+	BaseURL string   `json:"base_url"`
+	Token   string   `json:"token"`
+	Tenant  string   `json:"tenant"`
+	Output  string   `json:"output"`
+	Timeout Duration `json:"timeout"`
+}
+
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "subsctl", "config.json")
+}
+
+// loadConfig читает конфиг из файла. Отсутствующий файл по пути по умолчанию не считается ошибкой.
+func loadConfig(path string, explicit bool) (*Config, error) {
+	cfg := &Config{
+		BaseURL: defaultBaseURL,
+		Output:  formatTable,
+		Timeout: Duration{defaultTimeout},
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && !explicit:
+		case err != nil:
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		default:
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+			}
+		}
+	}
+
+	if url := os.Getenv("SUBSCTL_URL"); url != "" {
+		cfg.BaseURL = url
+	}
+	if token := os.Getenv("SUBSCTL_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	if tenant := os.Getenv("SUBSCTL_TENANT"); tenant != "" {
+		cfg.Tenant = tenant
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+const usage = `subsctl is a command-line client for the subscriptions service.
+
+Usage:
+  subsctl [global flags] <command> [flags]
+
+Commands:
+  create     --service NAME --price N --user UUID --start MM-YYYY [--end MM-YYYY]
+  get        ID
+  list       [--service NAME] [--user UUID] [--start MM-YYYY] [--end MM-YYYY]
+             [--page N] [--limit N] [--all]
+  update     ID [--service NAME] [--price N] [--user UUID] [--start MM-YYYY]
+             [--end MM-YYYY] [--clear-end]
+  delete     ID
+  cost total|breakdown --start MM-YYYY --end MM-YYYY [--service NAME] [--user UUID]
+
+Global flags:
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	global := flag.NewFlagSet("subsctl", flag.ContinueOnError)
+	global.Usage = func() {
+		fmt.Fprint(global.Output(), usage)
+		global.PrintDefaults()
+	}
+
+	configPath := global.String("config", defaultConfigPath(), "path to config file")
+	baseURL := global.String("url", "", "service base URL (overrides config and SUBSCTL_URL)")
+	token := global.String("token", "", "bearer token (overrides config and SUBSCTL_TOKEN)")
+	tenant := global.String("tenant", "", "tenant id (overrides config and SUBSCTL_TENANT)")
+	output := global.String("o", "", "output format: table, json or csv")
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+
+	explicit := false
+	global.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "config"
+	})
+
+	cfg, err := loadConfig(*configPath, explicit)
+	if err != nil {
+		return err
+	}
+	if *baseURL != "" {
+		cfg.BaseURL = *baseURL
+	}
+	if *token != "" {
+		cfg.Token = *token
+	}
+	if *tenant != "" {
+		cfg.Tenant = *tenant
+	}
+	if *output != "" {
+		cfg.Output = *output
+	}
+
+	rest := global.Args()
+	if len(rest) == 0 {
+		global.Usage()
+		return flag.ErrHelp
+	}
+
+	cmd := &command{
+		client: NewClient(cfg),
+		output: cfg.Output,
+		out:    os.Stdout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	handlers := map[string]func(context.Context, []string) error{
+		"create": cmd.create,
+		"get":    cmd.get,
+		"list":   cmd.list,
+		"update": cmd.update,
+		"delete": cmd.delete,
+		"cost":   cmd.cost,
+	}
+
+	handler, ok := handlers[rest[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run subsctl -h for usage", rest[0])
+	}
+	return handler(ctx, rest[1:])
+}
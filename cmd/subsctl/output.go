@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/noredis/subscriptions/internal/application/dto"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func render(w io.Writer, format string, raw any, t table) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(raw)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.headers); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func subscriptionsTable(subs ...*dto.SubscriptionResponse) table {
+	t := table{
+		headers: []string{"ID", "SERVICE", "PRICE", "USER", "START", "END"},
+		rows:    make([][]string, 0, len(subs)),
+	}
+
+	for _, sub := range subs {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(sub.ID),
+			sub.ServiceName,
+			strconv.Itoa(sub.Price),
+			sub.UserID,
+			sub.StartDate,
+			sub.EndDate,
+		})
+	}
+
+	return t
+}
+
+func breakdownTable(breakdown *dto.CostBreakdownResponse) table {
+	t := table{
+		headers: []string{"ID", "SERVICE", "USER", "PRICE", "MONTHS", "COST"},
+		rows:    make([][]string, 0, len(breakdown.Items)+1),
+	}
+
+	for _, item := range breakdown.Items {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(item.SubscriptionID),
+			item.ServiceName,
+			item.UserID,
+			strconv.Itoa(item.Price),
+			strconv.Itoa(item.Months),
+			strconv.Itoa(item.Cost),
+		})
+	}
+
+	t.rows = append(t.rows, []string{"", "TOTAL", "", "", "", strconv.Itoa(breakdown.TotalCost)})
+	return t
+}
@@ -0,0 +1,232 @@
+package appservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+const (
+	// APIKeyPrefix отличает API-ключи от JWT в заголовке Authorization.
+	APIKeyPrefix = "sk_"
+	// APIKeySubjectPrefix предшествует идентификатору ключа в Principal.Subject.
+	APIKeySubjectPrefix = "api-key:"
+
+	apiKeySecretBytes  = 32
+	apiKeyDisplayChars = 8
+)
+
+type APIKeyService struct {
+	validate      *validator.Validate
+	repo          interfaces.APIKeyRepository
+	metrics       interfaces.Metrics
+	requireTenant bool
+}
+
+// NewAPIKeyService создаёт сервис ключей. При requireTenant (включённой
+// мультиарендности) ключи без арендатора не выпускаются и не принимаются.
+func NewAPIKeyService(
+	validate *validator.Validate,
+	repo interfaces.APIKeyRepository,
+	metrics interfaces.Metrics,
+	requireTenant bool,
+) *APIKeyService {
+	return &APIKeyService{
+		validate:      validate,
+		repo:          repo,
+		metrics:       metrics,
+		requireTenant: requireTenant,
+	}
+}
+
+// Issue выпускает новый ключ и возвращает его в открытом виде.
+func (service *APIKeyService) Issue(
+	ctx context.Context,
+	req dto.APIKeyRequest,
+) (*dto.IssuedAPIKeyResponse, error) {
+	if err := validateStruct(service.validate, service.metrics, req); err != nil {
+		return nil, err
+	}
+
+	// Пользователь, закреплённый за арендатором, выпускает ключи только для него.
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Tenant != "" {
+		if req.TenantID != "" && req.TenantID != principal.Tenant {
+			return nil, failure.ErrForbidden
+		}
+		req.TenantID = principal.Tenant
+	}
+	if service.requireTenant && req.TenantID == "" {
+		return nil, failure.ErrAPIKeyTenantRequired
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	raw := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+
+	key, err := service.repo.Insert(keysContext(ctx), &entity.APIKey{
+		Name:      req.Name,
+		TenantID:  req.TenantID,
+		Prefix:    raw[:len(APIKeyPrefix)+apiKeyDisplayChars],
+		KeyHash:   hashAPIKey(raw),
+		Scopes:    slices.Compact(scopes),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.IssuedAPIKeyResponse{
+		APIKeyResponse: *service.mapFromEntity(key),
+		Key:            raw,
+	}, nil
+}
+
+func (service *APIKeyService) List(ctx context.Context) ([]*dto.APIKeyResponse, error) {
+	keys, err := service.repo.FindAll(keysContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		if visible(ctx, key) {
+			resp = append(resp, service.mapFromEntity(key))
+		}
+	}
+	return resp, nil
+}
+
+func (service *APIKeyService) Revoke(ctx context.Context, id int) error {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Tenant != "" {
+		keys, err := service.repo.FindAll(keysContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(keys, func(key *entity.APIKey) bool {
+			return key.ID == id && visible(ctx, key)
+		}) {
+			return failure.ErrAPIKeyNotFound
+		}
+	}
+
+	return service.repo.Revoke(keysContext(ctx), id, time.Now().UTC())
+}
+
+// Authenticate находит действующий ключ и возвращает соответствующего ему пользователя.
+// Ключи принадлежат внутренним сервисам, поэтому не ограничены подписками одного
+// пользователя. Администратором ключ становится только с правом admin.
+func (service *APIKeyService) Authenticate(
+	ctx context.Context,
+	raw string,
+) (*auth.Principal, error) {
+	if !strings.HasPrefix(raw, APIKeyPrefix) {
+		return nil, failure.ErrInvalidAPIKey
+	}
+
+	// Арендатор запроса определяется ключом, поэтому ключ ищется среди ключей
+	// всех арендаторов.
+	key, err := service.repo.FindByHash(tenant.WithAllTenants(ctx), hashAPIKey(raw))
+	if err != nil {
+		if errors.Is(err, failure.ErrAPIKeyNotFound) {
+			return nil, failure.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt != nil {
+		return nil, failure.ErrInvalidAPIKey
+	}
+
+	// Ключ, выпущенный до включения мультиарендности, не должен выбирать
+	// арендатора заголовком.
+	if service.requireTenant && key.TenantID == "" {
+		return nil, failure.ErrInvalidAPIKey
+	}
+
+	return &auth.Principal{
+		Subject: APIKeySubjectPrefix + strconv.Itoa(key.ID),
+		Admin:   slices.Contains(key.Scopes, auth.ScopeAdmin),
+		Service: true,
+		Tenant:  key.TenantID,
+		Scopes:  key.Scopes,
+	}, nil
+}
+
+// Check возвращает failure.ErrInvalidAPIKey, если ключ пользователя subject
+// удалён или отозван.
+func (service *APIKeyService) Check(ctx context.Context, subject string) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(subject, APIKeySubjectPrefix))
+	if err != nil {
+		return failure.ErrInvalidAPIKey
+	}
+
+	key, err := service.repo.FindByID(tenant.WithAllTenants(ctx), id)
+	if err != nil {
+		if errors.Is(err, failure.ErrAPIKeyNotFound) {
+			return failure.ErrInvalidAPIKey
+		}
+		return err
+	}
+
+	if key.RevokedAt != nil {
+		return failure.ErrInvalidAPIKey
+	}
+	return nil
+}
+
+func (service *APIKeyService) mapFromEntity(key *entity.APIKey) *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		TenantID:  key.TenantID,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
+
+// visible сообщает, виден ли ключ пользователю: закреплённый за арендатором
+// пользователь видит только ключи этого арендатора.
+// keysContext возвращает контекст управления ключами. Администратор без
+// арендатора работает с ключами всех арендаторов, остальные - только с
+// ключами своего арендатора.
+func keysContext(ctx context.Context) context.Context {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Tenant != "" {
+		return tenant.WithTenant(ctx, principal.Tenant)
+	}
+	return tenant.WithAllTenants(ctx)
+}
+
+func visible(ctx context.Context, key *entity.APIKey) bool {
+	principal, ok := auth.PrincipalFrom(ctx)
+	return !ok || principal.Tenant == "" || principal.Tenant == key.TenantID
+}
+
+// hashAPIKey хэширует ключ. У ключа 256 бит энтропии, поэтому медленный KDF не нужен.
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
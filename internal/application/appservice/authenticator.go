@@ -0,0 +1,115 @@
+package appservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/pkg/jwtauth"
+)
+
+// userScopes выдаются пользователям с JWT. Администратор дополнительно получает auth.ScopeAdmin.
+var userScopes = []string{
+	auth.ScopeSubscriptionsRead,
+	auth.ScopeSubscriptionsWrite,
+	auth.ScopeCostsRead,
+}
+
+// AuthClaims задаёт claims JWT, из которых читаются роли и арендатор пользователя.
+type AuthClaims struct {
+	Roles     string
+	AdminRole string
+	Tenant    string
+}
+
+// Authenticator определяет пользователя по JWT или API-ключу. Его используют
+// и HTTP, и gRPC API.
+type Authenticator struct {
+	verifier *jwtauth.Verifier
+	apiKeys  *APIKeyService
+	claims   AuthClaims
+}
+
+func NewAuthenticator(verifier *jwtauth.Verifier, apiKeys *APIKeyService, claims AuthClaims) *Authenticator {
+	return &Authenticator{
+		verifier: verifier,
+		apiKeys:  apiKeys,
+		claims:   claims,
+	}
+}
+
+// Authenticate возвращает пользователя токена. Для неверного API-ключа
+// возвращается failure.ErrInvalidAPIKey, для неверного JWT - ошибка,
+// оборачивающая failure.ErrInvalidToken.
+func (authenticator *Authenticator) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		return authenticator.apiKeys.Authenticate(ctx, token)
+	}
+
+	claims, err := authenticator.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", failure.ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", failure.ErrInvalidToken)
+	}
+
+	tenantID, _ := claims[authenticator.claims.Tenant].(string)
+
+	var expiresAt time.Time
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+
+	principal := &auth.Principal{
+		Subject:   subject,
+		Admin:     slices.Contains(roles(claims, authenticator.claims.Roles), authenticator.claims.AdminRole),
+		Tenant:    tenantID,
+		Scopes:    userScopes,
+		ExpiresAt: expiresAt,
+	}
+	if principal.Admin {
+		principal.Scopes = append(slices.Clone(userScopes), auth.ScopeAdmin)
+	}
+
+	return principal, nil
+}
+
+// Check проверяет, что учётные данные, по которым ранее аутентифицирован
+// principal, всё ещё действуют: JWT не истёк, а API-ключ не отозван. Его
+// вызывают долгие соединения, которые переживают срок действия учётных данных.
+func (authenticator *Authenticator) Check(ctx context.Context, principal *auth.Principal) error {
+	if !principal.ExpiresAt.IsZero() && !time.Now().Before(principal.ExpiresAt) {
+		return fmt.Errorf("%w: token is expired", failure.ErrInvalidToken)
+	}
+
+	if strings.HasPrefix(principal.Subject, APIKeySubjectPrefix) {
+		return authenticator.apiKeys.Check(ctx, principal.Subject)
+	}
+	return nil
+}
+
+// roles читает роли из claim в виде массива строк или строки, разделённой пробелами.
+func roles(claims jwt.MapClaims, claim string) []string {
+	switch value := claims[claim].(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, role := range value {
+			if s, ok := role.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package appservice
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"github.com/rs/zerolog"
+)
+
+type BatchResult struct {
+	Committed  bool
+	Operations []BatchOperationResult
+}
+
+type BatchOperationResult struct {
+	Op   string
+	Data *dto.SubscriptionResponse
+	Err  error
+}
+
+// Batch выполняет операции в одной транзакции. При ошибке любой операции
+// транзакция откатывается, а остальные операции помечаются ErrBatchRolledBack.
+func (service *SubscriptionService) Batch(
+	ctx context.Context,
+	req dto.BatchRequest,
+) (_ *BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Batch")
+	defer func() { traceext.End(span, err) }()
+
+	if err := validateStruct(service.validate, service.metrics, req); err != nil {
+		return nil, err
+	}
+
+	result := &BatchResult{
+		Operations: make([]BatchOperationResult, len(req.Operations)),
+	}
+
+	failed := -1
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, op := range req.Operations {
+			data, err := service.apply(ctx, op)
+			result.Operations[i] = BatchOperationResult{Op: op.Op, Data: data, Err: err}
+			if err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+
+	if failed >= 0 {
+		zerolog.Ctx(ctx).Info().
+			Err(result.Operations[failed].Err).
+			Int("index", failed).
+			Msg("batch rolled back")
+
+		for i, op := range req.Operations {
+			if i != failed {
+				result.Operations[i] = BatchOperationResult{Op: op.Op, Err: failure.ErrBatchRolledBack}
+			}
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	service.recordBatch(req)
+	service.notify(ctx)
+	return result, nil
+}
+
+// recordBatch учитывает операции только после фиксации транзакции.
+func (service *SubscriptionService) recordBatch(req dto.BatchRequest) {
+	var created, deleted int
+	for _, op := range req.Operations {
+		switch op.Op {
+		case dto.BatchOpCreate:
+			created++
+		case dto.BatchOpDelete:
+			deleted++
+		}
+	}
+
+	if created > 0 {
+		service.metrics.SubscriptionsCreated(created)
+	}
+	if deleted > 0 {
+		service.metrics.SubscriptionsDeleted(deleted)
+	}
+}
+
+func (service *SubscriptionService) apply(
+	ctx context.Context,
+	op dto.BatchOperation,
+) (*dto.SubscriptionResponse, error) {
+	if err := validateStruct(service.validate, service.metrics, op); err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case dto.BatchOpCreate:
+		return service.create(ctx, *op.Data)
+	case dto.BatchOpUpdate:
+		return service.update(ctx, *op.Data, op.ID)
+	default:
+		return nil, service.delete(ctx, op.ID)
+	}
+}
@@ -0,0 +1,219 @@
+package appservice_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/event"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+	"github.com/noredis/subscriptions/internal/presentation/http/handlers"
+	"github.com/noredis/subscriptions/pkg/rules"
+)
+
+const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+// trackingTxManager отмечает фиксацию транзакции верхнего уровня. Вложенные
+// транзакции выполняются в ней и ничего не фиксируют.
+type trackingTxManager struct {
+	*memory.TxManager
+
+	depth     int
+	committed bool
+}
+
+func (manager *trackingTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if manager.depth == 0 {
+		manager.committed = false
+	}
+
+	manager.depth++
+	err := manager.TxManager.WithinTransaction(ctx, fn)
+	manager.depth--
+
+	if manager.depth == 0 {
+		manager.committed = err == nil
+	}
+	return err
+}
+
+// batchMetrics запоминает учтённые подписки и то, была ли транзакция
+// зафиксирована к моменту учёта.
+type batchMetrics struct {
+	nopMetrics
+
+	tx      *trackingTxManager
+	created int
+	deleted int
+	early   bool
+}
+
+func (m *batchMetrics) SubscriptionsCreated(count int) {
+	m.created += count
+	m.early = m.early || !m.tx.committed
+}
+
+func (m *batchMetrics) SubscriptionsDeleted(count int) {
+	m.deleted += count
+	m.early = m.early || !m.tx.committed
+}
+
+// batchNotifier запоминает сигналы потокам изменений.
+type batchNotifier struct {
+	*memory.OutboxNotifier
+
+	tx       *trackingTxManager
+	notified int
+	early    bool
+}
+
+func (notifier *batchNotifier) Notify(ctx context.Context) error {
+	notifier.notified++
+	notifier.early = notifier.early || !notifier.tx.committed
+	return notifier.OutboxNotifier.Notify(ctx)
+}
+
+type batchFixture struct {
+	service  *appservice.SubscriptionService
+	repo     *memory.SubscriptionRepository
+	outbox   *memory.OutboxRepository
+	metrics  *batchMetrics
+	notifier *batchNotifier
+}
+
+func newBatchFixture(t *testing.T) *batchFixture {
+	t.Helper()
+
+	validate := validator.New()
+	if err := validate.RegisterValidation("date_format", rules.DateFormat); err != nil {
+		t.Fatalf("register date_format: %v", err)
+	}
+	if err := validate.RegisterValidation("date_year", rules.DateYear); err != nil {
+		t.Fatalf("register date_year: %v", err)
+	}
+
+	tx := &trackingTxManager{TxManager: memory.NewTxManager()}
+	f := &batchFixture{
+		repo:     memory.NewSubscriptionRepository(),
+		outbox:   memory.NewOutboxRepository(),
+		metrics:  &batchMetrics{tx: tx},
+		notifier: &batchNotifier{OutboxNotifier: memory.NewOutboxNotifier(), tx: tx},
+	}
+	f.service = appservice.NewSubscriptionService(validate, f.repo, tx, f.metrics, f.outbox, f.notifier)
+	return f
+}
+
+func createOp(service string) dto.BatchOperation {
+	return dto.BatchOperation{
+		Op: dto.BatchOpCreate,
+		Data: &dto.SubscriptionRequest{
+			ServiceName: service,
+			Price:       400,
+			UserID:      userID,
+			StartDate:   "07-2025",
+		},
+	}
+}
+
+func TestBatchCommitted(t *testing.T) {
+	f := newBatchFixture(t)
+	ctx := context.Background()
+
+	existing, err := f.service.Create(ctx, *createOp("Netflix").Data)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	f.metrics.created, f.notifier.notified = 0, 0
+
+	result, err := f.service.Batch(ctx, dto.BatchRequest{Operations: []dto.BatchOperation{
+		createOp("Yandex Plus"),
+		createOp("Spotify"),
+		{Op: dto.BatchOpDelete, ID: existing.ID},
+	}})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+
+	if !result.Committed {
+		t.Fatal("batch not committed")
+	}
+	for i, op := range result.Operations {
+		if op.Err != nil {
+			t.Fatalf("operation %d error = %v", i, op.Err)
+		}
+	}
+
+	if f.metrics.created != 2 || f.metrics.deleted != 1 {
+		t.Fatalf("metrics created = %d, deleted = %d, want 2 and 1", f.metrics.created, f.metrics.deleted)
+	}
+	if f.notifier.notified != 1 {
+		t.Fatalf("notified = %d, want 1", f.notifier.notified)
+	}
+	if f.metrics.early || f.notifier.early {
+		t.Fatal("metrics or streams notified before commit")
+	}
+}
+
+func TestBatchRolledBack(t *testing.T) {
+	f := newBatchFixture(t)
+	ctx := context.Background()
+
+	result, err := f.service.Batch(ctx, dto.BatchRequest{Operations: []dto.BatchOperation{
+		createOp("Yandex Plus"),
+		{Op: dto.BatchOpDelete, ID: 42},
+		createOp("Spotify"),
+	}})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+
+	if result.Committed {
+		t.Fatal("failed batch committed")
+	}
+	if !errors.Is(result.Operations[1].Err, failure.ErrSubscriptionNotFound) {
+		t.Fatalf("failed operation error = %v, want ErrSubscriptionNotFound", result.Operations[1].Err)
+	}
+
+	english := en.New()
+	uni := ut.New(english, english)
+	trans, _ := uni.GetTranslator("en")
+	problems := handlers.NewProblemRegistry("", uni)
+	for _, i := range []int{0, 2} {
+		op := result.Operations[i]
+		if !errors.Is(op.Err, failure.ErrBatchRolledBack) || op.Data != nil {
+			t.Fatalf("operation %d = %+v, want ErrBatchRolledBack without data", i, op)
+		}
+		if status := problems.ResolveIn(op.Err, trans).Status; status != http.StatusFailedDependency {
+			t.Fatalf("operation %d status = %d, want 424", i, status)
+		}
+	}
+
+	// Подписка первой операции отменена вместе с её событием.
+	subs, err := f.repo.FindAll(ctx, &entity.SubscriptionFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("subscriptions = %d, want 0", len(subs))
+	}
+	messages, err := f.outbox.FindAfter(ctx, 0, []string{event.TypeSubscriptionCreated}, 10)
+	if err != nil {
+		t.Fatalf("FindAfter: %v", err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("outbox messages = %d, want 0", len(messages))
+	}
+
+	if f.metrics.created != 0 || f.metrics.deleted != 0 || f.notifier.notified != 0 {
+		t.Fatalf("metrics created = %d, deleted = %d, notified = %d, want none",
+			f.metrics.created, f.metrics.deleted, f.notifier.notified)
+	}
+}
@@ -5,38 +5,54 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/internal/application/auth"
 	"github.com/noredis/subscriptions/internal/application/dto"
 	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
 	"github.com/noredis/subscriptions/internal/domain/interfaces"
 	"github.com/noredis/subscriptions/internal/domain/service"
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"github.com/rs/zerolog"
 )
 
 type CostService struct {
 	validate   *validator.Validate
 	repo       interfaces.SubscriptionRepository
 	calculator *service.CostCalculator
+	metrics    interfaces.Metrics
 }
 
 func NewCostService(
 	validate *validator.Validate,
 	repo interfaces.SubscriptionRepository,
 	calculator *service.CostCalculator,
+	metrics interfaces.Metrics,
 ) *CostService {
 	return &CostService{
 		validate:   validate,
 		repo:       repo,
 		calculator: calculator,
+		metrics:    metrics,
 	}
 }
 
 func (service *CostService) Total(
 	ctx context.Context,
 	f dto.CostFilterDTO,
-) (*dto.TotalCostResponse, error) {
-	if err := service.validate.Struct(f); err != nil {
+) (_ *dto.TotalCostResponse, err error) {
+	ctx, span := tracer.Start(ctx, "CostService.Total")
+	defer func() { traceext.End(span, err) }()
+
+	if err := validateStruct(service.validate, service.metrics, f); err != nil {
 		return nil, err
 	}
 
+	userID, ok := auth.ScopeUserID(ctx, f.UserID)
+	if !ok {
+		return nil, failure.ErrForbidden
+	}
+	f.UserID = userID
+
 	filters, err := service.mapFiltersToEntity(f)
 	if err != nil {
 		return nil, err
@@ -46,9 +62,122 @@ func (service *CostService) Total(
 	if err != nil {
 		return nil, err
 	}
+	service.metrics.CostQueried("total", len(subscriptions))
+
+	total := service.calculator.TotalCost(subscriptions, *filters.StartDate, *filters.EndDate)
+	zerolog.Ctx(ctx).Debug().
+		Int("subscriptions", len(subscriptions)).
+		Int("total_cost", total).
+		Msg("total cost calculated")
+
+	return &dto.TotalCostResponse{TotalCost: total}, nil
+}
+
+func (service *CostService) Breakdown(
+	ctx context.Context,
+	f dto.CostFilterDTO,
+) (_ *dto.CostBreakdownResponse, err error) {
+	ctx, span := tracer.Start(ctx, "CostService.Breakdown")
+	defer func() { traceext.End(span, err) }()
+
+	if err := validateStruct(service.validate, service.metrics, f); err != nil {
+		return nil, err
+	}
+
+	userID, ok := auth.ScopeUserID(ctx, f.UserID)
+	if !ok {
+		return nil, failure.ErrForbidden
+	}
+	f.UserID = userID
+
+	filters, err := service.mapFiltersToEntity(f)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := service.repo.FindAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	service.metrics.CostQueried("breakdown", len(subscriptions))
+
+	resp := &dto.CostBreakdownResponse{
+		Items: make([]*dto.CostBreakdownItem, 0, len(subscriptions)),
+	}
+
+	for _, sub := range subscriptions {
+		months := service.calculator.Months(sub, *filters.StartDate, *filters.EndDate)
+		item := &dto.CostBreakdownItem{
+			SubscriptionID: sub.ID,
+			ServiceName:    sub.ServiceName,
+			UserID:         sub.UserID,
+			Price:          sub.Price,
+			Months:         months,
+			Cost:           months * sub.Price,
+		}
+
+		resp.TotalCost += item.Cost
+		resp.Items = append(resp.Items, item)
+	}
+
+	zerolog.Ctx(ctx).Debug().
+		Int("subscriptions", len(subscriptions)).
+		Int("total_cost", resp.TotalCost).
+		Msg("cost breakdown calculated")
+
+	return resp, nil
+}
+
+// SubscriptionCost считает стоимость уже прочитанной подписки за период из
+// f без обращения к хранилищу. Остальные поля f не учитываются.
+func (service *CostService) SubscriptionCost(
+	ctx context.Context,
+	sub *dto.SubscriptionResponse,
+	f dto.CostFilterDTO,
+) (_ *dto.CostBreakdownItem, err error) {
+	ctx, span := tracer.Start(ctx, "CostService.SubscriptionCost")
+	defer func() { traceext.End(span, err) }()
+
+	if err := validateStruct(service.validate, service.metrics, f); err != nil {
+		return nil, err
+	}
+
+	if !auth.CanAccess(ctx, sub.UserID) {
+		return nil, failure.ErrForbidden
+	}
+
+	filters, err := service.mapFiltersToEntity(dto.CostFilterDTO{StartDate: f.StartDate, EndDate: f.EndDate})
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := time.Parse(dateFormat, sub.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var endDate *time.Time
+	if sub.EndDate != "" {
+		date, err := time.Parse(dateFormat, sub.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		endDate = &date
+	}
+
+	months := service.calculator.Months(&entity.Subscription{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}, *filters.StartDate, *filters.EndDate)
 
-	return &dto.TotalCostResponse{
-		TotalCost: service.calculator.TotalCost(subscriptions, *filters.StartDate, *filters.EndDate),
+	return &dto.CostBreakdownItem{
+		SubscriptionID: sub.ID,
+		ServiceName:    sub.ServiceName,
+		UserID:         sub.UserID,
+		Price:          sub.Price,
+		Months:         months,
+		Cost:           months * sub.Price,
 	}, nil
 }
 
@@ -0,0 +1,87 @@
+package appservice
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/rs/zerolog"
+)
+
+const shutdownComponent = "shutdown"
+
+type HealthService struct {
+	checks       []interfaces.HealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(timeout time.Duration, checks ...interfaces.HealthCheck) *HealthService {
+	return &HealthService{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Live сообщает, что процесс работает. Зависимости не проверяются, чтобы
+// недоступная БД не приводила к перезапуску контейнера.
+func (service *HealthService) Live() *dto.HealthResponse {
+	return &dto.HealthResponse{Status: entity.HealthStatusUp}
+}
+
+// Ready выполняет все проверки параллельно и возвращает готовность сервиса
+// вместе со статусом каждой зависимости. Ошибки проверок записываются в журнал
+// и в ответ не попадают.
+func (service *HealthService) Ready(ctx context.Context) (*dto.HealthResponse, bool) {
+	ctx, cancel := context.WithTimeout(ctx, service.timeout)
+	defer cancel()
+
+	results := make([]entity.ComponentHealth, len(service.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range service.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.Check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	resp := &dto.HealthResponse{
+		Status:     entity.HealthStatusUp,
+		Components: make(map[string]dto.ComponentHealth, len(results)+1),
+	}
+
+	for i, result := range results {
+		name := service.checks[i].Name()
+		resp.Components[name] = dto.ComponentHealth{
+			Status:  result.Status,
+			Details: result.Details,
+		}
+		if result.Status != entity.HealthStatusUp {
+			resp.Status = entity.HealthStatusDown
+			zerolog.Ctx(ctx).Warn().
+				Err(result.Err).
+				Str("component", name).
+				Msg("health check failed")
+		}
+	}
+
+	if service.shuttingDown.Load() {
+		resp.Status = entity.HealthStatusDown
+		resp.Components[shutdownComponent] = dto.ComponentHealth{Status: entity.HealthStatusDown}
+	}
+
+	return resp, resp.Status == entity.HealthStatusUp
+}
+
+// ShutDown переводит сервис в состояние "не готов", чтобы балансировщик
+// перестал направлять на него запросы до остановки сервера.
+func (service *HealthService) ShutDown() {
+	service.shuttingDown.Store(true)
+}
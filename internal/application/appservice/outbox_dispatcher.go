@@ -0,0 +1,125 @@
+package appservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/rs/zerolog"
+)
+
+// Исходы отправки сообщения outbox в приёмник для метрик.
+const (
+	sinkOutcomeSucceeded = "succeeded"
+	sinkOutcomeFailed    = "failed"
+)
+
+// OutboxDispatcherConfig задаёт размер пачки, срок, на который сообщение
+// закрепляется за экземпляром сервиса, и расписание повторов.
+type OutboxDispatcherConfig struct {
+	BatchSize   int
+	Lease       time.Duration
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// OutboxDispatcher читает сообщения outbox и отправляет их во все приёмники.
+// Сообщение считается отправленным, когда его приняли все приёмники; при
+// ошибке любого из них оно повторяется целиком, поэтому доставка происходит
+// хотя бы один раз.
+type OutboxDispatcher struct {
+	repo    interfaces.OutboxRepository
+	sinks   []interfaces.EventSink
+	metrics interfaces.Metrics
+	cfg     OutboxDispatcherConfig
+}
+
+func NewOutboxDispatcher(
+	repo interfaces.OutboxRepository,
+	sinks []interfaces.EventSink,
+	metrics interfaces.Metrics,
+	cfg OutboxDispatcherConfig,
+) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		repo:    repo,
+		sinks:   sinks,
+		metrics: metrics,
+		cfg:     cfg,
+	}
+}
+
+// Dispatch отправляет пачку сообщений в порядке их возникновения и
+// возвращает размер пачки.
+func (dispatcher *OutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	messages, err := dispatcher.repo.Claim(ctx, now, now.Add(dispatcher.cfg.Lease), dispatcher.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].OccurredAt.Before(messages[j].OccurredAt)
+	})
+
+	for _, message := range messages {
+		if err := dispatcher.dispatch(ctx, message); err != nil {
+			return len(messages), err
+		}
+	}
+
+	return len(messages), nil
+}
+
+func (dispatcher *OutboxDispatcher) dispatch(ctx context.Context, message *entity.OutboxMessage) error {
+	logger := zerolog.Ctx(ctx).With().
+		Str("event_id", message.ID).
+		Str("event", message.Type).
+		Logger()
+
+	var sendErr error
+	for _, sink := range dispatcher.sinks {
+		if err := sink.Send(ctx, message); err != nil {
+			dispatcher.metrics.OutboxSent(sink.Name(), sinkOutcomeFailed)
+			sendErr = fmt.Errorf("%s: %w", sink.Name(), err)
+			break
+		}
+		dispatcher.metrics.OutboxSent(sink.Name(), sinkOutcomeSucceeded)
+	}
+
+	now := time.Now().UTC()
+	message.Attempts++
+	if sendErr == nil {
+		message.Error = ""
+		message.PublishedAt = &now
+		logger.Debug().Int("attempt", message.Attempts).Msg("outbox message published")
+	} else {
+		message.Error = sendErr.Error()
+		message.NextAttemptAt = now.Add(
+			retryDelay(dispatcher.cfg.BackoffBase, dispatcher.cfg.BackoffMax, message.Attempts),
+		)
+		logger.Warn().
+			Err(sendErr).
+			Int("attempt", message.Attempts).
+			Time("next_attempt_at", message.NextAttemptAt).
+			Msg("failed to publish outbox message")
+	}
+
+	return dispatcher.repo.Update(ctx, message)
+}
+
+// Cleanup удаляет сообщения, отправленные раньше чем retention назад.
+func (dispatcher *OutboxDispatcher) Cleanup(ctx context.Context, retention time.Duration) error {
+	deleted, err := dispatcher.repo.DeletePublished(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("failed to delete published outbox messages: %w", err)
+	}
+
+	if deleted > 0 {
+		zerolog.Ctx(ctx).Info().Int("deleted", deleted).Msg("published outbox messages deleted")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package appservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/service"
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"github.com/rs/zerolog"
+)
+
+// reminderLock — имя блокировки, под которой один экземпляр сервиса
+// рассылает напоминания.
+const reminderLock = "subscriptions.reminders"
+
+// Исходы отправки напоминания для метрик.
+const (
+	reminderOutcomeSent   = "sent"
+	reminderOutcomeFailed = "failed"
+)
+
+// ReminderService напоминает пользователям о предстоящих списаниях и
+// окончании подписок.
+type ReminderService struct {
+	repo      interfaces.SubscriptionRepository
+	reminders interfaces.ReminderRepository
+	locker    interfaces.Locker
+	notifier  interfaces.Notifier
+	metrics   interfaces.Metrics
+	window    time.Duration
+}
+
+func NewReminderService(
+	repo interfaces.SubscriptionRepository,
+	reminders interfaces.ReminderRepository,
+	locker interfaces.Locker,
+	notifier interfaces.Notifier,
+	metrics interfaces.Metrics,
+	window time.Duration,
+) *ReminderService {
+	return &ReminderService{
+		repo:      repo,
+		reminders: reminders,
+		locker:    locker,
+		notifier:  notifier,
+		metrics:   metrics,
+		window:    window,
+	}
+}
+
+// Run отправляет напоминания о списаниях и окончаниях подписок, которые
+// наступят в ближайшее окно, и возвращает число отправленных напоминаний.
+// Рассылку выполняет экземпляр сервиса, захвативший блокировку; каждое
+// напоминание отмечается до отправки, поэтому повторно не отправляется.
+// Напоминание, которое не удалось отправить, повторяется при следующем запуске.
+func (service *ReminderService) Run(ctx context.Context, now time.Time) (sent int, err error) {
+	ctx, span := tracer.Start(ctx, "ReminderService.Run")
+	defer func() { traceext.End(span, err) }()
+
+	unlock, ok, err := service.locker.TryLock(ctx, reminderLock)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		zerolog.Ctx(ctx).Debug().Msg("reminders are being sent by another instance")
+		return 0, nil
+	}
+	defer unlock()
+
+	reminders, err := dueReminders(ctx, service.repo, now, now.Add(service.window))
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, reminder := range reminders {
+		reminder.SentAt = now.UTC()
+
+		ok, err := service.send(ctx, reminder)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// send возвращает false, если напоминание уже было отправлено.
+func (service *ReminderService) send(ctx context.Context, reminder *entity.Reminder) (bool, error) {
+	logger := zerolog.Ctx(ctx).With().
+		Str("kind", reminder.Kind).
+		Int("subscription_id", reminder.Subscription.ID).
+		Str("tenant_id", reminder.Subscription.TenantID).
+		Time("due_date", reminder.DueDate).
+		Logger()
+
+	reserved, err := service.reminders.Reserve(ctx, reminder)
+	if err != nil || !reserved {
+		return false, err
+	}
+
+	if err := service.notifier.Notify(ctx, reminder); err != nil {
+		service.metrics.ReminderSent(reminder.Kind, reminderOutcomeFailed)
+		logger.Warn().Err(err).Msg("failed to send reminder")
+
+		if delErr := service.reminders.Delete(ctx, reminder); delErr != nil {
+			return false, errors.Join(err, delErr)
+		}
+		return false, fmt.Errorf("failed to send %s reminder for subscription %d: %w",
+			reminder.Kind, reminder.Subscription.ID, err)
+	}
+
+	service.metrics.ReminderSent(reminder.Kind, reminderOutcomeSent)
+	logger.Debug().Msg("reminder sent")
+	return true, nil
+}
+
+// dueReminders собирает напоминания об окончании подписок и о списаниях,
+// которые наступят в промежутке [from, to]. О первом списании подписки не
+// напоминается.
+func dueReminders(
+	ctx context.Context,
+	repo interfaces.SubscriptionRepository,
+	from time.Time,
+	to time.Time,
+) ([]*entity.Reminder, error) {
+	ending, err := repo.FindEnding(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]*entity.Reminder, 0, len(ending))
+	for _, sub := range ending {
+		reminders = append(reminders, &entity.Reminder{
+			Kind:         entity.ReminderKindExpiry,
+			Subscription: *sub,
+			DueDate:      *sub.EndDate,
+		})
+	}
+
+	for _, date := range service.BillingDates(from, to) {
+		active, err := repo.FindActive(ctx, date)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sub := range active {
+			if !sub.StartDate.Before(date) {
+				continue
+			}
+
+			reminders = append(reminders, &entity.Reminder{
+				Kind:         entity.ReminderKindRenewal,
+				Subscription: *sub,
+				DueDate:      date,
+			})
+		}
+	}
+
+	return reminders, nil
+}
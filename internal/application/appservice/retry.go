@@ -0,0 +1,20 @@
+package appservice
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// retryDelay возвращает задержку перед попыткой attempt+1: base*2^(attempt-1),
+// но не больше maxDelay, со случайным разбросом до 10%, чтобы повторы
+// разных сообщений не совпадали.
+func retryDelay(base, maxDelay time.Duration, attempt int) time.Duration {
+	delay := maxDelay
+	if shift := attempt - 1; shift < 32 {
+		if d := base << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	return delay + rand.N(delay/10+1)
+}
@@ -0,0 +1,39 @@
+package appservice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	const (
+		base     = time.Second
+		maxDelay = time.Hour
+	)
+
+	tests := []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", attempt: 1, want: base},
+		{name: "doubles", attempt: 2, want: 2 * base},
+		{name: "exponential", attempt: 5, want: 16 * base},
+		{name: "capped", attempt: 13, want: maxDelay},
+		{name: "last shift", attempt: 32, want: maxDelay},
+		{name: "shift overflows", attempt: 33, want: maxDelay},
+		{name: "shift too large", attempt: 100, want: maxDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Разброс случайный, поэтому задержка проверяется несколько раз.
+			for range 100 {
+				got := retryDelay(base, maxDelay, tt.attempt)
+				if got < tt.want || got > tt.want+tt.want/10 {
+					t.Fatalf("retryDelay(%d) = %s, want between %s and %s", tt.attempt, got, tt.want, tt.want+tt.want/10)
+				}
+			}
+		})
+	}
+}
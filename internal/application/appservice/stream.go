@@ -0,0 +1,288 @@
+package appservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/event"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"github.com/rs/zerolog"
+)
+
+// streamEventTypes — события outbox, которые получают потоки изменений.
+var streamEventTypes = []string{
+	event.TypeSubscriptionCreated,
+	event.TypeSubscriptionUpdated,
+	event.TypeSubscriptionDeleted,
+}
+
+type SubscriptionStreamConfig struct {
+	// PollInterval — как часто outbox перечитывается без сигнала
+	// OutboxNotifier, например если сигнал потерялся при обрыве соединения.
+	PollInterval time.Duration
+	BatchSize    int
+	// AuthCheckInterval — как часто поток проверяет, что API-ключ пользователя
+	// не отозван. Поток с JWT, кроме того, закрывается по истечении токена.
+	AuthCheckInterval time.Duration
+	// MaxPerPrincipal ограничивает число одновременно открытых потоков
+	// одного пользователя: каждый поток отдельно опрашивает outbox.
+	MaxPerPrincipal int
+}
+
+// SubscriptionStreamService отдаёт изменения подписок из outbox по мере их
+// фиксации.
+type SubscriptionStreamService struct {
+	outbox        interfaces.OutboxRepository
+	notifier      interfaces.OutboxNotifier
+	metrics       interfaces.Metrics
+	authenticator *Authenticator
+	cfg           SubscriptionStreamConfig
+
+	mu   sync.Mutex
+	open map[string]int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSubscriptionStreamService создаёт сервис потоков. authenticator может
+// быть nil, если аутентификация отключена: тогда в контексте нет пользователя
+// и учётные данные не перепроверяются.
+func NewSubscriptionStreamService(
+	outbox interfaces.OutboxRepository,
+	notifier interfaces.OutboxNotifier,
+	metrics interfaces.Metrics,
+	authenticator *Authenticator,
+	cfg SubscriptionStreamConfig,
+) *SubscriptionStreamService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &SubscriptionStreamService{
+		outbox:        outbox,
+		notifier:      notifier,
+		metrics:       metrics,
+		authenticator: authenticator,
+		cfg:           cfg,
+		open:          make(map[string]int),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Subscribe возвращает канал изменений подписок арендатора из контекста,
+// подходящих под фильтры. lastEventID - номер последнего полученного
+// клиентом события; если он не задан, отдаются только новые изменения.
+// Канал закрывается после отмены ctx, вызова Close, ошибки чтения, а также
+// когда истекает JWT пользователя или отзывается его API-ключ. Если у
+// пользователя уже открыто MaxPerPrincipal потоков, возвращается
+// failure.ErrTooManyStreams.
+func (service *SubscriptionStreamService) Subscribe(
+	ctx context.Context,
+	filters dto.SubscriptionStreamFilterDTO,
+	lastEventID *int64,
+) (_ <-chan dto.StreamEvent, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionStreamService.Subscribe")
+	defer func() { traceext.End(span, err) }()
+
+	userID, ok := auth.ScopeUserID(ctx, filters.UserID)
+	if !ok {
+		return nil, failure.ErrForbidden
+	}
+	filters.UserID = userID
+
+	principal, _ := auth.PrincipalFrom(ctx)
+	release, ok := service.acquire(ctx, principal)
+	if !ok {
+		return nil, failure.ErrTooManyStreams
+	}
+
+	// Поток с JWT закрывается, когда истекает токен.
+	var cancel context.CancelFunc
+	if principal != nil && !principal.ExpiresAt.IsZero() {
+		ctx, cancel = context.WithDeadline(ctx, principal.ExpiresAt)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	stop := context.AfterFunc(service.ctx, cancel)
+
+	// Подписка на сигналы до чтения номера: изменение между ними не потеряется.
+	wake := service.notifier.Listen(ctx)
+
+	var after int64
+	if lastEventID != nil {
+		after = *lastEventID
+	} else if after, err = service.outbox.LastSeq(ctx); err != nil {
+		stop()
+		cancel()
+		release()
+		return nil, err
+	}
+
+	events := make(chan dto.StreamEvent)
+	go func() {
+		defer close(events)
+		defer release()
+		defer stop()
+		defer cancel()
+
+		service.metrics.StreamOpened()
+		defer service.metrics.StreamClosed()
+
+		if principal != nil {
+			go service.watch(ctx, cancel, principal)
+		}
+		service.run(ctx, filters, after, wake, events)
+	}()
+
+	return events, nil
+}
+
+// acquire занимает место в лимите потоков пользователя. Потоки без
+// пользователя, когда аутентификация отключена, не ограничиваются.
+func (service *SubscriptionStreamService) acquire(ctx context.Context, principal *auth.Principal) (func(), bool) {
+	if principal == nil {
+		return func() {}, true
+	}
+
+	key := tenant.FromContext(ctx) + "/" + principal.Subject
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.open[key] >= service.cfg.MaxPerPrincipal {
+		return nil, false
+	}
+	service.open[key]++
+
+	return sync.OnceFunc(func() {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+
+		if service.open[key]--; service.open[key] == 0 {
+			delete(service.open, key)
+		}
+	}), true
+}
+
+// watch раз в AuthCheckInterval проверяет учётные данные пользователя потока
+// и закрывает поток, если они больше не действуют. Временная ошибка проверки
+// поток не закрывает.
+func (service *SubscriptionStreamService) watch(ctx context.Context, cancel context.CancelFunc, principal *auth.Principal) {
+	ticker := time.NewTicker(service.cfg.AuthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := service.authenticator.Check(ctx, principal)
+		switch {
+		case err == nil:
+		case errors.Is(err, failure.ErrInvalidAPIKey), errors.Is(err, failure.ErrInvalidToken):
+			zerolog.Ctx(ctx).Info().Err(err).Msg("subscription stream closed: credentials are no longer valid")
+			cancel()
+			return
+		default:
+			if ctx.Err() == nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("failed to check stream credentials")
+			}
+		}
+	}
+}
+
+// Close завершает все открытые потоки. Вызывается перед остановкой
+// HTTP-сервера, который ждёт завершения ответов.
+func (service *SubscriptionStreamService) Close() {
+	service.cancel()
+}
+
+func (service *SubscriptionStreamService) run(
+	ctx context.Context,
+	filters dto.SubscriptionStreamFilterDTO,
+	after int64,
+	wake <-chan struct{},
+	events chan<- dto.StreamEvent,
+) {
+	ticker := time.NewTicker(service.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			messages, err := service.outbox.FindAfter(ctx, after, streamEventTypes, service.cfg.BatchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("failed to read subscription changes")
+				}
+				return
+			}
+
+			for _, message := range messages {
+				after = message.Seq
+
+				ok, err := matchStreamFilters(message, filters)
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Str("event_id", message.ID).Msg("failed to filter subscription change")
+					continue
+				}
+				if !ok {
+					continue
+				}
+
+				select {
+				case events <- dto.StreamEvent{ID: message.Seq, Type: message.Type, Data: message.Payload}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if len(messages) < service.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				return
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+func matchStreamFilters(message *entity.OutboxMessage, filters dto.SubscriptionStreamFilterDTO) (bool, error) {
+	if filters.UserID == "" && filters.ServiceName == "" {
+		return true, nil
+	}
+
+	var payload struct {
+		Data dto.SubscriptionResponse `json:"data"`
+	}
+	if err := json.Unmarshal(message.Payload, &payload); err != nil {
+		return false, fmt.Errorf("failed to decode event %s: %w", message.ID, err)
+	}
+
+	if filters.UserID != "" && !strings.EqualFold(payload.Data.UserID, filters.UserID) {
+		return false, nil
+	}
+	if filters.ServiceName != "" && payload.Data.ServiceName != filters.ServiceName {
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,129 @@
+package appservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+)
+
+// nopMetrics не учитывает ничего.
+type nopMetrics struct{}
+
+func (nopMetrics) SubscriptionsCreated(int)                {}
+func (nopMetrics) SubscriptionsDeleted(int)                {}
+func (nopMetrics) CostQueried(string, int)                 {}
+func (nopMetrics) ValidationFailed(string, string)         {}
+func (nopMetrics) WebhookDeliveryAttempted(string, string) {}
+func (nopMetrics) OutboxSent(string, string)               {}
+func (nopMetrics) ReminderSent(string, string)             {}
+func (nopMetrics) StreamOpened()                           {}
+func (nopMetrics) StreamClosed()                           {}
+
+func newStreamService(t *testing.T, keys *memory.APIKeyRepository) *appservice.SubscriptionStreamService {
+	t.Helper()
+
+	apiKeys := appservice.NewAPIKeyService(nil, keys, nopMetrics{}, false)
+	service := appservice.NewSubscriptionStreamService(
+		memory.NewOutboxRepository(),
+		memory.NewOutboxNotifier(),
+		nopMetrics{},
+		appservice.NewAuthenticator(nil, apiKeys, appservice.AuthClaims{}),
+		appservice.SubscriptionStreamConfig{
+			PollInterval:      time.Hour,
+			BatchSize:         10,
+			AuthCheckInterval: 10 * time.Millisecond,
+			MaxPerPrincipal:   1,
+		},
+	)
+	t.Cleanup(service.Close)
+	return service
+}
+
+func subscribe(t *testing.T, ctx context.Context, service *appservice.SubscriptionStreamService) <-chan dto.StreamEvent {
+	t.Helper()
+
+	events, err := service.Subscribe(ctx, dto.SubscriptionStreamFilterDTO{}, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	return events
+}
+
+func waitClosed(t *testing.T, events <-chan dto.StreamEvent) {
+	t.Helper()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("unexpected event")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream not closed")
+	}
+}
+
+func TestStreamLimitPerPrincipal(t *testing.T) {
+	service := newStreamService(t, memory.NewAPIKeyRepository())
+
+	alice := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice"})
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "bob"})
+
+	ctx, cancel := context.WithCancel(alice)
+	events := subscribe(t, ctx, service)
+
+	if _, err := service.Subscribe(alice, dto.SubscriptionStreamFilterDTO{}, nil); !errors.Is(err, failure.ErrTooManyStreams) {
+		t.Fatalf("second stream error = %v, want ErrTooManyStreams", err)
+	}
+	subscribe(t, bob, service)
+
+	// Закрытый поток освобождает место.
+	cancel()
+	waitClosed(t, events)
+	subscribe(t, alice, service)
+}
+
+func TestStreamClosedWhenTokenExpires(t *testing.T) {
+	service := newStreamService(t, memory.NewAPIKeyRepository())
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject:   "alice",
+		ExpiresAt: time.Now().Add(50 * time.Millisecond),
+	})
+
+	waitClosed(t, subscribe(t, ctx, service))
+}
+
+func TestStreamClosedWhenAPIKeyRevoked(t *testing.T) {
+	keys := memory.NewAPIKeyRepository()
+	service := newStreamService(t, keys)
+
+	key, err := keys.Insert(context.Background(), &entity.APIKey{Name: "billing", CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		Subject: appservice.APIKeySubjectPrefix + "1",
+		Service: true,
+	})
+	events := subscribe(t, ctx, service)
+
+	select {
+	case <-events:
+		t.Fatal("stream closed with valid key")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := keys.Revoke(context.Background(), key.ID, time.Now()); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	waitClosed(t, events)
+}
@@ -2,50 +2,99 @@ package appservice
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/noredis/subscriptions/internal/application/auth"
 	"github.com/noredis/subscriptions/internal/application/dto"
 	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/event"
 	"github.com/noredis/subscriptions/internal/domain/failure"
 	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
 	"github.com/noredis/subscriptions/pkg/goext"
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"github.com/rs/zerolog"
 )
 
 const dateFormat = "01-2006"
 
 type SubscriptionService struct {
-	validate *validator.Validate
-	repo     interfaces.SubscriptionRepository
+	validate  *validator.Validate
+	repo      interfaces.SubscriptionRepository
+	txManager interfaces.TxManager
+	metrics   interfaces.Metrics
+	outbox    interfaces.OutboxRepository
+	notifier  interfaces.OutboxNotifier
 }
 
 func NewSubscriptionService(
 	validate *validator.Validate,
 	repo interfaces.SubscriptionRepository,
+	txManager interfaces.TxManager,
+	metrics interfaces.Metrics,
+	outbox interfaces.OutboxRepository,
+	notifier interfaces.OutboxNotifier,
 ) *SubscriptionService {
 	return &SubscriptionService{
-		validate: validate,
-		repo:     repo,
+		validate:  validate,
+		repo:      repo,
+		txManager: txManager,
+		metrics:   metrics,
+		outbox:    outbox,
+		notifier:  notifier,
 	}
 }
 
 func (service *SubscriptionService) Create(
 	ctx context.Context,
 	req dto.SubscriptionRequest,
+) (_ *dto.SubscriptionResponse, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Create")
+	defer func() { traceext.End(span, err) }()
+
+	resp, err := service.create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	service.metrics.SubscriptionsCreated(1)
+	service.notify(ctx)
+	return resp, nil
+}
+
+func (service *SubscriptionService) create(
+	ctx context.Context,
+	req dto.SubscriptionRequest,
 ) (*dto.SubscriptionResponse, error) {
-	if err := service.validate.Struct(req); err != nil {
+	if err := validateStruct(service.validate, service.metrics, req); err != nil {
 		return nil, err
 	}
 
+	if !auth.CanAccess(ctx, req.UserID) {
+		return nil, failure.ErrForbidden
+	}
+
 	sub, err := service.mapToEntity(req)
 	if err != nil {
 		return nil, err
 	}
 
-	sub, err = service.repo.Insert(ctx, sub)
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		sub, err = service.repo.Insert(ctx, sub)
+		if err != nil {
+			return err
+		}
+
+		return service.record(ctx, event.SubscriptionCreated{Subscription: *sub})
+	})
 	if err != nil {
 		return nil, err
 	}
+	zerolog.Ctx(ctx).Debug().Int("id", sub.ID).Msg("subscription inserted")
 
 	return service.mapFromEntity(sub), nil
 }
@@ -54,68 +103,139 @@ func (service *SubscriptionService) Update(
 	ctx context.Context,
 	req dto.SubscriptionRequest,
 	id int,
-) (*dto.SubscriptionResponse, error) {
-	if err := service.validate.Struct(req); err != nil {
+) (_ *dto.SubscriptionResponse, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Update")
+	defer func() { traceext.End(span, err) }()
+
+	resp, err := service.update(ctx, req, id)
+	if err != nil {
 		return nil, err
 	}
 
-	sub, err := service.mapToEntity(req)
-	if err != nil {
+	service.notify(ctx)
+	return resp, nil
+}
+
+func (service *SubscriptionService) update(
+	ctx context.Context,
+	req dto.SubscriptionRequest,
+	id int,
+) (*dto.SubscriptionResponse, error) {
+	if err := validateStruct(service.validate, service.metrics, req); err != nil {
 		return nil, err
 	}
 
-	exists, err := service.repo.ExistsByID(ctx, id)
+	if !auth.CanAccess(ctx, req.UserID) {
+		return nil, failure.ErrForbidden
+	}
+
+	sub, err := service.mapToEntity(req)
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, failure.ErrSubscriptionNotFound
-	}
 
 	sub.ID = id
 
-	sub, err = service.repo.Update(ctx, sub)
+	err = service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		exists, err := service.repo.ExistsByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return failure.ErrSubscriptionNotFound
+		}
+
+		if err := service.checkOwner(ctx, id); err != nil {
+			return err
+		}
+
+		sub, err = service.repo.Update(ctx, sub)
+		if err != nil {
+			return err
+		}
+
+		return service.record(ctx, event.SubscriptionUpdated{Subscription: *sub})
+	})
 	if err != nil {
 		return nil, err
 	}
+	zerolog.Ctx(ctx).Debug().Int("id", id).Msg("subscription updated")
 
 	return service.mapFromEntity(sub), nil
 }
 
-func (service *SubscriptionService) Delete(ctx context.Context, id int) error {
-	exists, err := service.repo.ExistsByID(ctx, id)
-	if err != nil {
+func (service *SubscriptionService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Delete")
+	defer func() { traceext.End(span, err) }()
+
+	if err := service.delete(ctx, id); err != nil {
 		return err
 	}
-	if !exists {
-		return failure.ErrSubscriptionNotFound
-	}
 
-	return service.repo.Delete(ctx, id)
+	service.metrics.SubscriptionsDeleted(1)
+	service.notify(ctx)
+	return nil
+}
+
+func (service *SubscriptionService) delete(ctx context.Context, id int) error {
+	return service.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		sub, err := service.repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Чужие подписки скрываются от обычного пользователя так же, как несуществующие.
+		if !auth.CanAccess(ctx, sub.UserID) {
+			return failure.ErrSubscriptionNotFound
+		}
+
+		if err := service.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Debug().Int("id", id).Msg("subscription deleted")
+		return service.record(ctx, event.SubscriptionDeleted{Subscription: *sub})
+	})
 }
 
 func (service *SubscriptionService) Index(
 	ctx context.Context,
 	id int,
-) (*dto.SubscriptionResponse, error) {
+) (_ *dto.SubscriptionResponse, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.Index")
+	defer func() { traceext.End(span, err) }()
+
 	sub, err := service.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if !auth.CanAccess(ctx, sub.UserID) {
+		return nil, failure.ErrSubscriptionNotFound
+	}
+
 	return service.mapFromEntity(sub), nil
 }
 
 func (service *SubscriptionService) List(
 	ctx context.Context,
 	filters dto.SubscriptionFilterDTO,
-) (*dto.SubscriptionListResponse, error) {
-	f, err := service.mapFiltersToEntity(filters)
-	if err != nil {
+) (_ *dto.SubscriptionListResponse, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.List")
+	defer func() { traceext.End(span, err) }()
+
+	userID, ok := auth.ScopeUserID(ctx, filters.UserID)
+	if !ok {
+		return nil, failure.ErrForbidden
+	}
+	filters.UserID = userID
+
+	if err := validateStruct(service.validate, service.metrics, filters); err != nil {
 		return nil, err
 	}
 
-	if err := service.validate.Struct(f); err != nil {
+	f, err := service.mapFiltersToEntity(filters)
+	if err != nil {
 		return nil, err
 	}
 
@@ -137,6 +257,130 @@ func (service *SubscriptionService) List(
 	}, nil
 }
 
+// PublishEndingSoon сохраняет в outbox subscription.ending_soon для подписок
+// всех арендаторов, которые заканчиваются в ближайшие within. Идентификатор
+// события зависит только от подписки и даты окончания, поэтому повторный
+// запуск не сохраняет событие второй раз.
+func (service *SubscriptionService) PublishEndingSoon(
+	ctx context.Context,
+	now time.Time,
+	within time.Duration,
+) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "SubscriptionService.PublishEndingSoon")
+	defer func() { traceext.End(span, err) }()
+
+	subscriptions, err := service.repo.FindEnding(ctx, now, now.Add(within))
+	if err != nil {
+		return 0, err
+	}
+
+	messages := make([]*entity.OutboxMessage, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		name := fmt.Sprintf(
+			"%s/%s/%d/%s",
+			event.TypeSubscriptionEndingSoon, sub.TenantID, sub.ID, sub.EndDate.Format(dateFormat),
+		)
+		id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+
+		message, err := service.newMessage(id, sub.TenantID, now.UTC(), event.SubscriptionEndingSoon{Subscription: *sub})
+		if err != nil {
+			return 0, err
+		}
+		messages = append(messages, message)
+	}
+
+	if err := service.outbox.Insert(ctx, messages...); err != nil {
+		return 0, err
+	}
+	return len(messages), nil
+}
+
+// record сохраняет события в outbox. Вызывается в транзакции изменения
+// подписки, поэтому событие сохраняется тогда и только тогда, когда
+// фиксируется изменение.
+func (service *SubscriptionService) record(ctx context.Context, events ...event.Event) error {
+	now := time.Now().UTC()
+	tenantID := tenant.FromContext(ctx)
+
+	messages := make([]*entity.OutboxMessage, 0, len(events))
+	for _, e := range events {
+		message, err := service.newMessage(uuid.NewString(), tenantID, now, e)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+
+	return service.outbox.Insert(ctx, messages...)
+}
+
+// notify будит потоки изменений после фиксации транзакции. Ошибка не
+// отменяет изменение: потоки периодически перечитывают outbox и без сигнала.
+func (service *SubscriptionService) notify(ctx context.Context) {
+	if err := service.notifier.Notify(ctx); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("failed to notify subscription streams")
+	}
+}
+
+func (service *SubscriptionService) newMessage(
+	id string,
+	tenantID string,
+	occurredAt time.Time,
+	e event.Event,
+) (*entity.OutboxMessage, error) {
+	var sub entity.Subscription
+	switch e := e.(type) {
+	case event.SubscriptionCreated:
+		sub = e.Subscription
+	case event.SubscriptionUpdated:
+		sub = e.Subscription
+	case event.SubscriptionDeleted:
+		sub = e.Subscription
+	case event.SubscriptionEndingSoon:
+		sub = e.Subscription
+	default:
+		return nil, fmt.Errorf("unknown event %T", e)
+	}
+
+	payload, err := json.Marshal(dto.Event{
+		ID:         id,
+		Type:       e.EventType(),
+		TenantID:   tenantID,
+		OccurredAt: occurredAt,
+		Data:       service.mapFromEntity(&sub),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event %s: %w", id, err)
+	}
+
+	return &entity.OutboxMessage{
+		ID:            id,
+		TenantID:      tenantID,
+		Type:          e.EventType(),
+		AggregateID:   e.AggregateID(),
+		Payload:       payload,
+		OccurredAt:    occurredAt,
+		NextAttemptAt: occurredAt,
+	}, nil
+}
+
+// checkOwner скрывает чужие подписки от обычного пользователя так же, как несуществующие.
+func (service *SubscriptionService) checkOwner(ctx context.Context, id int) error {
+	if principal, ok := auth.PrincipalFrom(ctx); !ok || principal.AllUsers() {
+		return nil
+	}
+
+	sub, err := service.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !auth.CanAccess(ctx, sub.UserID) {
+		return failure.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
 func (service *SubscriptionService) mapToEntity(
 	sub dto.SubscriptionRequest,
 ) (*entity.Subscription, error) {
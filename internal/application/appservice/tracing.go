@@ -0,0 +1,5 @@
+package appservice
+
+import "go.opentelemetry.io/otel"
+
+var tracer = otel.Tracer("github.com/noredis/subscriptions/internal/application/appservice")
@@ -0,0 +1,22 @@
+package appservice
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// validateStruct проверяет s и учитывает каждое нарушенное правило в метриках.
+func validateStruct(validate *validator.Validate, metrics interfaces.Metrics, s any) error {
+	err := validate.Struct(s)
+
+	var vErrs validator.ValidationErrors
+	if errors.As(err, &vErrs) {
+		for _, fErr := range vErrs {
+			metrics.ValidationFailed(fErr.Field(), fErr.Tag())
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,193 @@
+package appservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/goext"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// WebhookSecretPrefix отличает сгенерированные секреты вебхуков.
+	WebhookSecretPrefix = "whsec_"
+
+	webhookSecretBytes = 32
+	// webhookDeliveriesLimit ограничивает журнал доставок в ответе API.
+	webhookDeliveriesLimit = 100
+)
+
+// WebhookService управляет вебхуками и как приёмник outbox ставит события
+// в очередь их доставки. Саму доставку выполняет WebhookDispatcher.
+type WebhookService struct {
+	validate   *validator.Validate
+	repo       interfaces.WebhookRepository
+	deliveries interfaces.WebhookDeliveryRepository
+	metrics    interfaces.Metrics
+}
+
+func NewWebhookService(
+	validate *validator.Validate,
+	repo interfaces.WebhookRepository,
+	deliveries interfaces.WebhookDeliveryRepository,
+	metrics interfaces.Metrics,
+) *WebhookService {
+	return &WebhookService{
+		validate:   validate,
+		repo:       repo,
+		deliveries: deliveries,
+		metrics:    metrics,
+	}
+}
+
+var _ interfaces.EventSink = (*WebhookService)(nil)
+
+// Register регистрирует вебхук арендатора запроса. Если секрет не указан,
+// он генерируется. Секрет возвращается только в этом ответе.
+func (service *WebhookService) Register(
+	ctx context.Context,
+	req dto.WebhookRequest,
+) (*dto.CreatedWebhookResponse, error) {
+	if err := validateStruct(service.validate, service.metrics, req); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		raw := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	webhook, err := service.repo.Insert(ctx, &entity.Webhook{
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedWebhookResponse{
+		WebhookResponse: *service.mapFromEntity(webhook),
+		Secret:          secret,
+	}, nil
+}
+
+func (service *WebhookService) List(ctx context.Context) ([]*dto.WebhookResponse, error) {
+	webhooks, err := service.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return goext.Map(webhooks, service.mapFromEntity), nil
+}
+
+// Delete удаляет вебхук вместе с журналом его доставок.
+func (service *WebhookService) Delete(ctx context.Context, id int) error {
+	return service.repo.Delete(ctx, id)
+}
+
+// Deliveries возвращает последние доставки вебхука, начиная с новых.
+func (service *WebhookService) Deliveries(
+	ctx context.Context,
+	id int,
+) ([]*dto.WebhookDeliveryResponse, error) {
+	if _, err := service.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := service.deliveries.FindByWebhook(ctx, id, webhookDeliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return goext.Map(deliveries, service.mapDeliveryFromEntity), nil
+}
+
+func (service *WebhookService) Name() string {
+	return "webhook"
+}
+
+// Send ставит событие в очередь доставки всем вебхукам его арендатора,
+// подписанным на тип события. Событие, уже поставленное в очередь
+// вебхука, повторно не добавляется.
+func (service *WebhookService) Send(ctx context.Context, message *entity.OutboxMessage) error {
+	ctx = tenant.WithTenant(ctx, message.TenantID)
+
+	webhooks, err := service.repo.FindByEvent(ctx, message.Type)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		inserted, err := service.deliveries.Insert(ctx, &entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			TenantID:      message.TenantID,
+			EventID:       message.ID,
+			EventType:     message.Type,
+			Payload:       message.Payload,
+			Status:        entity.DeliveryStatusPending,
+			CreatedAt:     now,
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		if inserted {
+			zerolog.Ctx(ctx).Debug().
+				Int("webhook_id", webhook.ID).
+				Str("event_id", message.ID).
+				Str("event", message.Type).
+				Msg("webhook delivery enqueued")
+		}
+	}
+
+	return nil
+}
+
+func (service *WebhookService) mapFromEntity(webhook *entity.Webhook) *dto.WebhookResponse {
+	return &dto.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func (service *WebhookService) mapDeliveryFromEntity(
+	delivery *entity.WebhookDelivery,
+) *dto.WebhookDeliveryResponse {
+	resp := &dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+
+	if delivery.Status == entity.DeliveryStatusPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+
+	return resp
+}
@@ -0,0 +1,186 @@
+package appservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/signature"
+	"github.com/rs/zerolog"
+)
+
+// Исходы попытки доставки для метрик.
+const (
+	deliveryOutcomeSucceeded = "succeeded"
+	deliveryOutcomeRetry     = "retry"
+	deliveryOutcomeFailed    = "failed"
+)
+
+// WebhookDispatcherConfig задаёт размер пачки, таймаут запроса и расписание
+// повторов: n-я неудачная попытка откладывает следующую на BackoffBase*2^(n-1),
+// но не больше BackoffMax. После MaxAttempts попыток доставка считается неудачной.
+type WebhookDispatcherConfig struct {
+	BatchSize   int
+	Timeout     time.Duration
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// WebhookDispatcher отправляет доставки из очереди, подписывая их HMAC.
+type WebhookDispatcher struct {
+	webhooks   interfaces.WebhookRepository
+	deliveries interfaces.WebhookDeliveryRepository
+	sender     interfaces.WebhookSender
+	metrics    interfaces.Metrics
+	cfg        WebhookDispatcherConfig
+}
+
+func NewWebhookDispatcher(
+	webhooks interfaces.WebhookRepository,
+	deliveries interfaces.WebhookDeliveryRepository,
+	sender interfaces.WebhookSender,
+	metrics interfaces.Metrics,
+	cfg WebhookDispatcherConfig,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		sender:     sender,
+		metrics:    metrics,
+		cfg:        cfg,
+	}
+}
+
+// Dispatch отправляет пачку доставок, которым пора отправляться, и
+// возвращает их количество. Доставки пачки отправляются параллельно.
+func (dispatcher *WebhookDispatcher) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	// Пока идёт отправка, доставка отложена: если процесс упадёт, её
+	// повторит любой экземпляр сервиса после истечения этого срока.
+	leaseUntil := now.Add(2 * dispatcher.cfg.Timeout)
+
+	claimed, err := dispatcher.deliveries.Claim(ctx, now, leaseUntil, dispatcher.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, delivery := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := dispatcher.deliver(ctx, delivery); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(claimed), errors.Join(errs...)
+}
+
+func (dispatcher *WebhookDispatcher) deliver(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	ctx = tenant.WithTenant(ctx, delivery.TenantID)
+	logger := zerolog.Ctx(ctx).With().
+		Int("delivery_id", delivery.ID).
+		Int("webhook_id", delivery.WebhookID).
+		Str("event", delivery.EventType).
+		Logger()
+
+	webhook, err := dispatcher.webhooks.FindByID(ctx, delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, failure.ErrWebhookNotFound) {
+			// Вебхук удалён после выборки доставки, вместе с ним удалён и журнал.
+			return nil
+		}
+		return err
+	}
+
+	status, sendErr := dispatcher.send(ctx, webhook, delivery)
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+	if status > 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	outcome := deliveryOutcomeSucceeded
+	switch {
+	case sendErr == nil && status >= 200 && status < 300:
+		delivery.Status = entity.DeliveryStatusSucceeded
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= dispatcher.cfg.MaxAttempts:
+		outcome = deliveryOutcomeFailed
+		delivery.Status = entity.DeliveryStatusFailed
+		delivery.Error = deliveryError(status, sendErr)
+	default:
+		outcome = deliveryOutcomeRetry
+		delivery.Error = deliveryError(status, sendErr)
+		delivery.NextAttemptAt = now.Add(
+			retryDelay(dispatcher.cfg.BackoffBase, dispatcher.cfg.BackoffMax, delivery.Attempts),
+		)
+	}
+
+	dispatcher.metrics.WebhookDeliveryAttempted(delivery.EventType, outcome)
+	logger.Debug().
+		Int("attempt", delivery.Attempts).
+		Int("status", status).
+		Str("outcome", outcome).
+		Str("error", delivery.Error).
+		Msg("webhook delivery attempted")
+	if outcome == deliveryOutcomeFailed {
+		logger.Warn().Int("attempts", delivery.Attempts).Str("error", delivery.Error).Msg("webhook delivery failed")
+	}
+
+	return dispatcher.deliveries.Update(ctx, delivery)
+}
+
+func (dispatcher *WebhookDispatcher) send(
+	ctx context.Context,
+	webhook *entity.Webhook,
+	delivery *entity.WebhookDelivery,
+) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, dispatcher.cfg.Timeout)
+	defer cancel()
+
+	timestamp := time.Now().Unix()
+
+	return dispatcher.sender.Send(ctx, interfaces.WebhookRequest{
+		URL: webhook.URL,
+		Headers: map[string]string{
+			signature.HeaderSignature: signature.Sign(webhook.Secret, timestamp, delivery.Payload),
+			signature.HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+			signature.HeaderEvent:     delivery.EventType,
+			signature.HeaderDelivery:  delivery.EventID,
+		},
+		Body: delivery.Payload,
+	})
+}
+
+func deliveryError(status int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("unexpected response status %d", status)
+}
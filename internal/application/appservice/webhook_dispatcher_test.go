@@ -0,0 +1,197 @@
+package appservice_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+	"github.com/noredis/subscriptions/pkg/signature"
+)
+
+// fakeSender отвечает заданным статусом или ошибкой и запоминает запросы.
+type fakeSender struct {
+	status int
+	err    error
+
+	mu       sync.Mutex
+	requests []interfaces.WebhookRequest
+}
+
+func (sender *fakeSender) Send(_ context.Context, req interfaces.WebhookRequest) (int, error) {
+	sender.mu.Lock()
+	sender.requests = append(sender.requests, req)
+	sender.mu.Unlock()
+
+	return sender.status, sender.err
+}
+
+// outcomeMetrics запоминает исходы попыток доставки.
+type outcomeMetrics struct {
+	nopMetrics
+
+	mu       sync.Mutex
+	outcomes []string
+}
+
+func (m *outcomeMetrics) WebhookDeliveryAttempted(_, outcome string) {
+	m.mu.Lock()
+	m.outcomes = append(m.outcomes, outcome)
+	m.mu.Unlock()
+}
+
+func TestWebhookDispatcherDeliver(t *testing.T) {
+	const (
+		maxAttempts = 3
+		backoffBase = time.Minute
+	)
+
+	tests := []struct {
+		name       string
+		status     int
+		err        error
+		attempts   int
+		wantStatus string
+		wantError  string
+		outcome    string
+		retry      bool
+	}{
+		{
+			name:       "succeeded",
+			status:     http.StatusNoContent,
+			wantStatus: entity.DeliveryStatusSucceeded,
+			outcome:    "succeeded",
+		},
+		{
+			name:       "error status is retried",
+			status:     http.StatusInternalServerError,
+			wantStatus: entity.DeliveryStatusPending,
+			wantError:  "unexpected response status 500",
+			outcome:    "retry",
+			retry:      true,
+		},
+		{
+			name:       "send error is retried",
+			err:        errors.New("connection refused"),
+			wantStatus: entity.DeliveryStatusPending,
+			wantError:  "connection refused",
+			outcome:    "retry",
+			retry:      true,
+		},
+		{
+			name:       "last attempt failed",
+			status:     http.StatusBadGateway,
+			attempts:   maxAttempts - 1,
+			wantStatus: entity.DeliveryStatusFailed,
+			wantError:  "unexpected response status 502",
+			outcome:    "failed",
+		},
+		{
+			name:       "success after retries",
+			status:     http.StatusOK,
+			attempts:   maxAttempts - 1,
+			wantStatus: entity.DeliveryStatusSucceeded,
+			outcome:    "succeeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			deliveries := memory.NewWebhookDeliveryRepository()
+			webhooks := memory.NewWebhookRepository(deliveries)
+			sender := &fakeSender{status: tt.status, err: tt.err}
+			metrics := &outcomeMetrics{}
+
+			webhook, err := webhooks.Insert(ctx, &entity.Webhook{
+				URL:    "https://example.com/hook",
+				Secret: "whsec_test",
+				Events: []string{"subscription.created"},
+			})
+			if err != nil {
+				t.Fatalf("Insert webhook: %v", err)
+			}
+
+			payload := []byte(`{"id":"evt-1"}`)
+			if _, err := deliveries.Insert(ctx, &entity.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				TenantID:      webhook.TenantID,
+				EventID:       "evt-1",
+				EventType:     "subscription.created",
+				Payload:       payload,
+				Status:        entity.DeliveryStatusPending,
+				Attempts:      tt.attempts,
+				NextAttemptAt: time.Now().Add(-time.Second),
+			}); err != nil {
+				t.Fatalf("Insert delivery: %v", err)
+			}
+
+			dispatcher := appservice.NewWebhookDispatcher(webhooks, deliveries, sender, metrics,
+				appservice.WebhookDispatcherConfig{
+					BatchSize:   10,
+					Timeout:     time.Second,
+					MaxAttempts: maxAttempts,
+					BackoffBase: backoffBase,
+					BackoffMax:  time.Hour,
+				},
+			)
+
+			started := time.Now()
+			n, err := dispatcher.Dispatch(ctx)
+			if err != nil {
+				t.Fatalf("Dispatch: %v", err)
+			}
+			if n != 1 || len(sender.requests) != 1 {
+				t.Fatalf("dispatched = %d, sent = %d, want 1", n, len(sender.requests))
+			}
+
+			req := sender.requests[0]
+			timestamp, err := strconv.ParseInt(req.Headers[signature.HeaderTimestamp], 10, 64)
+			if err != nil {
+				t.Fatalf("timestamp header: %v", err)
+			}
+			if !signature.Verify(webhook.Secret, req.Headers[signature.HeaderSignature], timestamp, payload) {
+				t.Fatalf("signature %q does not match body", req.Headers[signature.HeaderSignature])
+			}
+			if req.URL != webhook.URL || req.Headers[signature.HeaderDelivery] != "evt-1" ||
+				req.Headers[signature.HeaderEvent] != "subscription.created" {
+				t.Fatalf("request = %+v, want delivery evt-1 to %s", req, webhook.URL)
+			}
+
+			stored, err := deliveries.FindByWebhook(ctx, webhook.ID, 10)
+			if err != nil || len(stored) != 1 {
+				t.Fatalf("FindByWebhook = %v, %v", stored, err)
+			}
+			delivery := stored[0]
+
+			if delivery.Status != tt.wantStatus || delivery.Attempts != tt.attempts+1 || delivery.Error != tt.wantError {
+				t.Fatalf("delivery = %+v, want status %s, attempts %d, error %q",
+					delivery, tt.wantStatus, tt.attempts+1, tt.wantError)
+			}
+			if (delivery.DeliveredAt != nil) != (tt.wantStatus == entity.DeliveryStatusSucceeded) {
+				t.Fatalf("delivered at = %v, want set only on success", delivery.DeliveredAt)
+			}
+			if tt.status > 0 && (delivery.ResponseStatus == nil || *delivery.ResponseStatus != tt.status) {
+				t.Fatalf("response status = %v, want %d", delivery.ResponseStatus, tt.status)
+			}
+
+			if tt.retry {
+				wait := backoffBase << tt.attempts
+				if next := delivery.NextAttemptAt.Sub(started); next < wait || next > wait+wait/10+time.Second {
+					t.Fatalf("next attempt in %s, want about %s", next, wait)
+				}
+			}
+
+			if len(metrics.outcomes) != 1 || metrics.outcomes[0] != tt.outcome {
+				t.Fatalf("outcomes = %v, want [%s]", metrics.outcomes, tt.outcome)
+			}
+		})
+	}
+}
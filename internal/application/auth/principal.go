@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeSubscriptionsRead  = "subscriptions:read"
+	ScopeSubscriptionsWrite = "subscriptions:write"
+	ScopeCostsRead          = "costs:read"
+	ScopeAdmin              = "admin"
+)
+
+// Scopes перечисляет все известные права доступа.
+var Scopes = []string{
+	ScopeSubscriptionsRead,
+	ScopeSubscriptionsWrite,
+	ScopeCostsRead,
+	ScopeAdmin,
+}
+
+type principalKey struct{}
+
+// Principal описывает аутентифицированного пользователя или API-ключ запроса.
+// Admin снимает ограничение доступа к подпискам других пользователей и, без
+// Tenant, позволяет выбрать арендатора заголовком. Service отмечает API-ключ
+// внутреннего сервиса: он не привязан к пользователю и работает с подписками
+// всех пользователей, но только своего арендатора.
+// Tenant, если задан, закрепляет пользователя за одним арендатором.
+// ExpiresAt - срок действия JWT; у API-ключа он не задан.
+type Principal struct {
+	Subject   string
+	Admin     bool
+	Service   bool
+	Tenant    string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// AllUsers сообщает, видит ли пользователь подписки всех пользователей.
+func (principal *Principal) AllUsers() bool {
+	return principal.Admin || principal.Service
+}
+
+// HasScope сообщает, выдано ли право scope. Право admin включает все остальные.
+func (principal *Principal) HasScope(scope string) bool {
+	return slices.Contains(principal.Scopes, scope) ||
+		slices.Contains(principal.Scopes, ScopeAdmin)
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom возвращает пользователя из контекста. Если аутентификация
+// отключена, пользователя в контексте нет и доступ не ограничивается.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// HasScope сообщает, выдано ли право scope пользователю из контекста.
+func HasScope(ctx context.Context, scope string) bool {
+	principal, ok := PrincipalFrom(ctx)
+	return !ok || principal.HasScope(scope)
+}
+
+// CanAccess сообщает, может ли пользователь из контекста работать с данными userID.
+func CanAccess(ctx context.Context, userID string) bool {
+	principal, ok := PrincipalFrom(ctx)
+	if !ok || principal.AllUsers() {
+		return true
+	}
+	return strings.EqualFold(principal.Subject, userID)
+}
+
+// ScopeUserID возвращает user_id, которым ограничивается выборка. Обычный пользователь
+// видит только свои подписки, и запрос чужого user_id считается запрещённым.
+func ScopeUserID(ctx context.Context, userID string) (string, bool) {
+	principal, ok := PrincipalFrom(ctx)
+	if !ok || principal.AllUsers() {
+		return userID, true
+	}
+
+	if userID != "" && !strings.EqualFold(userID, principal.Subject) {
+		return "", false
+	}
+	return principal.Subject, true
+}
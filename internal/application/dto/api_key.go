@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type APIKeyRequest struct {
+	Name     string   `json:"name" validate:"required"`
+	TenantID string   `json:"tenant_id,omitempty" validate:"tenant_id"`
+	Scopes   []string `json:"scopes" validate:"required,min=1,dive,oneof=subscriptions:read subscriptions:write costs:read admin"`
+}
+
+type APIKeyResponse struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	TenantID  string     `json:"tenant_id,omitempty"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssuedAPIKeyResponse содержит ключ в открытом виде. Он возвращается только
+// при выпуске, в хранилище сохраняется лишь его хэш.
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
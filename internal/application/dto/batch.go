@@ -0,0 +1,32 @@
+package dto
+
+import "github.com/noredis/subscriptions/pkg/httpext"
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=10000"`
+}
+
+type BatchOperation struct {
+	Op   string               `json:"op" validate:"required,oneof=create update delete"`
+	ID   int                  `json:"id,omitempty" validate:"required_unless=Op create"`
+	Data *SubscriptionRequest `json:"data,omitempty" validate:"required_unless=Op delete"`
+}
+
+type BatchResponse struct {
+	Committed bool                     `json:"committed"`
+	Results   []BatchOperationResponse `json:"results"`
+}
+
+type BatchOperationResponse struct {
+	Index  int                   `json:"index"`
+	Op     string                `json:"op"`
+	Status int                   `json:"status"`
+	Data   *SubscriptionResponse `json:"data,omitempty"`
+	Error  *httpext.FiberError   `json:"error,omitempty"`
+}
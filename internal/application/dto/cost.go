@@ -3,10 +3,24 @@ package dto
 type CostFilterDTO struct {
 	ServiceName string `json:"service_name"`
 	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date" validate:"required,date_format"`
-	EndDate     string `json:"end_date" validate:"required,date_format"`
+	StartDate   string `json:"start_date" validate:"required,date_format,date_year"`
+	EndDate     string `json:"end_date" validate:"required,date_format,date_year"`
 }
 
 type TotalCostResponse struct {
 	TotalCost int `json:"total_cost"`
 }
+
+type CostBreakdownResponse struct {
+	TotalCost int                  `json:"total_cost"`
+	Items     []*CostBreakdownItem `json:"items"`
+}
+
+type CostBreakdownItem struct {
+	SubscriptionID int    `json:"subscription_id"`
+	ServiceName    string `json:"service_name"`
+	UserID         string `json:"user_id"`
+	Price          int    `json:"price"`
+	Months         int    `json:"months"`
+	Cost           int    `json:"cost"`
+}
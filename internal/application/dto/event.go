@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// Event — сериализованное событие, которое получают приёмники: вебхуки,
+// стандартный вывод, файл и брокеры сообщений.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type" example:"subscription.created"`
+	TenantID   string    `json:"tenant_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+// GraphQLRequest — запрос к /graphql. Для GET поля передаются параметрами
+// query, operationName и variables (JSON-объект).
+type GraphQLRequest struct {
+	Query         string         `json:"query" example:"{ subscriptions(limit: 10) { total items { id serviceName } } }"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse — результат запроса. Поле data отсутствует, если запрос не
+// прошёл разбор, валидацию или оценку сложности.
+type GraphQLResponse struct {
+	Data   any            `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError — ошибка запроса. В extensions передаются code, status и
+// fields с тем же смыслом, что и в ошибках REST API.
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation — место ошибки в тексте запроса.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type HealthResponse struct {
+	Status     string                     `json:"status" example:"up"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+type ComponentHealth struct {
+	Status  string         `json:"status" example:"up"`
+	Details map[string]any `json:"details,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+// SubscriptionStreamFilterDTO — фильтры потока изменений подписок.
+type SubscriptionStreamFilterDTO struct {
+	ServiceName string `json:"service_name"`
+	UserID      string `json:"user_id"`
+}
+
+// StreamEvent — событие потока изменений. ID - номер события, с которого
+// клиент продолжает чтение через Last-Event-ID, Data - сериализованный Event.
+type StreamEvent struct {
+	ID   int64
+	Type string
+	Data []byte
+}
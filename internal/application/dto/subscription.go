@@ -2,10 +2,10 @@ package dto
 
 type SubscriptionRequest struct {
 	ServiceName string `json:"service_name" validate:"required"`
-	Price       int    `json:"price" validate:"gte=0"`
+	Price       int    `json:"price" validate:"gte=0,max=1000000"`
 	UserID      string `json:"user_id" validate:"required,uuid"`
-	StartDate   string `json:"start_date" validate:"required,date_format"`
-	EndDate     string `json:"end_date,omitempty" validate:"date_format"`
+	StartDate   string `json:"start_date" validate:"required,date_format,date_year"`
+	EndDate     string `json:"end_date,omitempty" validate:"date_format,date_year"`
 }
 
 type SubscriptionResponse struct {
@@ -25,10 +25,10 @@ type SubscriptionListResponse struct {
 }
 
 type SubscriptionFilterDTO struct {
-	Page        int `validate:"gte=1"`
-	Limit       int `validate:"gte=1"`
-	ServiceName string
-	UserID      string
-	StartDate   string `validate:"date_format"`
-	EndDate     string `validate:"date_format"`
+	Page        int    `json:"page" validate:"gte=1"`
+	Limit       int    `json:"limit" validate:"gte=1"`
+	ServiceName string `json:"service_name"`
+	UserID      string `json:"user_id"`
+	StartDate   string `json:"start_date" validate:"date_format,date_year"`
+	EndDate     string `json:"end_date" validate:"date_format,date_year"`
 }
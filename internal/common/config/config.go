@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -8,33 +9,201 @@ import (
 	"github.com/kelseyhightower/envconfig"
 )
 
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverSQLite   = "sqlite"
+	StorageDriverMemory   = "memory"
+
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+
+	OutboxSinkWebhook = "webhook"
+	OutboxSinkStdout  = "stdout"
+	OutboxSinkFile    = "file"
+
+	ReminderNotifierLog     = "log"
+	ReminderNotifierSMTP    = "smtp"
+	ReminderNotifierWebhook = "webhook"
+
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
 type Config struct {
-	App    App
-	Logger Logger
-	DB     DB
+	App         App
+	Logger      Logger
+	DB          DB
+	SQLite      SQLite
+	Storage     Storage
+	Migrate     Migrate
+	Auth        Auth
+	Tenancy     Tenancy
+	RateLimit   RateLimit
+	Idempotency Idempotency
+	Tracing     Tracing
+	Health      Health
+	Webhooks    Webhooks
+	Outbox      Outbox
+	Reminders   Reminders
+	Stream      Stream
+	GRPC        GRPC
+	GraphQL     GraphQL
 }
 
 type App struct {
-	Env  string `envconfig:"APP_ENV" default:"dev"`
-	Port int    `envconfig:"APP_PORT" default:"8080"`
+	Env                string        `envconfig:"APP_ENV" default:"dev"`
+	Port               int           `envconfig:"APP_PORT" default:"8080"`
+	ShutdownDelay      time.Duration `envconfig:"APP_SHUTDOWN_DELAY" default:"5s"`
+	ProblemTypeBaseURI string        `envconfig:"APP_PROBLEM_TYPE_BASE_URI" default:"/problems/"`
+	BodyLimit          int           `envconfig:"APP_BODY_LIMIT" default:"1048576"`
 }
 
 type Logger struct {
 	Level string `envconfig:"LOG_LEVEL" default:"debug"`
 }
 
+type SQLite struct {
+	Path string `envconfig:"SQLITE_PATH" default:"subscriptions.db"`
+}
+
+type Storage struct {
+	Driver string `envconfig:"STORAGE_DRIVER" default:"postgres"`
+}
+
+type Migrate struct {
+	OnStart bool `envconfig:"MIGRATE_ON_START" default:"false"`
+}
+
+type Auth struct {
+	Enabled     bool          `envconfig:"AUTH_ENABLED" default:"false"`
+	Algorithm   string        `envconfig:"AUTH_JWT_ALGORITHM" default:"HS256"`
+	Secret      string        `envconfig:"AUTH_JWT_SECRET"`
+	JWKSFile    string        `envconfig:"AUTH_JWKS_FILE"`
+	JWKSURL     string        `envconfig:"AUTH_JWKS_URL"`
+	JWKSRefresh time.Duration `envconfig:"AUTH_JWKS_REFRESH" default:"1h"`
+	Issuer      string        `envconfig:"AUTH_JWT_ISSUER"`
+	Audience    string        `envconfig:"AUTH_JWT_AUDIENCE"`
+	RolesClaim  string        `envconfig:"AUTH_ROLES_CLAIM" default:"roles"`
+	AdminRole   string        `envconfig:"AUTH_ADMIN_ROLE" default:"admin"`
+}
+
+type Tenancy struct {
+	Enabled bool   `envconfig:"TENANCY_ENABLED" default:"false"`
+	Header  string `envconfig:"TENANT_HEADER" default:"X-Tenant-ID"`
+	Claim   string `envconfig:"TENANT_CLAIM" default:"tenant_id"`
+}
+
+type RateLimit struct {
+	Enabled     bool          `envconfig:"RATE_LIMIT_ENABLED" default:"false"`
+	Store       string        `envconfig:"RATE_LIMIT_STORE" default:"memory"`
+	ReadLimit   int           `envconfig:"RATE_LIMIT_READ" default:"100"`
+	ReadPeriod  time.Duration `envconfig:"RATE_LIMIT_READ_PERIOD" default:"1m"`
+	WriteLimit  int           `envconfig:"RATE_LIMIT_WRITE" default:"30"`
+	WritePeriod time.Duration `envconfig:"RATE_LIMIT_WRITE_PERIOD" default:"1m"`
+	CostsLimit  int           `envconfig:"RATE_LIMIT_COSTS" default:"10"`
+	CostsPeriod time.Duration `envconfig:"RATE_LIMIT_COSTS_PERIOD" default:"1m"`
+	IPLimit     int           `envconfig:"RATE_LIMIT_IP" default:"300"`
+	IPPeriod    time.Duration `envconfig:"RATE_LIMIT_IP_PERIOD" default:"1m"`
+}
+
+type Tracing struct {
+	Enabled      bool    `envconfig:"TRACING_ENABLED" default:"false"`
+	Exporter     string  `envconfig:"TRACING_EXPORTER" default:"otlp"`
+	OTLPEndpoint string  `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+	OTLPInsecure bool    `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+	SampleRatio  float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+	ServiceName  string  `envconfig:"TRACING_SERVICE_NAME" default:"subscriptions"`
+}
+
+type Health struct {
+	Timeout time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+}
+
+// Webhooks задаёт расписание отправки вебхуков и поиска заканчивающихся подписок.
+type Webhooks struct {
+	DispatchInterval   time.Duration `envconfig:"WEBHOOK_DISPATCH_INTERVAL" default:"5s"`
+	BatchSize          int           `envconfig:"WEBHOOK_BATCH_SIZE" default:"50"`
+	Timeout            time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	MaxAttempts        int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	BackoffBase        time.Duration `envconfig:"WEBHOOK_BACKOFF_BASE" default:"10s"`
+	BackoffMax         time.Duration `envconfig:"WEBHOOK_BACKOFF_MAX" default:"1h"`
+	EndingSoonWithin   time.Duration `envconfig:"WEBHOOK_ENDING_SOON_WITHIN" default:"720h"`
+	EndingSoonInterval time.Duration `envconfig:"WEBHOOK_ENDING_SOON_INTERVAL" default:"1h"`
+}
+
+// Outbox задаёт приёмники событий и расписание отправки сообщений outbox.
+type Outbox struct {
+	Sinks            []string      `envconfig:"OUTBOX_SINKS" default:"webhook"`
+	FilePath         string        `envconfig:"OUTBOX_FILE_PATH" default:"events.jsonl"`
+	DispatchInterval time.Duration `envconfig:"OUTBOX_DISPATCH_INTERVAL" default:"1s"`
+	BatchSize        int           `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	Lease            time.Duration `envconfig:"OUTBOX_LEASE" default:"1m"`
+	BackoffBase      time.Duration `envconfig:"OUTBOX_BACKOFF_BASE" default:"1s"`
+	BackoffMax       time.Duration `envconfig:"OUTBOX_BACKOFF_MAX" default:"5m"`
+	Retention        time.Duration `envconfig:"OUTBOX_RETENTION" default:"168h"`
+}
+
+// Reminders задаёт расписание напоминаний о списаниях и окончании подписок
+// и способ их отправки.
+type Reminders struct {
+	Enabled       bool          `envconfig:"REMINDERS_ENABLED" default:"true"`
+	Interval      time.Duration `envconfig:"REMINDER_INTERVAL" default:"1h"`
+	Window        time.Duration `envconfig:"REMINDER_WINDOW" default:"72h"`
+	Notifier      string        `envconfig:"REMINDER_NOTIFIER" default:"log"`
+	Timeout       time.Duration `envconfig:"REMINDER_TIMEOUT" default:"10s"`
+	SMTPAddr      string        `envconfig:"REMINDER_SMTP_ADDR"`
+	SMTPUsername  string        `envconfig:"REMINDER_SMTP_USERNAME"`
+	SMTPPassword  string        `envconfig:"REMINDER_SMTP_PASSWORD"`
+	SMTPFrom      string        `envconfig:"REMINDER_SMTP_FROM"`
+	SMTPTo        string        `envconfig:"REMINDER_SMTP_TO"`
+	WebhookURL    string        `envconfig:"REMINDER_WEBHOOK_URL"`
+	WebhookSecret string        `envconfig:"REMINDER_WEBHOOK_SECRET"`
+}
+
+// Stream задаёт поток изменений подписок GET /subscriptions/stream.
+type Stream struct {
+	PollInterval time.Duration `envconfig:"STREAM_POLL_INTERVAL" default:"5s"`
+	Heartbeat    time.Duration `envconfig:"STREAM_HEARTBEAT" default:"15s"`
+	BatchSize    int           `envconfig:"STREAM_BATCH_SIZE" default:"100"`
+	// AuthCheckInterval — как часто открытый поток проверяет, что API-ключ
+	// пользователя не отозван.
+	AuthCheckInterval time.Duration `envconfig:"STREAM_AUTH_CHECK_INTERVAL" default:"1m"`
+	MaxPerPrincipal   int           `envconfig:"STREAM_MAX_PER_PRINCIPAL" default:"5"`
+}
+
+// GRPC задаёт gRPC API, который работает рядом с HTTP API на отдельном порту.
+type GRPC struct {
+	Enabled         bool          `envconfig:"GRPC_ENABLED" default:"true"`
+	Port            int           `envconfig:"GRPC_PORT" default:"9090"`
+	Reflection      bool          `envconfig:"GRPC_REFLECTION" default:"true"`
+	ShutdownTimeout time.Duration `envconfig:"GRPC_SHUTDOWN_TIMEOUT" default:"10s"`
+}
+
+// GraphQL задаёт эндпоинт /graphql.
+type GraphQL struct {
+	Enabled       bool `envconfig:"GRAPHQL_ENABLED" default:"true"`
+	MaxComplexity int  `envconfig:"GRAPHQL_MAX_COMPLEXITY" default:"1000"`
+}
+
+type Idempotency struct {
+	TTL             time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+	Lease           time.Duration `envconfig:"IDEMPOTENCY_LEASE" default:"1m"`
+	CleanupInterval time.Duration `envconfig:"IDEMPOTENCY_CLEANUP_INTERVAL" default:"1h"`
+}
+
 type DB struct {
-	User            string        `envconfig:"DB_USER" required:"true"`
-	Password        string        `envconfig:"DB_PASSWORD" required:"true"`
+	User            string        `envconfig:"DB_USER"`
+	Password        string        `envconfig:"DB_PASSWORD"`
 	Host            string        `envconfig:"DB_HOST" default:"localhost"`
 	Port            int           `envconfig:"DB_PORT" default:"5432"`
-	Name            string        `envconfig:"DB_NAME" required:"true"`
+	Name            string        `envconfig:"DB_NAME"`
 	MaxConns        int           `envconfig:"DB_MAX_CONNS" default:"4"`
 	MinConns        int           `envconfig:"DB_MIN_CONNS" default:"1"`
 	MaxConnLifetime time.Duration `envconfig:"DB_MAX_CONN_LIFETIME" default:"1h"`
 	MaxConnIdleTime time.Duration `envconfig:"DB_MAX_CONN_IDLE_TIME" default:"15m"`
 	Attempts        int           `envconfig:"DB_CONN_ATTEMPTS" default:"5"`
 	Delay           time.Duration `envconfig:"DB_CONN_DELAY" default:"3s"`
+	RowSecurity     bool          `envconfig:"DB_ROW_LEVEL_SECURITY" default:"false"`
 }
 
 func (dbCfg *DB) DSN() string {
@@ -57,6 +226,171 @@ func (dbCfg *DB) DSN() string {
 	)
 }
 
+func (cfg *Config) Validate() error {
+	switch cfg.Storage.Driver {
+	case StorageDriverPostgres:
+		if cfg.DB.User == "" || cfg.DB.Password == "" || cfg.DB.Name == "" {
+			return errors.New("DB_USER, DB_PASSWORD and DB_NAME are required for postgres storage")
+		}
+	case StorageDriverSQLite:
+		if cfg.SQLite.Path == "" {
+			return errors.New("SQLITE_PATH is required for sqlite storage")
+		}
+	case StorageDriverMemory:
+	default:
+		return fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+
+	if cfg.App.BodyLimit <= 0 {
+		return errors.New("APP_BODY_LIMIT must be positive")
+	}
+
+	if cfg.Idempotency.TTL <= 0 || cfg.Idempotency.Lease <= 0 || cfg.Idempotency.CleanupInterval <= 0 {
+		return errors.New("idempotency TTL, lease and cleanup interval must be positive")
+	}
+	if cfg.Idempotency.Lease > cfg.Idempotency.TTL {
+		return errors.New("IDEMPOTENCY_LEASE must not exceed IDEMPOTENCY_TTL")
+	}
+
+	if cfg.RateLimit.Enabled {
+		switch cfg.RateLimit.Store {
+		case RateLimitStoreMemory:
+		case RateLimitStorePostgres:
+			if cfg.Storage.Driver != StorageDriverPostgres {
+				return errors.New("RATE_LIMIT_STORE=postgres requires postgres storage")
+			}
+		default:
+			return fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+		}
+
+		for _, limit := range []struct {
+			limit  int
+			period time.Duration
+		}{
+			{cfg.RateLimit.ReadLimit, cfg.RateLimit.ReadPeriod},
+			{cfg.RateLimit.WriteLimit, cfg.RateLimit.WritePeriod},
+			{cfg.RateLimit.CostsLimit, cfg.RateLimit.CostsPeriod},
+			{cfg.RateLimit.IPLimit, cfg.RateLimit.IPPeriod},
+		} {
+			if limit.limit <= 0 || limit.period <= 0 {
+				return errors.New("rate limits and periods must be positive")
+			}
+		}
+	}
+
+	if cfg.Tracing.Enabled {
+		switch cfg.Tracing.Exporter {
+		case TracingExporterOTLP, TracingExporterStdout:
+		default:
+			return fmt.Errorf("unknown tracing exporter %q", cfg.Tracing.Exporter)
+		}
+
+		if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+			return errors.New("TRACING_SAMPLE_RATIO must be between 0 and 1")
+		}
+	}
+
+	if cfg.Webhooks.DispatchInterval <= 0 || cfg.Webhooks.Timeout <= 0 ||
+		cfg.Webhooks.BackoffBase <= 0 || cfg.Webhooks.EndingSoonInterval <= 0 ||
+		cfg.Webhooks.EndingSoonWithin <= 0 {
+		return errors.New("webhook intervals and timeouts must be positive")
+	}
+	if cfg.Webhooks.BatchSize <= 0 || cfg.Webhooks.MaxAttempts <= 0 {
+		return errors.New("WEBHOOK_BATCH_SIZE and WEBHOOK_MAX_ATTEMPTS must be positive")
+	}
+	if cfg.Webhooks.BackoffMax < cfg.Webhooks.BackoffBase {
+		return errors.New("WEBHOOK_BACKOFF_MAX must not be less than WEBHOOK_BACKOFF_BASE")
+	}
+
+	for _, sink := range cfg.Outbox.Sinks {
+		switch sink {
+		case OutboxSinkWebhook, OutboxSinkStdout:
+		case OutboxSinkFile:
+			if cfg.Outbox.FilePath == "" {
+				return errors.New("OUTBOX_FILE_PATH is required for file sink")
+			}
+		default:
+			return fmt.Errorf("unknown outbox sink %q", sink)
+		}
+	}
+	if cfg.Outbox.DispatchInterval <= 0 || cfg.Outbox.Lease <= 0 ||
+		cfg.Outbox.BackoffBase <= 0 || cfg.Outbox.Retention <= 0 {
+		return errors.New("outbox intervals must be positive")
+	}
+	if cfg.Outbox.BatchSize <= 0 {
+		return errors.New("OUTBOX_BATCH_SIZE must be positive")
+	}
+	if cfg.Outbox.BackoffMax < cfg.Outbox.BackoffBase {
+		return errors.New("OUTBOX_BACKOFF_MAX must not be less than OUTBOX_BACKOFF_BASE")
+	}
+
+	if cfg.Reminders.Enabled {
+		switch cfg.Reminders.Notifier {
+		case ReminderNotifierLog:
+		case ReminderNotifierSMTP:
+			if cfg.Reminders.SMTPAddr == "" || cfg.Reminders.SMTPFrom == "" || cfg.Reminders.SMTPTo == "" {
+				return errors.New("REMINDER_SMTP_ADDR, REMINDER_SMTP_FROM and REMINDER_SMTP_TO are required for smtp notifier")
+			}
+		case ReminderNotifierWebhook:
+			if cfg.Reminders.WebhookURL == "" || cfg.Reminders.WebhookSecret == "" {
+				return errors.New("REMINDER_WEBHOOK_URL and REMINDER_WEBHOOK_SECRET are required for webhook notifier")
+			}
+		default:
+			return fmt.Errorf("unknown reminder notifier %q", cfg.Reminders.Notifier)
+		}
+
+		if cfg.Reminders.Interval <= 0 || cfg.Reminders.Window <= 0 || cfg.Reminders.Timeout <= 0 {
+			return errors.New("reminder interval, window and timeout must be positive")
+		}
+	}
+
+	if cfg.Stream.PollInterval <= 0 || cfg.Stream.Heartbeat <= 0 {
+		return errors.New("STREAM_POLL_INTERVAL and STREAM_HEARTBEAT must be positive")
+	}
+	if cfg.Stream.BatchSize <= 0 {
+		return errors.New("STREAM_BATCH_SIZE must be positive")
+	}
+	if cfg.Stream.AuthCheckInterval <= 0 {
+		return errors.New("STREAM_AUTH_CHECK_INTERVAL must be positive")
+	}
+	if cfg.Stream.MaxPerPrincipal <= 0 {
+		return errors.New("STREAM_MAX_PER_PRINCIPAL must be positive")
+	}
+
+	if cfg.GRPC.Enabled {
+		if cfg.GRPC.Port <= 0 || cfg.GRPC.Port > 65535 {
+			return errors.New("GRPC_PORT must be between 1 and 65535")
+		}
+		if cfg.GRPC.Port == cfg.App.Port {
+			return errors.New("GRPC_PORT must differ from APP_PORT")
+		}
+		if cfg.GRPC.ShutdownTimeout <= 0 {
+			return errors.New("GRPC_SHUTDOWN_TIMEOUT must be positive")
+		}
+	}
+
+	if cfg.GraphQL.Enabled && cfg.GraphQL.MaxComplexity <= 0 {
+		return errors.New("GRAPHQL_MAX_COMPLEXITY must be positive")
+	}
+
+	if cfg.Auth.Enabled {
+		switch cfg.Auth.Algorithm {
+		case "HS256":
+			if cfg.Auth.Secret == "" {
+				return errors.New("AUTH_JWT_SECRET is required for HS256")
+			}
+		case "RS256":
+			if cfg.Auth.JWKSFile == "" && cfg.Auth.JWKSURL == "" {
+				return errors.New("AUTH_JWKS_FILE or AUTH_JWKS_URL is required for RS256")
+			}
+		default:
+			return fmt.Errorf("unsupported jwt algorithm %q", cfg.Auth.Algorithm)
+		}
+	}
+
+	return nil
+}
+
 func MustLoad() *Config {
 	var cfg Config
 
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type APIKey struct {
+	ID        int
+	Name      string
+	TenantID  string
+	Prefix    string
+	KeyHash   string
+	Scopes    []string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,15 @@
+package entity
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// ComponentHealth — результат проверки одной зависимости сервиса. Err только
+// записывается в журнал: текст ошибки драйвера может раскрыть адреса и имена
+// внутренних сервисов, поэтому в ответ проверки он не попадает.
+type ComponentHealth struct {
+	Status  string
+	Err     error
+	Details map[string]any
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// Token отличает запрос, который занял ключ: сохранить ответ, продлить
+	// аренду или освободить ключ может только он.
+	Token        string
+	StatusCode   *int
+	ResponseBody []byte
+	ContentType  string
+	Location     string
+	// LockedUntil — срок аренды ключа запросом, который ещё выполняется.
+	// После него ключ без сохранённого ответа можно занять повторно.
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// OutboxMessage — событие, сохранённое в той же транзакции, что и изменение
+// подписки. Payload содержит сериализованное событие и отправляется
+// приёмникам как есть. Пока PublishedAt пуст, сообщение отправляется
+// не раньше NextAttemptAt. Seq - возрастающий номер сообщения, по которому
+// потоки изменений продолжают чтение после переподключения.
+type OutboxMessage struct {
+	Seq           int64
+	ID            string
+	TenantID      string
+	Type          string
+	AggregateID   string
+	Payload       []byte
+	OccurredAt    time.Time
+	Attempts      int
+	Error         string
+	NextAttemptAt time.Time
+	PublishedAt   *time.Time
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// RateLimit разрешает Limit запросов за Period с накоплением до Limit запросов.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	// Reset — время до полного восстановления корзины.
+	Reset time.Duration
+}
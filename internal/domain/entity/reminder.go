@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Виды напоминаний.
+const (
+	// ReminderKindRenewal — подписка будет оплачена за следующий месяц.
+	ReminderKindRenewal = "renewal"
+	// ReminderKindExpiry — подписка заканчивается.
+	ReminderKindExpiry = "expiry"
+)
+
+// Reminder — напоминание пользователю о предстоящем списании или окончании
+// подписки. DueDate — дата списания или окончания. Напоминание одного вида
+// на одну дату отправляется один раз.
+type Reminder struct {
+	Kind         string
+	Subscription Subscription
+	DueDate      time.Time
+	SentAt       time.Time
+}
@@ -4,6 +4,7 @@ import "time"
 
 type Subscription struct {
 	ID          int
+	TenantID    string
 	ServiceName string
 	Price       int
 	UserID      string
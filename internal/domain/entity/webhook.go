@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Статусы доставки вебхука.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook — адрес, на который отправляются события указанных типов.
+// Secret используется для подписи HMAC и хранится в открытом виде.
+type Webhook struct {
+	ID        int
+	TenantID  string
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery — отправка одного события на один вебхук. Пока статус
+// pending, доставка повторяется не раньше NextAttemptAt.
+type WebhookDelivery struct {
+	ID             int
+	WebhookID      int
+	TenantID       string
+	EventID        string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	ResponseStatus *int
+	Error          string
+	CreatedAt      time.Time
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"strconv"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+)
+
+// Типы событий. На них подписываются вебхуки, и они же используются
+// как имена событий во всех приёмниках.
+const (
+	TypeSubscriptionCreated    = "subscription.created"
+	TypeSubscriptionUpdated    = "subscription.updated"
+	TypeSubscriptionDeleted    = "subscription.deleted"
+	TypeSubscriptionEndingSoon = "subscription.ending_soon"
+)
+
+// Event — доменное событие. AggregateID идентифицирует изменённый объект:
+// события одного объекта приёмники могут упорядочивать по нему.
+type Event interface {
+	EventType() string
+	AggregateID() string
+}
+
+// SubscriptionCreated — подписка создана.
+type SubscriptionCreated struct {
+	Subscription entity.Subscription
+}
+
+func (SubscriptionCreated) EventType() string { return TypeSubscriptionCreated }
+
+func (e SubscriptionCreated) AggregateID() string { return strconv.Itoa(e.Subscription.ID) }
+
+// SubscriptionUpdated — подписка изменена. Subscription содержит новое состояние.
+type SubscriptionUpdated struct {
+	Subscription entity.Subscription
+}
+
+func (SubscriptionUpdated) EventType() string { return TypeSubscriptionUpdated }
+
+func (e SubscriptionUpdated) AggregateID() string { return strconv.Itoa(e.Subscription.ID) }
+
+// SubscriptionDeleted — подписка удалена. Subscription содержит её последнее состояние.
+type SubscriptionDeleted struct {
+	Subscription entity.Subscription
+}
+
+func (SubscriptionDeleted) EventType() string { return TypeSubscriptionDeleted }
+
+func (e SubscriptionDeleted) AggregateID() string { return strconv.Itoa(e.Subscription.ID) }
+
+// SubscriptionEndingSoon — до даты окончания подписки осталось меньше
+// заданного срока.
+type SubscriptionEndingSoon struct {
+	Subscription entity.Subscription
+}
+
+func (SubscriptionEndingSoon) EventType() string { return TypeSubscriptionEndingSoon }
+
+func (e SubscriptionEndingSoon) AggregateID() string { return strconv.Itoa(e.Subscription.ID) }
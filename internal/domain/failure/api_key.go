@@ -0,0 +1,11 @@
+package failure
+
+import "errors"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	// ErrAPIKeyTenantRequired возвращается при выпуске ключа без арендатора,
+	// когда включена мультиарендность.
+	ErrAPIKeyTenantRequired = errors.New("api key tenant is required")
+)
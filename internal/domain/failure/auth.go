@@ -0,0 +1,8 @@
+package failure
+
+import "errors"
+
+var (
+	ErrForbidden    = errors.New("access denied")
+	ErrInvalidToken = errors.New("invalid token")
+)
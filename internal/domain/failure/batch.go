@@ -0,0 +1,5 @@
+package failure
+
+import "errors"
+
+var ErrBatchRolledBack = errors.New("operation rolled back because another operation in batch failed")
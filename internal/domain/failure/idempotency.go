@@ -0,0 +1,5 @@
+package failure
+
+import "errors"
+
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
@@ -0,0 +1,7 @@
+package failure
+
+import "errors"
+
+// ErrTooManyStreams возвращается, если у пользователя уже открыто максимальное
+// число потоков изменений.
+var ErrTooManyStreams = errors.New("too many open streams")
@@ -0,0 +1,5 @@
+package failure
+
+import "errors"
+
+var ErrWebhookNotFound = errors.New("webhook not found")
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+)
+
+// HealthCheck проверяет зависимость, без которой сервис не может обслуживать запросы.
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) entity.ComponentHealth
+}
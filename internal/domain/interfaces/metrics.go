@@ -0,0 +1,20 @@
+package interfaces
+
+// Metrics принимает прикладные события для мониторинга.
+type Metrics interface {
+	SubscriptionsCreated(count int)
+	SubscriptionsDeleted(count int)
+	CostQueried(kind string, subscriptions int)
+	ValidationFailed(field, tag string)
+	// WebhookDeliveryAttempted учитывает попытку доставки с исходом
+	// succeeded, retry или failed.
+	WebhookDeliveryAttempted(event, outcome string)
+	// OutboxSent учитывает отправку сообщения outbox в приёмник с исходом
+	// succeeded или failed.
+	OutboxSent(sink, outcome string)
+	// ReminderSent учитывает напоминание с исходом sent или failed.
+	ReminderSent(kind, outcome string)
+	// StreamOpened и StreamClosed учитывают открытые потоки изменений.
+	StreamOpened()
+	StreamClosed()
+}
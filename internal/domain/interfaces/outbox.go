@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+)
+
+// EventSink — приёмник событий из outbox. Send должен быть идемпотентным
+// по message.ID: при сбое другого приёмника сообщение отправляется повторно.
+type EventSink interface {
+	Name() string
+	Send(ctx context.Context, message *entity.OutboxMessage) error
+}
+
+// OutboxNotifier будит читателей outbox после сохранения новых сообщений,
+// в том числе в других экземплярах сервиса.
+type OutboxNotifier interface {
+	// Notify вызывается после фиксации транзакции с новыми сообщениями.
+	Notify(ctx context.Context) error
+	// Listen возвращает канал сигналов о новых сообщениях. Несколько
+	// сигналов могут прийти одним; канал закрывается после отмены ctx.
+	Listen(ctx context.Context) <-chan struct{}
+}
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+)
+
+// Notifier отправляет напоминание пользователю.
+type Notifier interface {
+	Notify(ctx context.Context, reminder *entity.Reminder) error
+}
+
+// Locker выдаёт блокировку, общую для всех экземпляров сервиса.
+type Locker interface {
+	// TryLock захватывает блокировку name без ожидания и возвращает false,
+	// если её удерживает другой экземпляр. unlock освобождает блокировку.
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/noredis/subscriptions/internal/domain/entity"
 )
@@ -15,4 +16,93 @@ type SubscriptionRepository interface {
 	Find(ctx context.Context, f *entity.SubscriptionFilter) ([]*entity.Subscription, error)
 	FindAll(ctx context.Context, f *entity.SubscriptionFilter) ([]*entity.Subscription, error)
 	Total(ctx context.Context, f *entity.SubscriptionFilter) (int, error)
+	// FindEnding возвращает подписки всех арендаторов с датой окончания в [from, to].
+	// Дата окончания — первое число месяца, поэтому from сравнивается с точностью
+	// до месяца: подписка, которая заканчивается в месяце from, тоже возвращается.
+	FindEnding(ctx context.Context, from, to time.Time) ([]*entity.Subscription, error)
+	// FindActive возвращает подписки всех арендаторов, действующие в месяце at.
+	FindActive(ctx context.Context, at time.Time) ([]*entity.Subscription, error)
+}
+
+// IdempotencyRepository хранит ключи отдельно для каждого арендатора из контекста.
+// SaveResponse, Extend и Delete меняют ключ, только пока его держит запрос
+// с тем же токеном и ответ ещё не сохранён: запрос, аренда которого истекла,
+// не должен затереть ключ, занятый повтором.
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error)
+	FindByKey(ctx context.Context, key string) (*entity.IdempotencyRecord, error)
+	SaveResponse(ctx context.Context, record *entity.IdempotencyRecord) error
+	// Extend продлевает аренду ключа до lockedUntil.
+	Extend(ctx context.Context, key, token string, lockedUntil time.Time) error
+	Delete(ctx context.Context, key, token string) error
+	// DeleteExpired удаляет истёкшие ключи всех арендаторов.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+type APIKeyRepository interface {
+	Insert(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*entity.APIKey, error)
+	FindByID(ctx context.Context, id int) (*entity.APIKey, error)
+	FindAll(ctx context.Context) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, id int, revokedAt time.Time) error
+}
+
+type WebhookRepository interface {
+	Insert(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+	FindByID(ctx context.Context, id int) (*entity.Webhook, error)
+	FindAll(ctx context.Context) ([]*entity.Webhook, error)
+	FindByEvent(ctx context.Context, event string) ([]*entity.Webhook, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type WebhookDeliveryRepository interface {
+	// Insert сохраняет доставку и возвращает false, если событие уже
+	// поставлено в очередь этого вебхука.
+	Insert(ctx context.Context, delivery *entity.WebhookDelivery) (bool, error)
+	// Claim выбирает до limit доставок, которым пора отправляться, и
+	// откладывает их следующую попытку до leaseUntil, чтобы их не взял
+	// другой экземпляр сервиса.
+	Claim(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *entity.WebhookDelivery) error
+	FindByWebhook(ctx context.Context, webhookID, limit int) ([]*entity.WebhookDelivery, error)
+}
+
+// OutboxRepository хранит сообщения всех арендаторов: их читает фоновый
+// диспетчер, поэтому запросы не ограничиваются арендатором из контекста.
+type OutboxRepository interface {
+	// Insert сохраняет сообщения в транзакции из контекста. Сообщения с уже
+	// сохранёнными идентификаторами пропускаются.
+	Insert(ctx context.Context, messages ...*entity.OutboxMessage) error
+	// Claim выбирает до limit самых старых неотправленных сообщений, которым
+	// пора отправляться, и откладывает их следующую попытку до leaseUntil.
+	// Порядок возвращённых сообщений не гарантируется.
+	Claim(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.OutboxMessage, error)
+	Update(ctx context.Context, message *entity.OutboxMessage) error
+	// DeletePublished удаляет сообщения, отправленные раньше before.
+	DeletePublished(ctx context.Context, before time.Time) (int, error)
+	// FindAfter возвращает до limit сообщений арендатора из контекста с
+	// типом из types и номером больше seq в порядке номеров. Сообщения
+	// выдаются только после фиксации всех транзакций, которые могли получить
+	// меньшие номера, поэтому чтение по номеру ничего не пропускает.
+	FindAfter(ctx context.Context, seq int64, types []string, limit int) ([]*entity.OutboxMessage, error)
+	// LastSeq возвращает номер, после которого FindAfter выдаст только новые
+	// сообщения.
+	LastSeq(ctx context.Context) (int64, error)
+}
+
+type ReminderRepository interface {
+	// Reserve отмечает напоминание отправленным и возвращает false, если
+	// оно уже было отправлено.
+	Reserve(ctx context.Context, reminder *entity.Reminder) (bool, error)
+	// Delete снимает отметку, если напоминание не удалось отправить.
+	Delete(ctx context.Context, reminder *entity.Reminder) error
+}
+
+// RateLimitStore хранит корзины отдельно для каждого арендатора из контекста.
+type RateLimitStore interface {
+	// Take берёт из корзины key n токенов: столько стоит запрос.
+	Take(ctx context.Context, key string, n int, limit entity.RateLimit) (*entity.RateLimitResult, error)
+	// DeleteExpired удаляет корзины всех арендаторов, полностью восстановившиеся
+	// к before: они неотличимы от отсутствующих.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
 }
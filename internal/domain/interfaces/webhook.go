@@ -0,0 +1,15 @@
+package interfaces
+
+import "context"
+
+// WebhookRequest — подписанный запрос к адресу вебхука.
+type WebhookRequest struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// WebhookSender отправляет запрос вебхука и возвращает HTTP-статус ответа.
+type WebhookSender interface {
+	Send(ctx context.Context, req WebhookRequest) (int, error)
+}
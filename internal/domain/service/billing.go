@@ -0,0 +1,17 @@
+package service
+
+import "time"
+
+// BillingDates возвращает даты списаний в промежутке (from, to]. Подписки
+// оплачиваются помесячно, поэтому списание приходится на первое число месяца.
+func BillingDates(from, to time.Time) []time.Time {
+	from, to = from.UTC(), to.UTC()
+
+	dates := make([]time.Time, 0)
+	date := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	for ; !date.After(to); date = date.AddDate(0, 1, 0) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
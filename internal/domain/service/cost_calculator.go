@@ -28,6 +28,14 @@ func (calculator *CostCalculator) SingleCost(
 	sub *entity.Subscription,
 	startDate time.Time,
 	endDate time.Time,
+) int {
+	return calculator.Months(sub, startDate, endDate) * sub.Price
+}
+
+func (calculator *CostCalculator) Months(
+	sub *entity.Subscription,
+	startDate time.Time,
+	endDate time.Time,
 ) int {
 	minDate := goext.MaxTime(startDate, sub.StartDate)
 	maxDate := endDate
@@ -36,6 +44,11 @@ func (calculator *CostCalculator) SingleCost(
 		maxDate = goext.MinTime(endDate, *sub.EndDate)
 	}
 
-	months := goext.MonthsBetween(minDate, maxDate)
-	return months * sub.Price
+	// Подписка не пересекается с периодом: MonthsBetween вернул бы
+	// расстояние между датами, а не ноль.
+	if maxDate.Before(minDate) {
+		return 0
+	}
+
+	return goext.MonthsBetween(minDate, maxDate)
 }
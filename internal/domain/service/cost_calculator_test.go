@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/service"
+)
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func subscription(price int, start time.Time, end *time.Time) *entity.Subscription {
+	return &entity.Subscription{ServiceName: "Netflix", Price: price, StartDate: start, EndDate: end}
+}
+
+func TestCostCalculatorMonths(t *testing.T) {
+	calculator := service.NewCostCalculator()
+	from, to := month(2025, time.January), month(2025, time.June)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   *time.Time
+		want  int
+	}{
+		{name: "covers period", start: month(2020, time.January), want: 5},
+		{name: "starts inside period", start: month(2025, time.March), want: 3},
+		{name: "inside period", start: month(2025, time.February), end: ptr(month(2025, time.April)), want: 2},
+		{name: "ends inside period", start: month(2024, time.June), end: ptr(month(2025, time.February)), want: 1},
+		{name: "ends at period start", start: month(2024, time.June), end: ptr(from), want: 0},
+		{name: "ended before period", start: month(2024, time.January), end: ptr(month(2024, time.June)), want: 0},
+		{name: "starts after period", start: month(2025, time.September), want: 0},
+		{
+			name:  "starts after period and has end",
+			start: month(2026, time.January),
+			end:   ptr(month(2026, time.December)),
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := subscription(400, tt.start, tt.end)
+
+			if got := calculator.Months(sub, from, to); got != tt.want {
+				t.Fatalf("Months = %d, want %d", got, tt.want)
+			}
+			if got := calculator.SingleCost(sub, from, to); got != tt.want*sub.Price {
+				t.Fatalf("SingleCost = %d, want %d", got, tt.want*sub.Price)
+			}
+		})
+	}
+}
+
+func TestCostCalculatorTotalCost(t *testing.T) {
+	calculator := service.NewCostCalculator()
+
+	subs := []*entity.Subscription{
+		subscription(400, month(2025, time.January), nil),
+		subscription(250, month(2025, time.April), ptr(month(2025, time.May))),
+		// Не пересекается с периодом и не должна уменьшать сумму.
+		subscription(1000, month(2023, time.January), ptr(month(2023, time.December))),
+	}
+
+	got := calculator.TotalCost(subs, month(2025, time.January), month(2025, time.June))
+	if want := 5*400 + 1*250; got != want {
+		t.Fatalf("TotalCost = %d, want %d", got, want)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
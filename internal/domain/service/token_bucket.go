@@ -0,0 +1,41 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+)
+
+// TakeTokens пополняет корзину за время с последнего обращения и пытается взять из неё
+// n токенов. Корзина без обращений (нулевой UpdatedAt) считается полной. Запрос дороже
+// ёмкости корзины стоит всю корзину: иначе он не выполнился бы никогда.
+func TakeTokens(
+	bucket entity.TokenBucket,
+	limit entity.RateLimit,
+	n int,
+	now time.Time,
+) (entity.TokenBucket, entity.RateLimitResult) {
+	capacity := float64(limit.Limit)
+	perToken := limit.Period / time.Duration(limit.Limit)
+	cost := math.Min(float64(max(n, 1)), capacity)
+
+	tokens := capacity
+	if !bucket.UpdatedAt.IsZero() {
+		elapsed := now.Sub(bucket.UpdatedAt)
+		tokens = math.Min(capacity, bucket.Tokens+float64(elapsed)/float64(perToken))
+	}
+
+	var result entity.RateLimitResult
+	if tokens >= cost {
+		tokens -= cost
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((cost - tokens) * float64(perToken))
+	}
+
+	result.Remaining = int(tokens)
+	result.Reset = time.Duration((capacity - tokens) * float64(perToken))
+
+	return entity.TokenBucket{Tokens: tokens, UpdatedAt: now}, result
+}
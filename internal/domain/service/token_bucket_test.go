@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/service"
+)
+
+func TestTakeToken(t *testing.T) {
+	// 10 запросов за 10 секунд: один токен восстанавливается за секунду.
+	limit := entity.RateLimit{Limit: 10, Period: 10 * time.Second}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		bucket     entity.TokenBucket
+		want       entity.RateLimitResult
+		wantTokens float64
+	}{
+		{
+			name:       "new bucket is full",
+			bucket:     entity.TokenBucket{},
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 9, Reset: time.Second},
+			wantTokens: 9,
+		},
+		{
+			name:       "last token",
+			bucket:     entity.TokenBucket{Tokens: 1, UpdatedAt: now},
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 0, Reset: 10 * time.Second},
+			wantTokens: 0,
+		},
+		{
+			name:       "empty bucket",
+			bucket:     entity.TokenBucket{Tokens: 0, UpdatedAt: now},
+			want:       entity.RateLimitResult{RetryAfter: time.Second, Reset: 10 * time.Second},
+			wantTokens: 0,
+		},
+		{
+			name:       "partial token is not enough",
+			bucket:     entity.TokenBucket{Tokens: 0, UpdatedAt: now.Add(-500 * time.Millisecond)},
+			want:       entity.RateLimitResult{RetryAfter: 500 * time.Millisecond, Reset: 9500 * time.Millisecond},
+			wantTokens: 0.5,
+		},
+		{
+			name:       "refill since last request",
+			bucket:     entity.TokenBucket{Tokens: 2, UpdatedAt: now.Add(-3 * time.Second)},
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 4, Reset: 6 * time.Second},
+			wantTokens: 4,
+		},
+		{
+			name:       "refill is capped by limit",
+			bucket:     entity.TokenBucket{Tokens: 5, UpdatedAt: now.Add(-time.Hour)},
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 9, Reset: time.Second},
+			wantTokens: 9,
+		},
+		{
+			name:       "fractional remainder is rounded down",
+			bucket:     entity.TokenBucket{Tokens: 1.5, UpdatedAt: now},
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 0, Reset: 9500 * time.Millisecond},
+			wantTokens: 0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, result := service.TakeTokens(tt.bucket, limit, 1, now)
+
+			if result != tt.want {
+				t.Fatalf("result = %+v, want %+v", result, tt.want)
+			}
+			if bucket.Tokens != tt.wantTokens {
+				t.Fatalf("tokens = %v, want %v", bucket.Tokens, tt.wantTokens)
+			}
+			if !bucket.UpdatedAt.Equal(now) {
+				t.Fatalf("updated at = %v, want %v", bucket.UpdatedAt, now)
+			}
+		})
+	}
+}
+
+func TestTakeTokenSequence(t *testing.T) {
+	limit := entity.RateLimit{Limit: 3, Period: 3 * time.Second}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		after   time.Duration
+		allowed bool
+	}{
+		{after: 0, allowed: true},
+		{after: 0, allowed: true},
+		{after: 0, allowed: true},
+		{after: 0, allowed: false},
+		{after: 999 * time.Millisecond, allowed: false},
+		{after: time.Millisecond, allowed: true},
+		{after: 0, allowed: false},
+		{after: 10 * time.Second, allowed: true},
+		{after: 0, allowed: true},
+		{after: 0, allowed: true},
+		{after: 0, allowed: false},
+	}
+
+	var bucket entity.TokenBucket
+	for i, step := range steps {
+		now = now.Add(step.after)
+
+		var result entity.RateLimitResult
+		bucket, result = service.TakeTokens(bucket, limit, 1, now)
+		if result.Allowed != step.allowed {
+			t.Fatalf("step %d: allowed = %t, want %t", i, result.Allowed, step.allowed)
+		}
+	}
+}
+
+func TestTakeTokensCost(t *testing.T) {
+	limit := entity.RateLimit{Limit: 10, Period: 10 * time.Second}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		bucket     entity.TokenBucket
+		n          int
+		want       entity.RateLimitResult
+		wantTokens float64
+	}{
+		{
+			name:       "several tokens",
+			bucket:     entity.TokenBucket{},
+			n:          4,
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 6, Reset: 4 * time.Second},
+			wantTokens: 6,
+		},
+		{
+			name:       "not enough tokens",
+			bucket:     entity.TokenBucket{Tokens: 3, UpdatedAt: now},
+			n:          5,
+			want:       entity.RateLimitResult{Remaining: 3, RetryAfter: 2 * time.Second, Reset: 7 * time.Second},
+			wantTokens: 3,
+		},
+		{
+			name:       "cost above limit takes full bucket",
+			bucket:     entity.TokenBucket{},
+			n:          90,
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 0, Reset: 10 * time.Second},
+			wantTokens: 0,
+		},
+		{
+			name:       "cost above limit waits for full bucket",
+			bucket:     entity.TokenBucket{Tokens: 9, UpdatedAt: now},
+			n:          90,
+			want:       entity.RateLimitResult{RetryAfter: time.Second, Remaining: 9, Reset: time.Second},
+			wantTokens: 9,
+		},
+		{
+			name:       "zero cost takes one token",
+			bucket:     entity.TokenBucket{},
+			n:          0,
+			want:       entity.RateLimitResult{Allowed: true, Remaining: 9, Reset: time.Second},
+			wantTokens: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, result := service.TakeTokens(tt.bucket, limit, tt.n, now)
+
+			if result != tt.want {
+				t.Fatalf("result = %+v, want %+v", result, tt.want)
+			}
+			if bucket.Tokens != tt.wantTokens {
+				t.Fatalf("tokens = %v, want %v", bucket.Tokens, tt.wantTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package tenant
+
+import "context"
+
+// Default используется, когда мультиарендность выключена или арендатор не указан.
+const Default = "default"
+
+type tenantKey struct{}
+
+type allTenantsKey struct{}
+
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext возвращает арендатора запроса. Репозитории ограничивают по нему
+// каждый запрос, поэтому без арендатора в контексте используется Default.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+// WithAllTenants отмечает контекст фоновой задачи, которая обходит данные всех
+// арендаторов. В запросах API так ищутся только API-ключи: арендатор запроса
+// ещё не известен или запрос выполняет администратор без арендатора.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// AllTenants сообщает, отмечен ли контекст WithAllTenants.
+func AllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
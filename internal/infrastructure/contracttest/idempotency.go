@@ -0,0 +1,223 @@
+package contracttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+// IdempotencyFactory возвращает пустой репозиторий ключей для одной проверки.
+type IdempotencyFactory func(t *testing.T) interfaces.IdempotencyRepository
+
+// IdempotencyRepository проверяет контракт interfaces.IdempotencyRepository.
+func IdempotencyRepository(t *testing.T, newRepo IdempotencyFactory) {
+	t.Run("reserve and save response", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		record := reserve(t, ctx, repo, "key", "first", time.Minute)
+		if reserved := tryReserve(t, ctx, repo, "key", "second", time.Minute); reserved {
+			t.Fatal("reserved key that is in progress")
+		}
+
+		status := 201
+		record.StatusCode = &status
+		record.ResponseBody = []byte(`{"id":1}`)
+		record.ContentType = "application/json"
+		record.Location = "/subscriptions/1"
+		if err := repo.SaveResponse(ctx, record); err != nil {
+			t.Fatalf("SaveResponse: %v", err)
+		}
+
+		found := findKey(t, ctx, repo, "key")
+		if found.StatusCode == nil || *found.StatusCode != status || string(found.ResponseBody) != `{"id":1}` ||
+			found.ContentType != record.ContentType || found.Location != record.Location {
+			t.Fatalf("found = %+v, want saved response", found)
+		}
+		if found.RequestHash != record.RequestHash {
+			t.Fatalf("request hash = %q, want %q", found.RequestHash, record.RequestHash)
+		}
+
+		if reserved := tryReserve(t, ctx, repo, "key", "second", time.Minute); reserved {
+			t.Fatal("reserved key with saved response")
+		}
+		// Ключ с ответом освобождается только по истечении ttl.
+		if err := repo.Delete(ctx, "key", "first"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		findKey(t, ctx, repo, "key")
+	})
+
+	t.Run("only owner changes key", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		reserve(t, ctx, repo, "key", "first", time.Minute)
+
+		status := 201
+		if err := repo.SaveResponse(ctx, &entity.IdempotencyRecord{
+			Key:        "key",
+			Token:      "other",
+			StatusCode: &status,
+		}); err != nil {
+			t.Fatalf("SaveResponse: %v", err)
+		}
+		if err := repo.Delete(ctx, "key", "other"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if found := findKey(t, ctx, repo, "key"); found.StatusCode != nil {
+			t.Fatalf("status code = %d, want response not saved", *found.StatusCode)
+		}
+
+		if err := repo.Delete(ctx, "key", "first"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.FindByKey(ctx, "key"); !errors.Is(err, failure.ErrIdempotencyKeyNotFound) {
+			t.Fatalf("FindByKey error = %v, want ErrIdempotencyKeyNotFound", err)
+		}
+	})
+
+	t.Run("retry takes key after lease", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		stale := reserve(t, ctx, repo, "key", "first", -time.Second)
+		reserve(t, ctx, repo, "key", "second", time.Minute)
+
+		// Запрос, аренда которого истекла, не затирает и не освобождает ключ повтора.
+		status := 201
+		stale.StatusCode = &status
+		if err := repo.SaveResponse(ctx, stale); err != nil {
+			t.Fatalf("SaveResponse: %v", err)
+		}
+		if err := repo.Delete(ctx, "key", stale.Token); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repo.Extend(ctx, "key", stale.Token, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Extend: %v", err)
+		}
+
+		found := findKey(t, ctx, repo, "key")
+		if found.StatusCode != nil {
+			t.Fatalf("status code = %d, want response not saved", *found.StatusCode)
+		}
+		if found.LockedUntil.After(time.Now().Add(2 * time.Minute)) {
+			t.Fatalf("locked until = %s, want lease of retry", found.LockedUntil)
+		}
+	})
+
+	t.Run("extend keeps key", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		record := reserve(t, ctx, repo, "key", "first", time.Second)
+
+		if err := repo.Extend(ctx, "key", record.Token, time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("Extend: %v", err)
+		}
+		if !tryReserve(t, ctx, repo, "key", "second", time.Second) {
+			t.Fatal("key with expired lease not reserved")
+		}
+
+		if err := repo.Extend(ctx, "key", "second", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Extend: %v", err)
+		}
+		if tryReserve(t, ctx, repo, "key", "third", time.Second) {
+			t.Fatal("reserved key with extended lease")
+		}
+	})
+
+	t.Run("tenants do not share keys", func(t *testing.T) {
+		repo := newRepo(t)
+		acme := tenant.WithTenant(context.Background(), "acme")
+		globex := tenant.WithTenant(context.Background(), "globex")
+
+		reserve(t, acme, repo, "key", "first", time.Minute)
+		reserve(t, globex, repo, "key", "second", time.Minute)
+
+		if err := repo.Delete(globex, "key", "first"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		findKey(t, acme, repo, "key")
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		reserve(t, ctx, repo, "key", "first", time.Minute)
+
+		deleted, err := repo.DeleteExpired(ctx, time.Now().Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("DeleteExpired: %v", err)
+		}
+		if deleted != 1 {
+			t.Fatalf("deleted = %d, want 1", deleted)
+		}
+		if _, err := repo.FindByKey(ctx, "key"); !errors.Is(err, failure.ErrIdempotencyKeyNotFound) {
+			t.Fatalf("FindByKey error = %v, want ErrIdempotencyKeyNotFound", err)
+		}
+	})
+}
+
+func tryReserve(
+	t *testing.T,
+	ctx context.Context,
+	repo interfaces.IdempotencyRepository,
+	key string,
+	token string,
+	lease time.Duration,
+) bool {
+	t.Helper()
+
+	now := time.Now()
+	reserved, err := repo.Reserve(ctx, &entity.IdempotencyRecord{
+		Key:         key,
+		RequestHash: "hash",
+		Token:       token,
+		LockedUntil: now.Add(lease),
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Reserve(%q): %v", key, err)
+	}
+	return reserved
+}
+
+func reserve(
+	t *testing.T,
+	ctx context.Context,
+	repo interfaces.IdempotencyRepository,
+	key string,
+	token string,
+	lease time.Duration,
+) *entity.IdempotencyRecord {
+	t.Helper()
+
+	if !tryReserve(t, ctx, repo, key, token, lease) {
+		t.Fatalf("key %q with token %q not reserved", key, token)
+	}
+	return &entity.IdempotencyRecord{Key: key, RequestHash: "hash", Token: token}
+}
+
+func findKey(
+	t *testing.T,
+	ctx context.Context,
+	repo interfaces.IdempotencyRepository,
+	key string,
+) *entity.IdempotencyRecord {
+	t.Helper()
+
+	found, err := repo.FindByKey(ctx, key)
+	if err != nil {
+		t.Fatalf("FindByKey(%q): %v", key, err)
+	}
+	return found
+}
@@ -0,0 +1,183 @@
+package contracttest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+// RateLimitStoreFactory возвращает пустое хранилище корзин для одной проверки.
+type RateLimitStoreFactory func(t *testing.T) interfaces.RateLimitStore
+
+// Период выбран большим, чтобы корзина не восстанавливалась за время проверки.
+var testRateLimit = entity.RateLimit{Limit: 3, Period: time.Hour}
+
+// RateLimitStore проверяет контракт interfaces.RateLimitStore.
+func RateLimitStore(t *testing.T, newStore RateLimitStoreFactory) {
+	t.Run("allows limit requests", func(t *testing.T) {
+		store := newStore(t)
+		ctx := context.Background()
+
+		for i := range testRateLimit.Limit {
+			result := take(t, ctx, store, "read:user")
+			if !result.Allowed {
+				t.Fatalf("request %d denied", i+1)
+			}
+			if want := testRateLimit.Limit - i - 1; result.Remaining != want {
+				t.Fatalf("request %d: remaining = %d, want %d", i+1, result.Remaining, want)
+			}
+		}
+
+		result := take(t, ctx, store, "read:user")
+		if result.Allowed {
+			t.Fatal("request over limit allowed")
+		}
+		if result.RetryAfter <= 0 || result.RetryAfter > testRateLimit.Period/time.Duration(testRateLimit.Limit) {
+			t.Fatalf("retry after = %s, want up to one token period", result.RetryAfter)
+		}
+		if result.Reset <= 0 || result.Reset > testRateLimit.Period {
+			t.Fatalf("reset = %s, want up to %s", result.Reset, testRateLimit.Period)
+		}
+	})
+
+	t.Run("takes several tokens", func(t *testing.T) {
+		store := newStore(t)
+		ctx := context.Background()
+
+		result, err := store.Take(ctx, "costs:user", testRateLimit.Limit-1, testRateLimit)
+		if err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		if !result.Allowed || result.Remaining != 1 {
+			t.Fatalf("result = %+v, want allowed with 1 remaining", result)
+		}
+
+		result, err = store.Take(ctx, "costs:user", 2, testRateLimit)
+		if err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		if result.Allowed || result.Remaining != 1 {
+			t.Fatalf("result = %+v, want denied with 1 remaining", result)
+		}
+
+		if result := take(t, ctx, store, "costs:user"); !result.Allowed {
+			t.Fatal("request with remaining token denied")
+		}
+	})
+
+	t.Run("keys do not share buckets", func(t *testing.T) {
+		store := newStore(t)
+		ctx := context.Background()
+
+		exhaust(t, ctx, store, "read:user")
+
+		if result := take(t, ctx, store, "write:user"); !result.Allowed {
+			t.Fatal("request with other key denied")
+		}
+	})
+
+	t.Run("tenants do not share buckets", func(t *testing.T) {
+		store := newStore(t)
+
+		exhaust(t, tenant.WithTenant(context.Background(), "acme"), store, "read:user")
+
+		result := take(t, tenant.WithTenant(context.Background(), "globex"), store, "read:user")
+		if !result.Allowed {
+			t.Fatal("request of other tenant denied")
+		}
+	})
+
+	t.Run("delete expired buckets of all tenants", func(t *testing.T) {
+		store := newStore(t)
+		ctx := context.Background()
+
+		take(t, ctx, store, "read:user")
+		take(t, tenant.WithTenant(ctx, "acme"), store, "read:user")
+
+		deleted, err := store.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			t.Fatalf("DeleteExpired: %v", err)
+		}
+		if deleted != 0 {
+			t.Fatalf("deleted = %d, want no buckets before refill", deleted)
+		}
+
+		deleted, err = store.DeleteExpired(ctx, time.Now().Add(testRateLimit.Period))
+		if err != nil {
+			t.Fatalf("DeleteExpired: %v", err)
+		}
+		if deleted != 2 {
+			t.Fatalf("deleted = %d, want 2", deleted)
+		}
+
+		if result := take(t, ctx, store, "read:user"); result.Remaining != testRateLimit.Limit-1 {
+			t.Fatalf("remaining = %d, want full bucket", result.Remaining)
+		}
+	})
+
+	t.Run("concurrent requests do not exceed limit", func(t *testing.T) {
+		store := newStore(t)
+		ctx := context.Background()
+
+		const requests = 10
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			allowed int
+		)
+		for range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result, err := store.Take(ctx, "write:user", 1, testRateLimit)
+				if err != nil {
+					t.Errorf("Take: %v", err)
+					return
+				}
+				if result.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if allowed != testRateLimit.Limit {
+			t.Fatalf("allowed = %d, want %d", allowed, testRateLimit.Limit)
+		}
+	})
+}
+
+func take(
+	t *testing.T,
+	ctx context.Context,
+	store interfaces.RateLimitStore,
+	key string,
+) *entity.RateLimitResult {
+	t.Helper()
+
+	result, err := store.Take(ctx, key, 1, testRateLimit)
+	if err != nil {
+		t.Fatalf("Take(%q): %v", key, err)
+	}
+	return result
+}
+
+func exhaust(t *testing.T, ctx context.Context, store interfaces.RateLimitStore, key string) {
+	t.Helper()
+
+	for range testRateLimit.Limit {
+		take(t, ctx, store, key)
+	}
+	if result := take(t, ctx, store, key); result.Allowed {
+		t.Fatalf("request over limit allowed for %q", key)
+	}
+}
@@ -0,0 +1,380 @@
+// Package contracttest содержит общие проверки поведения репозиториев.
+// Каждая реализация (память, PostgreSQL, SQLite) запускает их в своих тестах,
+// чтобы все хранилища вели себя одинаково.
+package contracttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+const (
+	userA = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+	userB = "3c0ab1f2-8e2a-4d43-9b1c-0d6a2e5f7a11"
+)
+
+// SubscriptionFactory возвращает пустой репозиторий для одной проверки.
+type SubscriptionFactory func(t *testing.T) interfaces.SubscriptionRepository
+
+// SubscriptionRepository проверяет контракт interfaces.SubscriptionRepository.
+func SubscriptionRepository(t *testing.T, newRepo SubscriptionFactory) {
+	t.Run("insert and find by id", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		sub := insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil)
+
+		found, err := repo.FindByID(ctx, sub.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		assertSubscription(t, found, sub)
+
+		exists, err := repo.ExistsByID(ctx, sub.ID)
+		if err != nil || !exists {
+			t.Fatalf("ExistsByID = %t, %v, want true", exists, err)
+		}
+
+		if _, err := repo.FindByID(ctx, sub.ID+1000); !errors.Is(err, failure.ErrSubscriptionNotFound) {
+			t.Fatalf("FindByID(missing) error = %v, want ErrSubscriptionNotFound", err)
+		}
+	})
+
+	t.Run("user id is case-insensitive", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		sub := insert(t, ctx, repo, "Netflix", "60601FEE-2BF1-4721-AE6F-7636E79A0CBA", Month(2025, 1), nil)
+
+		found, err := repo.FindByID(ctx, sub.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if found.UserID != userA {
+			t.Fatalf("UserID = %q, want %q", found.UserID, userA)
+		}
+
+		for _, userID := range []string{userA, "60601FEE-2BF1-4721-AE6F-7636E79A0CBA"} {
+			total, err := repo.Total(ctx, &entity.SubscriptionFilter{UserID: userID})
+			if err != nil {
+				t.Fatalf("Total: %v", err)
+			}
+			if total != 1 {
+				t.Fatalf("Total(user_id=%s) = %d, want 1", userID, total)
+			}
+		}
+	})
+
+	t.Run("duplicate subscription", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil)
+		other := insert(t, ctx, repo, "Spotify", userA, Month(2025, 1), nil)
+
+		tests := []struct {
+			name string
+			call func() error
+		}{
+			{
+				name: "insert same service and user",
+				call: func() error {
+					_, err := repo.Insert(ctx, subscription("Netflix", userA, Month(2025, 3), nil))
+					return err
+				},
+			},
+			{
+				name: "insert with user id in upper case",
+				call: func() error {
+					_, err := repo.Insert(ctx, subscription(
+						"Netflix", "60601FEE-2BF1-4721-AE6F-7636E79A0CBA", Month(2025, 3), nil,
+					))
+					return err
+				},
+			},
+			{
+				name: "update into existing pair",
+				call: func() error {
+					sub := subscription("Netflix", userA, Month(2025, 1), nil)
+					sub.ID = other.ID
+					_, err := repo.Update(ctx, sub)
+					return err
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := tt.call(); !errors.Is(err, failure.ErrUserAlreadyHasThisSubscription) {
+					t.Fatalf("error = %v, want ErrUserAlreadyHasThisSubscription", err)
+				}
+			})
+		}
+
+		// Уникальность действует в пределах арендатора.
+		otherTenant := tenant.WithTenant(ctx, "acme")
+		if _, err := repo.Insert(otherTenant, subscription("Netflix", userA, Month(2025, 1), nil)); err != nil {
+			t.Fatalf("Insert in other tenant: %v", err)
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		sub := insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil)
+
+		updated := subscription("Netflix Premium", userA, Month(2025, 2), Month(2025, 6))
+		updated.ID = sub.ID
+		updated.Price = 900
+		if _, err := repo.Update(ctx, updated); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, sub.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		assertSubscription(t, found, updated)
+
+		if err := repo.Delete(ctx, sub.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.FindByID(ctx, sub.ID); !errors.Is(err, failure.ErrSubscriptionNotFound) {
+			t.Fatalf("FindByID after delete error = %v, want ErrSubscriptionNotFound", err)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		// Подписки за январь–март 2025, с апреля 2025 без окончания, за 2024 год
+		// и подписка другого пользователя.
+		q1 := insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), Month(2025, 3))
+		open := insert(t, ctx, repo, "Spotify", userA, Month(2025, 4), nil)
+		old := insert(t, ctx, repo, "Yandex", userA, Month(2024, 1), Month(2024, 12))
+		other := insert(t, ctx, repo, "Netflix", userB, Month(2025, 2), nil)
+		insert(t, tenant.WithTenant(ctx, "acme"), repo, "Netflix", userA, Month(2025, 1), nil)
+
+		tests := []struct {
+			name   string
+			filter entity.SubscriptionFilter
+			want   []int
+		}{
+			{
+				name: "no filter returns tenant subscriptions",
+				want: []int{q1.ID, open.ID, old.ID, other.ID},
+			},
+			{
+				name:   "service name",
+				filter: entity.SubscriptionFilter{ServiceName: "Netflix"},
+				want:   []int{q1.ID, other.ID},
+			},
+			{
+				name:   "user id",
+				filter: entity.SubscriptionFilter{UserID: userB},
+				want:   []int{other.ID},
+			},
+			{
+				name:   "start date excludes ended before it",
+				filter: entity.SubscriptionFilter{StartDate: Month(2025, 3)},
+				want:   []int{q1.ID, open.ID, other.ID},
+			},
+			{
+				name:   "start date after finished subscriptions",
+				filter: entity.SubscriptionFilter{StartDate: Month(2025, 4)},
+				want:   []int{open.ID, other.ID},
+			},
+			{
+				name:   "end date excludes started after it",
+				filter: entity.SubscriptionFilter{EndDate: Month(2025, 1)},
+				want:   []int{q1.ID, old.ID},
+			},
+			{
+				name: "period overlap",
+				filter: entity.SubscriptionFilter{
+					StartDate: Month(2025, 2),
+					EndDate:   Month(2025, 3),
+				},
+				want: []int{q1.ID, other.ID},
+			},
+			{
+				name: "all conditions",
+				filter: entity.SubscriptionFilter{
+					ServiceName: "Netflix",
+					UserID:      userA,
+					StartDate:   Month(2024, 6),
+					EndDate:     Month(2025, 1),
+				},
+				want: []int{q1.ID},
+			},
+			{
+				name:   "no matches",
+				filter: entity.SubscriptionFilter{ServiceName: "Kinopoisk"},
+				want:   []int{},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				all, err := repo.FindAll(ctx, &tt.filter)
+				if err != nil {
+					t.Fatalf("FindAll: %v", err)
+				}
+				assertIDs(t, "FindAll", all, tt.want)
+
+				total, err := repo.Total(ctx, &tt.filter)
+				if err != nil {
+					t.Fatalf("Total: %v", err)
+				}
+				if total != len(tt.want) {
+					t.Fatalf("Total = %d, want %d", total, len(tt.want))
+				}
+			})
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		ids := make([]int, 0, 5)
+		for _, service := range []string{"E", "D", "C", "B", "A"} {
+			ids = append(ids, insert(t, ctx, repo, service, userA, Month(2025, 1), nil).ID)
+		}
+
+		tests := []struct {
+			page  int
+			limit int
+			want  []int
+		}{
+			{page: 1, limit: 2, want: ids[0:2]},
+			{page: 2, limit: 2, want: ids[2:4]},
+			{page: 3, limit: 2, want: ids[4:5]},
+			{page: 4, limit: 2, want: []int{}},
+			{page: 1, limit: 10, want: ids},
+		}
+
+		for _, tt := range tests {
+			f := &entity.SubscriptionFilter{Page: tt.page, Limit: tt.limit}
+			found, err := repo.Find(ctx, f)
+			if err != nil {
+				t.Fatalf("Find(page=%d, limit=%d): %v", tt.page, tt.limit, err)
+			}
+			assertIDs(t, "Find", found, tt.want)
+		}
+	})
+
+	t.Run("tenant isolation", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+		acme := tenant.WithTenant(ctx, "acme")
+
+		sub := insert(t, acme, repo, "Netflix", userA, Month(2025, 1), nil)
+		if sub.TenantID != "acme" {
+			t.Fatalf("TenantID = %q, want acme", sub.TenantID)
+		}
+
+		if _, err := repo.FindByID(ctx, sub.ID); !errors.Is(err, failure.ErrSubscriptionNotFound) {
+			t.Fatalf("FindByID from other tenant error = %v, want ErrSubscriptionNotFound", err)
+		}
+		if err := repo.Delete(ctx, sub.ID); err != nil {
+			t.Fatalf("Delete from other tenant: %v", err)
+		}
+		if _, err := repo.FindByID(acme, sub.ID); err != nil {
+			t.Fatalf("subscription deleted from other tenant: %v", err)
+		}
+	})
+
+	t.Run("find ending compares months", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), Month(2025, 9))
+		current := insert(t, ctx, repo, "Spotify", userA, Month(2025, 1), Month(2025, 10))
+		next := insert(t, tenant.WithTenant(ctx, "acme"), repo, "Yandex", userA, Month(2025, 1), Month(2025, 11))
+		insert(t, ctx, repo, "Kinopoisk", userA, Month(2025, 1), Month(2025, 12))
+		insert(t, ctx, repo, "Okko", userA, Month(2025, 1), nil)
+
+		// Подписка, которая заканчивается в текущем месяце, хранит его первое число.
+		from := time.Date(2025, 10, 19, 12, 0, 0, 0, time.UTC)
+		found, err := repo.FindEnding(ctx, from, from.AddDate(0, 1, 0))
+		if err != nil {
+			t.Fatalf("FindEnding: %v", err)
+		}
+		assertIDs(t, "FindEnding", found, []int{current.ID, next.ID})
+	})
+}
+
+// Month возвращает первое число месяца: подписки хранят даты с точностью до месяца.
+func Month(year int, month time.Month) *time.Time {
+	date := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+func subscription(service, userID string, start, end *time.Time) *entity.Subscription {
+	return &entity.Subscription{
+		ServiceName: service,
+		Price:       400,
+		UserID:      userID,
+		StartDate:   *start,
+		EndDate:     end,
+	}
+}
+
+func insert(
+	t *testing.T,
+	ctx context.Context,
+	repo interfaces.SubscriptionRepository,
+	service, userID string,
+	start, end *time.Time,
+) *entity.Subscription {
+	t.Helper()
+
+	sub, err := repo.Insert(ctx, subscription(service, userID, start, end))
+	if err != nil {
+		t.Fatalf("Insert(%s): %v", service, err)
+	}
+	return sub
+}
+
+func assertSubscription(t *testing.T, got, want *entity.Subscription) {
+	t.Helper()
+
+	if got.ID != want.ID || got.ServiceName != want.ServiceName || got.Price != want.Price {
+		t.Fatalf("subscription = %+v, want %+v", got, want)
+	}
+	if !got.StartDate.Equal(want.StartDate) {
+		t.Fatalf("StartDate = %s, want %s", got.StartDate, want.StartDate)
+	}
+	if (got.EndDate == nil) != (want.EndDate == nil) ||
+		(got.EndDate != nil && !got.EndDate.Equal(*want.EndDate)) {
+		t.Fatalf("EndDate = %v, want %v", got.EndDate, want.EndDate)
+	}
+}
+
+func assertIDs(t *testing.T, name string, got []*entity.Subscription, want []int) {
+	t.Helper()
+
+	ids := make([]int, 0, len(got))
+	for _, sub := range got {
+		ids = append(ids, sub.ID)
+	}
+
+	if len(ids) != len(want) {
+		t.Fatalf("%s ids = %v, want %v", name, ids, want)
+	}
+	for i := range ids {
+		if ids[i] != want[i] {
+			t.Fatalf("%s ids = %v, want %v", name, ids, want)
+		}
+	}
+}
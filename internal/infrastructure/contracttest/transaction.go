@@ -0,0 +1,127 @@
+package contracttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// TxFactory возвращает менеджер транзакций и пустой репозиторий, который
+// участвует в его транзакциях.
+type TxFactory func(t *testing.T) (interfaces.TxManager, interfaces.SubscriptionRepository)
+
+var errRollback = errors.New("rollback")
+
+// TxManager проверяет контракт interfaces.TxManager. Хранилище должно допускать
+// запись вне транзакции, пока она открыта.
+func TxManager(t *testing.T, newStore TxFactory) {
+	t.Run("commit keeps changes", func(t *testing.T) {
+		txManager, repo := newStore(t)
+		ctx := context.Background()
+
+		var id int
+		err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			id = insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil).ID
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithinTransaction: %v", err)
+		}
+
+		if _, err := repo.FindByID(ctx, id); err != nil {
+			t.Fatalf("FindByID after commit: %v", err)
+		}
+	})
+
+	t.Run("rollback undoes only transaction changes", func(t *testing.T) {
+		txManager, repo := newStore(t)
+		ctx := context.Background()
+
+		updated := insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil)
+		deleted := insert(t, ctx, repo, "Spotify", userA, Month(2025, 1), nil)
+
+		var inserted, outside int
+		err := txManager.WithinTransaction(ctx, func(txCtx context.Context) error {
+			inserted = insert(t, txCtx, repo, "Yandex", userA, Month(2025, 1), nil).ID
+
+			sub := subscription("Netflix", userA, Month(2025, 6), nil)
+			sub.ID = updated.ID
+			if _, err := repo.Update(txCtx, sub); err != nil {
+				return err
+			}
+			if err := repo.Delete(txCtx, deleted.ID); err != nil {
+				return err
+			}
+
+			// Запись вне транзакции не должна откатиться вместе с ней.
+			outside = insert(t, ctx, repo, "Kinopoisk", userB, Month(2025, 1), nil).ID
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("WithinTransaction error = %v, want %v", err, errRollback)
+		}
+
+		if _, err := repo.FindByID(ctx, inserted); !errors.Is(err, failure.ErrSubscriptionNotFound) {
+			t.Fatalf("inserted subscription after rollback: %v, want ErrSubscriptionNotFound", err)
+		}
+
+		found, err := repo.FindByID(ctx, updated.ID)
+		if err != nil {
+			t.Fatalf("FindByID(updated): %v", err)
+		}
+		assertSubscription(t, found, updated)
+
+		if _, err := repo.FindByID(ctx, deleted.ID); err != nil {
+			t.Fatalf("deleted subscription after rollback: %v", err)
+		}
+		if _, err := repo.FindByID(ctx, outside); err != nil {
+			t.Fatalf("subscription written outside transaction after rollback: %v", err)
+		}
+	})
+
+	t.Run("rollback does not reuse ids", func(t *testing.T) {
+		txManager, repo := newStore(t)
+		ctx := context.Background()
+
+		var rolledBack int
+		err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			rolledBack = insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil).ID
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("WithinTransaction error = %v, want %v", err, errRollback)
+		}
+
+		next := insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil)
+		if next.ID <= rolledBack {
+			t.Fatalf("id after rollback = %d, want greater than %d", next.ID, rolledBack)
+		}
+	})
+
+	t.Run("nested call joins transaction", func(t *testing.T) {
+		txManager, repo := newStore(t)
+		ctx := context.Background()
+
+		var id int
+		err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+				id = insert(t, ctx, repo, "Netflix", userA, Month(2025, 1), nil).ID
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("WithinTransaction error = %v, want %v", err, errRollback)
+		}
+
+		if _, err := repo.FindByID(ctx, id); !errors.Is(err, failure.ErrSubscriptionNotFound) {
+			t.Fatalf("nested insert after rollback: %v, want ErrSubscriptionNotFound", err)
+		}
+	})
+}
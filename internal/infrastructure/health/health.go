@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/pkg/migrate"
+)
+
+var errPendingMigrations = errors.New("database schema is not up to date")
+
+type PingCheck struct {
+	name string
+	ping func(ctx context.Context) error
+}
+
+var _ interfaces.HealthCheck = (*PingCheck)(nil)
+
+// NewPingCheck проверяет соединение с базой данных функцией ping,
+// например pgxpool.Pool.Ping или sql.DB.PingContext.
+func NewPingCheck(name string, ping func(ctx context.Context) error) *PingCheck {
+	return &PingCheck{name: name, ping: ping}
+}
+
+func (check *PingCheck) Name() string {
+	return check.name
+}
+
+func (check *PingCheck) Check(ctx context.Context) entity.ComponentHealth {
+	start := time.Now()
+	err := check.ping(ctx)
+	details := map[string]any{"latency": time.Since(start).String()}
+
+	if err != nil {
+		return entity.ComponentHealth{
+			Status:  entity.HealthStatusDown,
+			Err:     err,
+			Details: details,
+		}
+	}
+	return entity.ComponentHealth{Status: entity.HealthStatusUp, Details: details}
+}
+
+type MigrationCheck struct {
+	migrator *migrate.Migrator
+}
+
+var _ interfaces.HealthCheck = (*MigrationCheck)(nil)
+
+// NewMigrationCheck считает сервис неготовым, пока схема БД отстаёт
+// от встроенных миграций или помечена как dirty.
+func NewMigrationCheck(migrator *migrate.Migrator) *MigrationCheck {
+	return &MigrationCheck{migrator: migrator}
+}
+
+func (check *MigrationCheck) Name() string {
+	return "migrations"
+}
+
+func (check *MigrationCheck) Check(ctx context.Context) entity.ComponentHealth {
+	status, err := check.migrator.Status(ctx)
+	if err != nil {
+		return entity.ComponentHealth{Status: entity.HealthStatusDown, Err: err}
+	}
+
+	pending := 0
+	for _, migration := range status.Migrations {
+		if !migration.Applied {
+			pending++
+		}
+	}
+
+	details := map[string]any{
+		"version": status.Version,
+		"dirty":   status.Dirty,
+		"pending": pending,
+	}
+
+	switch {
+	case status.Dirty:
+		err = migrate.ErrDirty
+	case pending > 0:
+		err = errPendingMigrations
+	default:
+		return entity.ComponentHealth{Status: entity.HealthStatusUp, Details: details}
+	}
+
+	return entity.ComponentHealth{
+		Status:  entity.HealthStatusDown,
+		Err:     err,
+		Details: details,
+	}
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type APIKeyRepository struct {
+	mu     sync.RWMutex
+	nextID int
+	keys   map[int]*entity.APIKey
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		nextID: 1,
+		keys:   make(map[int]*entity.APIKey),
+	}
+}
+
+var _ interfaces.APIKeyRepository = (*APIKeyRepository)(nil)
+
+func (repo *APIKeyRepository) Insert(
+	_ context.Context,
+	key *entity.APIKey,
+) (*entity.APIKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key.ID = repo.nextID
+	repo.nextID++
+	repo.keys[key.ID] = cloneAPIKey(key)
+
+	return key, nil
+}
+
+func (repo *APIKeyRepository) FindByHash(
+	_ context.Context,
+	hash string,
+) (*entity.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, key := range repo.keys {
+		if key.KeyHash == hash {
+			return cloneAPIKey(key), nil
+		}
+	}
+	return nil, failure.ErrAPIKeyNotFound
+}
+
+func (repo *APIKeyRepository) FindByID(_ context.Context, id int) (*entity.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	key, ok := repo.keys[id]
+	if !ok {
+		return nil, failure.ErrAPIKeyNotFound
+	}
+	return cloneAPIKey(key), nil
+}
+
+func (repo *APIKeyRepository) FindAll(_ context.Context) ([]*entity.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	keys := make([]*entity.APIKey, 0, len(repo.keys))
+	for _, key := range repo.keys {
+		keys = append(keys, cloneAPIKey(key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
+
+func (repo *APIKeyRepository) Revoke(_ context.Context, id int, revokedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return failure.ErrAPIKeyNotFound
+	}
+
+	key.RevokedAt = &revokedAt
+	return nil
+}
+
+func cloneAPIKey(key *entity.APIKey) *entity.APIKey {
+	c := *key
+	c.Scopes = slices.Clone(key.Scopes)
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		c.RevokedAt = &revokedAt
+	}
+	return &c
+}
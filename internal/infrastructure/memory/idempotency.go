@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+// tenantKey отделяет ключи разных арендаторов.
+type tenantKey struct {
+	tenant string
+	key    string
+}
+
+func keyOf(ctx context.Context, key string) tenantKey {
+	return tenantKey{tenant: tenant.FromContext(ctx), key: key}
+}
+
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[tenantKey]*entity.IdempotencyRecord
+}
+
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{
+		records: make(map[tenantKey]*entity.IdempotencyRecord),
+	}
+}
+
+var _ interfaces.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+func (repo *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	if stored, ok := repo.records[keyOf(ctx, record.Key)]; ok && stored.ExpiresAt.After(now) {
+		if stored.StatusCode != nil || stored.LockedUntil.After(now) {
+			return false, nil
+		}
+	}
+
+	repo.records[keyOf(ctx, record.Key)] = &entity.IdempotencyRecord{
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		Token:       record.Token,
+		LockedUntil: record.LockedUntil,
+		ExpiresAt:   record.ExpiresAt,
+	}
+	return true, nil
+}
+
+func (repo *IdempotencyRepository) FindByKey(
+	ctx context.Context,
+	key string,
+) (*entity.IdempotencyRecord, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.records[keyOf(ctx, key)]
+	if !ok || !stored.ExpiresAt.After(time.Now()) {
+		return nil, failure.ErrIdempotencyKeyNotFound
+	}
+
+	record := *stored
+	return &record, nil
+}
+
+func (repo *IdempotencyRepository) SaveResponse(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.held(ctx, record.Key, record.Token)
+	if !ok {
+		return nil
+	}
+
+	stored.StatusCode = record.StatusCode
+	stored.ResponseBody = record.ResponseBody
+	stored.ContentType = record.ContentType
+	stored.Location = record.Location
+	return nil
+}
+
+func (repo *IdempotencyRepository) Extend(
+	ctx context.Context,
+	key string,
+	token string,
+	lockedUntil time.Time,
+) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if stored, ok := repo.held(ctx, key, token); ok {
+		stored.LockedUntil = lockedUntil
+	}
+	return nil
+}
+
+func (repo *IdempotencyRepository) Delete(ctx context.Context, key, token string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.held(ctx, key, token); ok {
+		delete(repo.records, keyOf(ctx, key))
+	}
+	return nil
+}
+
+// held возвращает ключ без ответа, занятый запросом с токеном token.
+func (repo *IdempotencyRepository) held(
+	ctx context.Context,
+	key string,
+	token string,
+) (*entity.IdempotencyRecord, bool) {
+	stored, ok := repo.records[keyOf(ctx, key)]
+	if !ok || stored.Token != token || stored.StatusCode != nil {
+		return nil, false
+	}
+	return stored, true
+}
+
+// DeleteExpired не ограничивается арендатором: её вызывает фоновая задача
+// очистки ключей всех арендаторов.
+func (repo *IdempotencyRepository) DeleteExpired(_ context.Context, before time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var deleted int
+	for key, record := range repo.records {
+		if !record.ExpiresAt.After(before) {
+			delete(repo.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
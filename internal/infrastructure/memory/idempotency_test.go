@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	contracttest.IdempotencyRepository(t, func(*testing.T) interfaces.IdempotencyRepository {
+		return memory.NewIdempotencyRepository()
+	})
+}
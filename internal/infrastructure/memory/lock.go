@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// Locker — блокировки в памяти процесса. Подходит, когда сервис запущен
+// в одном экземпляре.
+type Locker struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewLocker() *Locker {
+	return &Locker{
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+var _ interfaces.Locker = (*Locker)(nil)
+
+func (locker *Locker) TryLock(_ context.Context, name string) (func(), bool, error) {
+	locker.mu.Lock()
+	lock, ok := locker.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		locker.locks[name] = lock
+	}
+	locker.mu.Unlock()
+
+	if !lock.TryLock() {
+		return nil, false, nil
+	}
+	return lock.Unlock, true, nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// OutboxNotifier оповещает читателей outbox в памяти процесса. Подходит,
+// когда сервис запущен в одном экземпляре.
+type OutboxNotifier struct {
+	mu        sync.Mutex
+	listeners map[chan struct{}]struct{}
+}
+
+func NewOutboxNotifier() *OutboxNotifier {
+	return &OutboxNotifier{
+		listeners: make(map[chan struct{}]struct{}),
+	}
+}
+
+var _ interfaces.OutboxNotifier = (*OutboxNotifier)(nil)
+
+func (notifier *OutboxNotifier) Notify(_ context.Context) error {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	for ch := range notifier.listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (notifier *OutboxNotifier) Listen(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	notifier.mu.Lock()
+	notifier.listeners[ch] = struct{}{}
+	notifier.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+
+		delete(notifier.listeners, ch)
+		close(ch)
+	})
+	return ch
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/goext"
+)
+
+type OutboxRepository struct {
+	mu       sync.Mutex
+	seq      int64
+	messages map[string]*entity.OutboxMessage
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{
+		messages: make(map[string]*entity.OutboxMessage),
+	}
+}
+
+var _ interfaces.OutboxRepository = (*OutboxRepository)(nil)
+
+func (repo *OutboxRepository) Insert(ctx context.Context, messages ...*entity.OutboxMessage) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, message := range messages {
+		if _, ok := repo.messages[message.ID]; !ok {
+			repo.seq++
+			stored := cloneOutboxMessage(message)
+			stored.Seq = repo.seq
+			repo.messages[message.ID] = stored
+			onRollback(ctx, func() { repo.remove(stored) })
+		}
+	}
+	return nil
+}
+
+func (repo *OutboxRepository) Claim(
+	_ context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*entity.OutboxMessage, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	due := make([]*entity.OutboxMessage, 0)
+	for _, message := range repo.messages {
+		if message.PublishedAt == nil && !message.NextAttemptAt.After(now) {
+			due = append(due, message)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].OccurredAt.Before(due[j].OccurredAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*entity.OutboxMessage, 0, len(due))
+	for _, message := range due {
+		message.NextAttemptAt = leaseUntil
+		claimed = append(claimed, cloneOutboxMessage(message))
+	}
+	return claimed, nil
+}
+
+func (repo *OutboxRepository) Update(_ context.Context, message *entity.OutboxMessage) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.messages[message.ID]; ok {
+		repo.messages[message.ID] = cloneOutboxMessage(message)
+	}
+	return nil
+}
+
+func (repo *OutboxRepository) DeletePublished(_ context.Context, before time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	deleted := 0
+	for id, message := range repo.messages {
+		if message.PublishedAt != nil && message.PublishedAt.Before(before) {
+			delete(repo.messages, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (repo *OutboxRepository) FindAfter(
+	ctx context.Context,
+	seq int64,
+	types []string,
+	limit int,
+) ([]*entity.OutboxMessage, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	found := make([]*entity.OutboxMessage, 0)
+	for _, message := range repo.messages {
+		if message.Seq > seq && message.TenantID == tenantID && slices.Contains(types, message.Type) {
+			found = append(found, message)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Seq < found[j].Seq })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	return goext.Map(found, cloneOutboxMessage), nil
+}
+
+func (repo *OutboxRepository) LastSeq(_ context.Context) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.seq, nil
+}
+
+// remove откатывает вставку сообщения. Номер seq повторно не выдаётся.
+func (repo *OutboxRepository) remove(message *entity.OutboxMessage) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if repo.messages[message.ID] == message {
+		delete(repo.messages, message.ID)
+	}
+}
+
+func cloneOutboxMessage(message *entity.OutboxMessage) *entity.OutboxMessage {
+	c := *message
+	c.Payload = slices.Clone(message.Payload)
+	if message.PublishedAt != nil {
+		publishedAt := *message.PublishedAt
+		c.PublishedAt = &publishedAt
+	}
+	return &c
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/service"
+)
+
+type rateLimitBucket struct {
+	bucket    entity.TokenBucket
+	expiresAt time.Time
+}
+
+type RateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[tenantKey]*rateLimitBucket
+}
+
+func NewRateLimitStore() *RateLimitStore {
+	return &RateLimitStore{
+		buckets: make(map[tenantKey]*rateLimitBucket),
+	}
+}
+
+var _ interfaces.RateLimitStore = (*RateLimitStore)(nil)
+
+func (store *RateLimitStore) Take(
+	ctx context.Context,
+	key string,
+	n int,
+	limit entity.RateLimit,
+) (*entity.RateLimitResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+
+	stored, ok := store.buckets[keyOf(ctx, key)]
+	if !ok {
+		stored = &rateLimitBucket{}
+		store.buckets[keyOf(ctx, key)] = stored
+	}
+
+	bucket, result := service.TakeTokens(stored.bucket, limit, n, now)
+	stored.bucket = bucket
+	stored.expiresAt = now.Add(result.Reset)
+
+	return &result, nil
+}
+
+func (store *RateLimitStore) DeleteExpired(_ context.Context, before time.Time) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var deleted int
+	for key, stored := range store.buckets {
+		if !stored.expiresAt.After(before) {
+			delete(store.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type reminderKey struct {
+	tenantID       string
+	subscriptionID int
+	kind           string
+	dueDate        string
+}
+
+type ReminderRepository struct {
+	mu   sync.Mutex
+	sent map[reminderKey]struct{}
+}
+
+func NewReminderRepository() *ReminderRepository {
+	return &ReminderRepository{
+		sent: make(map[reminderKey]struct{}),
+	}
+}
+
+var _ interfaces.ReminderRepository = (*ReminderRepository)(nil)
+
+func (repo *ReminderRepository) Reserve(_ context.Context, reminder *entity.Reminder) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := newReminderKey(reminder)
+	if _, ok := repo.sent[key]; ok {
+		return false, nil
+	}
+
+	repo.sent[key] = struct{}{}
+	return true, nil
+}
+
+func (repo *ReminderRepository) Delete(_ context.Context, reminder *entity.Reminder) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.sent, newReminderKey(reminder))
+	return nil
+}
+
+func newReminderKey(reminder *entity.Reminder) reminderKey {
+	return reminderKey{
+		tenantID:       reminder.Subscription.TenantID,
+		subscriptionID: reminder.Subscription.ID,
+		kind:           reminder.Kind,
+		dueDate:        reminder.DueDate.Format("2006-01-02"),
+	}
+}
@@ -0,0 +1,281 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+type SubscriptionRepository struct {
+	mu            sync.RWMutex
+	nextID        int
+	subscriptions map[int]*entity.Subscription
+}
+
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		nextID:        1,
+		subscriptions: make(map[int]*entity.Subscription),
+	}
+}
+
+var _ interfaces.SubscriptionRepository = (*SubscriptionRepository)(nil)
+
+func (repo *SubscriptionRepository) Insert(
+	ctx context.Context,
+	sub *entity.Subscription,
+) (*entity.Subscription, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored := normalize(sub)
+	stored.TenantID = tenant.FromContext(ctx)
+	if repo.conflicts(stored) {
+		return nil, failure.ErrUserAlreadyHasThisSubscription
+	}
+
+	stored.ID = repo.nextID
+	repo.nextID++
+	repo.subscriptions[stored.ID] = stored
+	onRollback(ctx, func() { repo.restore(stored.ID, stored, nil) })
+
+	sub.ID = stored.ID
+	sub.TenantID = stored.TenantID
+	return sub, nil
+}
+
+func (repo *SubscriptionRepository) Update(
+	ctx context.Context,
+	sub *entity.Subscription,
+) (*entity.Subscription, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored := normalize(sub)
+	stored.TenantID = tenant.FromContext(ctx)
+	if repo.conflicts(stored) {
+		return nil, failure.ErrUserAlreadyHasThisSubscription
+	}
+
+	if prev, ok := repo.get(ctx, stored.ID); ok {
+		repo.subscriptions[stored.ID] = stored
+		onRollback(ctx, func() { repo.restore(stored.ID, stored, prev) })
+	}
+
+	return sub, nil
+}
+
+func (repo *SubscriptionRepository) Delete(ctx context.Context, id int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if prev, ok := repo.get(ctx, id); ok {
+		delete(repo.subscriptions, id)
+		onRollback(ctx, func() { repo.restore(id, nil, prev) })
+	}
+	return nil
+}
+
+func (repo *SubscriptionRepository) ExistsByID(ctx context.Context, id int) (bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	_, ok := repo.get(ctx, id)
+	return ok, nil
+}
+
+func (repo *SubscriptionRepository) FindByID(
+	ctx context.Context,
+	id int,
+) (*entity.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	sub, ok := repo.get(ctx, id)
+	if !ok {
+		return nil, failure.ErrSubscriptionNotFound
+	}
+	return clone(sub), nil
+}
+
+func (repo *SubscriptionRepository) Find(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) ([]*entity.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	subscriptions := repo.filter(ctx, f)
+
+	offset := min((f.Page-1)*f.Limit, len(subscriptions))
+	end := min(offset+f.Limit, len(subscriptions))
+
+	return subscriptions[offset:end], nil
+}
+
+func (repo *SubscriptionRepository) FindAll(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) ([]*entity.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.filter(ctx, f), nil
+}
+
+func (repo *SubscriptionRepository) Total(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) (int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return len(repo.filter(ctx, f)), nil
+}
+
+// FindEnding не ограничивается арендатором: её вызывают фоновые задачи,
+// которые обходят подписки всех арендаторов.
+func (repo *SubscriptionRepository) FindEnding(
+	_ context.Context,
+	from time.Time,
+	to time.Time,
+) ([]*entity.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	monthStart := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	subscriptions := make([]*entity.Subscription, 0)
+	for _, sub := range repo.subscriptions {
+		if sub.EndDate != nil && !sub.EndDate.Before(monthStart) && !sub.EndDate.After(to) {
+			subscriptions = append(subscriptions, clone(sub))
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions, nil
+}
+
+// FindActive, как и FindEnding, обходит подписки всех арендаторов.
+func (repo *SubscriptionRepository) FindActive(
+	_ context.Context,
+	at time.Time,
+) ([]*entity.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	subscriptions := make([]*entity.Subscription, 0)
+	for _, sub := range repo.subscriptions {
+		if !sub.StartDate.After(at) && (sub.EndDate == nil || !sub.EndDate.Before(at)) {
+			subscriptions = append(subscriptions, clone(sub))
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions, nil
+}
+
+// filter повторяет условия SubscriptionRepository.filterHelper из pgx-реализации.
+func (repo *SubscriptionRepository) filter(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) []*entity.Subscription {
+	subscriptions := make([]*entity.Subscription, 0)
+	tenantID := tenant.FromContext(ctx)
+
+	for _, sub := range repo.subscriptions {
+		if sub.TenantID != tenantID {
+			continue
+		}
+
+		if f.ServiceName != "" && sub.ServiceName != f.ServiceName {
+			continue
+		}
+
+		if f.UserID != "" && sub.UserID != strings.ToLower(f.UserID) {
+			continue
+		}
+
+		if f.StartDate != nil && sub.EndDate != nil && sub.EndDate.Before(*f.StartDate) {
+			continue
+		}
+
+		if f.EndDate != nil && sub.StartDate.After(*f.EndDate) {
+			continue
+		}
+
+		subscriptions = append(subscriptions, clone(sub))
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions
+}
+
+// get возвращает подписку, только если она принадлежит арендатору из контекста.
+func (repo *SubscriptionRepository) get(ctx context.Context, id int) (*entity.Subscription, bool) {
+	sub, ok := repo.subscriptions[id]
+	if !ok || sub.TenantID != tenant.FromContext(ctx) {
+		return nil, false
+	}
+	return sub, true
+}
+
+// conflicts проверяет ограничение UNIQUE (tenant_id, service_name, user_id).
+func (repo *SubscriptionRepository) conflicts(sub *entity.Subscription) bool {
+	for _, stored := range repo.subscriptions {
+		if stored.ID != sub.ID &&
+			stored.TenantID == sub.TenantID &&
+			stored.ServiceName == sub.ServiceName &&
+			stored.UserID == sub.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+// restore откатывает изменение подписки id с prev на written, если после него
+// запись не менялась. nil означает отсутствие подписки.
+func (repo *SubscriptionRepository) restore(id int, written, prev *entity.Subscription) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if repo.subscriptions[id] != written {
+		return
+	}
+	if prev == nil {
+		delete(repo.subscriptions, id)
+		return
+	}
+	repo.subscriptions[id] = prev
+}
+
+func normalize(sub *entity.Subscription) *entity.Subscription {
+	stored := clone(sub)
+	stored.UserID = strings.ToLower(stored.UserID)
+	return stored
+}
+
+func clone(sub *entity.Subscription) *entity.Subscription {
+	c := *sub
+	if sub.EndDate != nil {
+		endDate := *sub.EndDate
+		c.EndDate = &endDate
+	}
+	return &c
+}
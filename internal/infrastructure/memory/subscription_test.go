@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+)
+
+func TestSubscriptionRepository(t *testing.T) {
+	contracttest.SubscriptionRepository(t, func(*testing.T) interfaces.SubscriptionRepository {
+		return memory.NewSubscriptionRepository()
+	})
+}
+
+func TestTxManager(t *testing.T) {
+	contracttest.TxManager(t, func(*testing.T) (interfaces.TxManager, interfaces.SubscriptionRepository) {
+		return memory.NewTxManager(), memory.NewSubscriptionRepository()
+	})
+}
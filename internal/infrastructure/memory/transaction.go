@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type txKey struct{}
+
+// tx хранит журнал отмены изменений, сделанных внутри транзакции.
+type tx struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+type TxManager struct {
+	mu sync.Mutex
+}
+
+func NewTxManager() *TxManager {
+	return &TxManager{}
+}
+
+var _ interfaces.TxManager = (*TxManager)(nil)
+
+// WithinTransaction выполняет транзакции последовательно и при ошибке отменяет
+// только изменения, записанные в журнал транзакции. Изменения, сделанные вне
+// транзакции, сохраняются, а выданные идентификаторы, как и последовательности
+// в PostgreSQL, повторно не используются.
+func (manager *TxManager) WithinTransaction(
+	ctx context.Context,
+	fn func(ctx context.Context) error,
+) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	t := &tx{}
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		t.rollback()
+		return err
+	}
+	return nil
+}
+
+func (t *tx) rollback() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+}
+
+// onRollback записывает undo в журнал транзакции из контекста. Вне транзакции
+// изменение сразу окончательное, и undo не сохраняется. undo вызывается без
+// блокировок репозитория и должна захватывать их сама.
+func onRollback(ctx context.Context, undo func()) {
+	t, ok := ctx.Value(txKey{}).(*tx)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.undo = append(t.undo, undo)
+}
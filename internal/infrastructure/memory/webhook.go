@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+type WebhookRepository struct {
+	mu       sync.RWMutex
+	nextID   int
+	webhooks map[int]*entity.Webhook
+	// deliveries удаляет доставки вместе с вебхуком, как ON DELETE CASCADE.
+	deliveries *WebhookDeliveryRepository
+}
+
+func NewWebhookRepository(deliveries *WebhookDeliveryRepository) *WebhookRepository {
+	return &WebhookRepository{
+		nextID:     1,
+		webhooks:   make(map[int]*entity.Webhook),
+		deliveries: deliveries,
+	}
+}
+
+var _ interfaces.WebhookRepository = (*WebhookRepository)(nil)
+
+func (repo *WebhookRepository) Insert(
+	ctx context.Context,
+	webhook *entity.Webhook,
+) (*entity.Webhook, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	webhook.ID = repo.nextID
+	webhook.TenantID = tenant.FromContext(ctx)
+	repo.nextID++
+	repo.webhooks[webhook.ID] = cloneWebhook(webhook)
+
+	return webhook, nil
+}
+
+func (repo *WebhookRepository) FindByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	webhook, ok := repo.webhooks[id]
+	if !ok || webhook.TenantID != tenant.FromContext(ctx) {
+		return nil, failure.ErrWebhookNotFound
+	}
+	return cloneWebhook(webhook), nil
+}
+
+func (repo *WebhookRepository) FindAll(ctx context.Context) ([]*entity.Webhook, error) {
+	return repo.filter(ctx, func(*entity.Webhook) bool { return true }), nil
+}
+
+func (repo *WebhookRepository) FindByEvent(
+	ctx context.Context,
+	event string,
+) ([]*entity.Webhook, error) {
+	return repo.filter(ctx, func(webhook *entity.Webhook) bool {
+		return slices.Contains(webhook.Events, event)
+	}), nil
+}
+
+func (repo *WebhookRepository) Delete(ctx context.Context, id int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	webhook, ok := repo.webhooks[id]
+	if !ok || webhook.TenantID != tenant.FromContext(ctx) {
+		return failure.ErrWebhookNotFound
+	}
+
+	delete(repo.webhooks, id)
+	repo.deliveries.deleteByWebhook(id)
+	return nil
+}
+
+func (repo *WebhookRepository) filter(
+	ctx context.Context,
+	match func(*entity.Webhook) bool,
+) []*entity.Webhook {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	webhooks := make([]*entity.Webhook, 0)
+	for _, webhook := range repo.webhooks {
+		if webhook.TenantID == tenantID && match(webhook) {
+			webhooks = append(webhooks, cloneWebhook(webhook))
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks
+}
+
+func cloneWebhook(webhook *entity.Webhook) *entity.Webhook {
+	c := *webhook
+	c.Events = slices.Clone(webhook.Events)
+	return &c
+}
+
+type WebhookDeliveryRepository struct {
+	mu         sync.Mutex
+	nextID     int
+	deliveries map[int]*entity.WebhookDelivery
+}
+
+func NewWebhookDeliveryRepository() *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		nextID:     1,
+		deliveries: make(map[int]*entity.WebhookDelivery),
+	}
+}
+
+var _ interfaces.WebhookDeliveryRepository = (*WebhookDeliveryRepository)(nil)
+
+func (repo *WebhookDeliveryRepository) Insert(
+	_ context.Context,
+	delivery *entity.WebhookDelivery,
+) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, stored := range repo.deliveries {
+		if stored.WebhookID == delivery.WebhookID && stored.EventID == delivery.EventID {
+			return false, nil
+		}
+	}
+
+	delivery.ID = repo.nextID
+	repo.nextID++
+	repo.deliveries[delivery.ID] = cloneWebhookDelivery(delivery)
+
+	return true, nil
+}
+
+func (repo *WebhookDeliveryRepository) Claim(
+	_ context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	due := make([]*entity.WebhookDelivery, 0)
+	for _, delivery := range repo.deliveries {
+		if delivery.Status == entity.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*entity.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		delivery.NextAttemptAt = leaseUntil
+		claimed = append(claimed, cloneWebhookDelivery(delivery))
+	}
+	return claimed, nil
+}
+
+func (repo *WebhookDeliveryRepository) Update(
+	_ context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.deliveries[delivery.ID]; ok {
+		repo.deliveries[delivery.ID] = cloneWebhookDelivery(delivery)
+	}
+	return nil
+}
+
+func (repo *WebhookDeliveryRepository) FindByWebhook(
+	ctx context.Context,
+	webhookID int,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+
+	deliveries := make([]*entity.WebhookDelivery, 0)
+	for _, delivery := range repo.deliveries {
+		if delivery.WebhookID == webhookID && delivery.TenantID == tenantID {
+			deliveries = append(deliveries, cloneWebhookDelivery(delivery))
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (repo *WebhookDeliveryRepository) deleteByWebhook(webhookID int) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, delivery := range repo.deliveries {
+		if delivery.WebhookID == webhookID {
+			delete(repo.deliveries, id)
+		}
+	}
+}
+
+func cloneWebhookDelivery(delivery *entity.WebhookDelivery) *entity.WebhookDelivery {
+	c := *delivery
+	c.Payload = slices.Clone(delivery.Payload)
+	if delivery.ResponseStatus != nil {
+		status := *delivery.ResponseStatus
+		c.ResponseStatus = &status
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		c.DeliveredAt = &deliveredAt
+	}
+	return &c
+}
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subscriptions"
+
+// Metrics собирает метрики HTTP-запросов, вызовов gRPC и прикладные счётчики в собственном реестре.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	rpcs            *prometheus.CounterVec
+	rpcDuration     *prometheus.HistogramVec
+
+	subscriptionsCreated prometheus.Counter
+	subscriptionsDeleted prometheus.Counter
+	costQueries          *prometheus.CounterVec
+	costSubscriptions    *prometheus.HistogramVec
+	validationFailures   *prometheus.CounterVec
+	webhookDeliveries    *prometheus.CounterVec
+	outboxSends          *prometheus.CounterVec
+	reminders            *prometheus.CounterVec
+	streams              prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		rpcs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of gRPC calls by full method name and status code.",
+		}, []string{"method", "code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "gRPC call latency by full method name and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		subscriptionsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subscriptions_created_total",
+			Help:      "Number of created subscriptions.",
+		}),
+		subscriptionsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subscriptions_deleted_total",
+			Help:      "Number of deleted subscriptions.",
+		}),
+		costQueries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cost_queries_total",
+			Help:      "Number of cost calculations by kind.",
+		}, []string{"kind"}),
+		costSubscriptions: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "cost_query_subscriptions",
+			Help:      "Number of subscriptions read by a cost calculation.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"kind"}),
+		validationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Number of validation failures by field and rule.",
+		}, []string{"field", "tag"}),
+		webhookDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_delivery_attempts_total",
+			Help:      "Number of webhook delivery attempts by event type and outcome.",
+		}, []string{"event", "outcome"}),
+		outboxSends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbox_sends_total",
+			Help:      "Number of outbox messages sent to event sinks by sink and outcome.",
+		}, []string{"sink", "outcome"}),
+		reminders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reminders_total",
+			Help:      "Number of subscription reminders by kind and outcome.",
+		}, []string{"kind", "outcome"}),
+		streams: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stream_clients",
+			Help:      "Number of open subscription change streams.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.rpcs,
+		m.rpcDuration,
+		m.subscriptionsCreated,
+		m.subscriptionsDeleted,
+		m.costQueries,
+		m.costSubscriptions,
+		m.validationFailures,
+		m.webhookDeliveries,
+		m.outboxSends,
+		m.reminders,
+		m.streams,
+	)
+
+	return m
+}
+
+var _ interfaces.Metrics = (*Metrics)(nil)
+
+// Register добавляет сторонний коллектор, например статистику пула соединений.
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveRPC(method, code string, duration time.Duration) {
+	m.rpcs.WithLabelValues(method, code).Inc()
+	m.rpcDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) SubscriptionsCreated(count int) {
+	m.subscriptionsCreated.Add(float64(count))
+}
+
+func (m *Metrics) SubscriptionsDeleted(count int) {
+	m.subscriptionsDeleted.Add(float64(count))
+}
+
+func (m *Metrics) CostQueried(kind string, subscriptions int) {
+	m.costQueries.WithLabelValues(kind).Inc()
+	m.costSubscriptions.WithLabelValues(kind).Observe(float64(subscriptions))
+}
+
+func (m *Metrics) ValidationFailed(field, tag string) {
+	m.validationFailures.WithLabelValues(field, tag).Inc()
+}
+
+func (m *Metrics) WebhookDeliveryAttempted(event, outcome string) {
+	m.webhookDeliveries.WithLabelValues(event, outcome).Inc()
+}
+
+func (m *Metrics) OutboxSent(sink, outcome string) {
+	m.outboxSends.WithLabelValues(sink, outcome).Inc()
+}
+
+func (m *Metrics) ReminderSent(kind, outcome string) {
+	m.reminders.WithLabelValues(kind, outcome).Inc()
+}
+
+func (m *Metrics) StreamOpened() {
+	m.streams.Inc()
+}
+
+func (m *Metrics) StreamClosed() {
+	m.streams.Dec()
+}
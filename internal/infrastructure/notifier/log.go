@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/rs/zerolog"
+)
+
+// LogNotifier записывает напоминания в лог. Используется, пока рассылка
+// не настроена.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+var _ interfaces.Notifier = (*LogNotifier)(nil)
+
+func (notifier *LogNotifier) Notify(ctx context.Context, reminder *entity.Reminder) error {
+	zerolog.Ctx(ctx).Info().
+		Str("kind", reminder.Kind).
+		Str("tenant_id", reminder.Subscription.TenantID).
+		Int("subscription_id", reminder.Subscription.ID).
+		Str("user_id", reminder.Subscription.UserID).
+		Str("service_name", reminder.Subscription.ServiceName).
+		Int("price", reminder.Subscription.Price).
+		Str("due_date", reminder.DueDate.Format(dueDateLayout)).
+		Msg("subscription reminder")
+	return nil
+}
@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+)
+
+const (
+	dueDateLayout      = "2006-01-02"
+	subscriptionLayout = "01-2006"
+)
+
+// reminderMessage — напоминание в формате JSON для вебхука.
+type reminderMessage struct {
+	Type         string              `json:"type"`
+	TenantID     string              `json:"tenant_id"`
+	DueDate      string              `json:"due_date"`
+	SentAt       time.Time           `json:"sent_at"`
+	Subscription subscriptionMessage `json:"subscription"`
+}
+
+type subscriptionMessage struct {
+	ID          int    `json:"id"`
+	ServiceName string `json:"service_name"`
+	Price       int    `json:"price"`
+	UserID      string `json:"user_id"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date,omitempty"`
+}
+
+func newReminderMessage(reminder *entity.Reminder) reminderMessage {
+	sub := reminder.Subscription
+
+	var endDate string
+	if sub.EndDate != nil {
+		endDate = sub.EndDate.Format(subscriptionLayout)
+	}
+
+	return reminderMessage{
+		Type:     "reminder." + reminder.Kind,
+		TenantID: sub.TenantID,
+		DueDate:  reminder.DueDate.Format(dueDateLayout),
+		SentAt:   reminder.SentAt,
+		Subscription: subscriptionMessage{
+			ID:          sub.ID,
+			ServiceName: sub.ServiceName,
+			Price:       sub.Price,
+			UserID:      sub.UserID,
+			StartDate:   sub.StartDate.Format(subscriptionLayout),
+			EndDate:     endDate,
+		},
+	}
+}
+
+// reminderText возвращает тему и текст письма с напоминанием.
+func reminderText(reminder *entity.Reminder) (subject, body string) {
+	sub := reminder.Subscription
+	date := reminder.DueDate.Format("02.01.2006")
+
+	if reminder.Kind == entity.ReminderKindExpiry {
+		subject = fmt.Sprintf("Подписка %s заканчивается", sub.ServiceName)
+		body = fmt.Sprintf(
+			"Подписка %s заканчивается %s. Если она больше не нужна, ничего делать не требуется.",
+			sub.ServiceName, date,
+		)
+		return subject, body
+	}
+
+	subject = fmt.Sprintf("Скоро списание за подписку %s", sub.ServiceName)
+	body = fmt.Sprintf(
+		"%s за подписку %s будет списано %d ₽. Если подписка больше не нужна, отмените её заранее.",
+		date, sub.ServiceName, sub.Price,
+	)
+	return subject, body
+}
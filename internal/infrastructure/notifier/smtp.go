@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// UserIDPlaceholder заменяется в адресе получателя идентификатором пользователя.
+const UserIDPlaceholder = "{user_id}"
+
+// SMTPConfig задаёт SMTP-сервер и адреса писем. To может содержать
+// UserIDPlaceholder, например "{user_id}@users.example.com", если почтовый
+// шлюз сопоставляет идентификаторы пользователей с их адресами.
+type SMTPConfig struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       string
+	Timeout  time.Duration
+}
+
+// SMTPNotifier отправляет напоминания письмами. Если сервер поддерживает
+// STARTTLS, соединение шифруется.
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+var _ interfaces.Notifier = (*SMTPNotifier)(nil)
+
+func (notifier *SMTPNotifier) Notify(ctx context.Context, reminder *entity.Reminder) error {
+	to := strings.ReplaceAll(notifier.cfg.To, UserIDPlaceholder, reminder.Subscription.UserID)
+	subject, text := reminderText(reminder)
+
+	ctx, cancel := context.WithTimeout(ctx, notifier.cfg.Timeout)
+	defer cancel()
+
+	return notifier.send(ctx, to, subject, text)
+}
+
+func (notifier *SMTPNotifier) send(ctx context.Context, to, subject, text string) error {
+	host, _, err := net.SplitHostPort(notifier.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid smtp address: %w", err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", notifier.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if notifier.cfg.Username != "" {
+		auth := smtp.PlainAuth("", notifier.cfg.Username, notifier.cfg.Password, host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(notifier.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(notifier.message(to, subject, text)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (notifier *SMTPNotifier) message(to, subject, text string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", notifier.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(text)
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/pkg/signature"
+)
+
+// WebhookNotifier отправляет напоминания POST-запросом на один адрес,
+// например в сервис рассылок. Запрос подписывается так же, как вебхуки событий.
+type WebhookNotifier struct {
+	url    string
+	secret string
+	sender interfaces.WebhookSender
+}
+
+func NewWebhookNotifier(url, secret string, sender interfaces.WebhookSender) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		sender: sender,
+	}
+}
+
+var _ interfaces.Notifier = (*WebhookNotifier)(nil)
+
+func (notifier *WebhookNotifier) Notify(ctx context.Context, reminder *entity.Reminder) error {
+	message := newReminderMessage(reminder)
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode reminder: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	status, err := notifier.sender.Send(ctx, interfaces.WebhookRequest{
+		URL: notifier.url,
+		Headers: map[string]string{
+			signature.HeaderSignature: signature.Sign(notifier.secret, timestamp, body),
+			signature.HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+			signature.HeaderEvent:     message.Type,
+		},
+		Body: body,
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("unexpected response status %d", status)
+	}
+	return nil
+}
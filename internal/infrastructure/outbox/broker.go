@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// Заголовки сообщения брокера.
+const (
+	HeaderEventID   = "event-id"
+	HeaderEventType = "event-type"
+	HeaderTenantID  = "tenant-id"
+)
+
+// BrokerMessage — сообщение брокера. Subject соответствует subject NATS
+// или топику Kafka, Key - ключу партиционирования Kafka.
+type BrokerMessage struct {
+	Subject string
+	Key     string
+	Headers map[string]string
+	Data    []byte
+}
+
+// Broker — адаптер клиента брокера сообщений (NATS, Kafka). Publish должен
+// возвращаться только после подтверждения записи брокером.
+type Broker interface {
+	Publish(ctx context.Context, message BrokerMessage) error
+}
+
+// BrokerSink отправляет события в брокер: subject события -
+// "<prefix>.<тип события>", ключ - "<арендатор>/<идентификатор подписки>",
+// поэтому события одной подписки попадают в одну партицию.
+type BrokerSink struct {
+	name   string
+	prefix string
+	broker Broker
+}
+
+func NewBrokerSink(name, prefix string, broker Broker) *BrokerSink {
+	return &BrokerSink{
+		name:   name,
+		prefix: prefix,
+		broker: broker,
+	}
+}
+
+var _ interfaces.EventSink = (*BrokerSink)(nil)
+
+func (sink *BrokerSink) Name() string {
+	return sink.name
+}
+
+func (sink *BrokerSink) Send(ctx context.Context, message *entity.OutboxMessage) error {
+	subject := message.Type
+	if sink.prefix != "" {
+		subject = sink.prefix + "." + subject
+	}
+
+	return sink.broker.Publish(ctx, BrokerMessage{
+		Subject: subject,
+		Key:     message.TenantID + "/" + message.AggregateID,
+		Headers: map[string]string{
+			HeaderEventID:   message.ID,
+			HeaderEventType: message.Type,
+			HeaderTenantID:  message.TenantID,
+		},
+		Data: message.Payload,
+	})
+}
@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// WriterSink пишет каждое событие отдельной строкой JSON (JSON Lines).
+type WriterSink struct {
+	mu     sync.Mutex
+	name   string
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewStdoutSink пишет события в стандартный вывод.
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{name: "stdout", w: os.Stdout}
+}
+
+// NewFileSink дописывает события в конец файла path, создавая его при необходимости.
+func NewFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+
+	return &WriterSink{name: "file", w: file, closer: file}, nil
+}
+
+var _ interfaces.EventSink = (*WriterSink)(nil)
+
+func (sink *WriterSink) Name() string {
+	return sink.name
+}
+
+func (sink *WriterSink) Send(_ context.Context, message *entity.OutboxMessage) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	line := make([]byte, 0, len(message.Payload)+1)
+	line = append(line, message.Payload...)
+	line = append(line, '\n')
+
+	_, err := sink.w.Write(line)
+	return err
+}
+
+func (sink *WriterSink) Close() error {
+	if sink.closer == nil {
+		return nil
+	}
+	return sink.closer.Close()
+}
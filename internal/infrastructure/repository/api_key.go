@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) interfaces.APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (repo *APIKeyRepository) Insert(
+	ctx context.Context,
+	key *entity.APIKey,
+) (*entity.APIKey, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Insert("api_keys").
+		Columns("name", "tenant_id", "prefix", "key_hash", "scopes", "created_at").
+		Values(key.Name, key.TenantID, key.Prefix, key.KeyHash, key.Scopes, key.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&key.ID); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (repo *APIKeyRepository) FindByHash(
+	ctx context.Context,
+	hash string,
+) (*entity.APIKey, error) {
+	return repo.findOne(ctx, squirrel.Eq{"key_hash": hash})
+}
+
+func (repo *APIKeyRepository) FindByID(ctx context.Context, id int) (*entity.APIKey, error) {
+	return repo.findOne(ctx, squirrel.Eq{"id": id})
+}
+
+func (repo *APIKeyRepository) FindAll(ctx context.Context) ([]*entity.APIKey, error) {
+	keys := make([]*entity.APIKey, 0)
+
+	query, args, err := repo.getQuery().OrderBy("id").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key entity.APIKey
+		err := rows.Scan(
+			&key.ID,
+			&key.Name,
+			&key.TenantID,
+			&key.Prefix,
+			&key.KeyHash,
+			&key.Scopes,
+			&key.CreatedAt,
+			&key.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke отзывает ключ. Уже отозванный ключ считается ненайденным.
+func (repo *APIKeyRepository) Revoke(ctx context.Context, id int, revokedAt time.Time) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("api_keys").
+		Set("revoked_at", revokedAt).
+		Where(squirrel.Eq{"id": id, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := conn(ctx, repo.db).Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return failure.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (repo *APIKeyRepository) findOne(ctx context.Context, pred squirrel.Sqlizer) (*entity.APIKey, error) {
+	query, args, err := repo.getQuery().
+		Where(pred).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var key entity.APIKey
+	err = conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(
+		&key.ID,
+		&key.Name,
+		&key.TenantID,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (repo *APIKeyRepository) getQuery() squirrel.SelectBuilder {
+	return squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Select("id", "name", "tenant_id", "prefix", "key_hash", "scopes", "created_at", "revoked_at").
+		From("api_keys")
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+type IdempotencyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool) interfaces.IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve занимает ключ. Истёкший ключ и ключ без ответа с истёкшей арендой
+// перезаписываются, остальные — нет.
+func (repo *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+) (bool, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Insert("idempotency_keys").
+		Columns("tenant_id", "key", "request_hash", "token", "locked_until", "expires_at").
+		Values(tenant.FromContext(ctx), record.Key, record.RequestHash, record.Token, record.LockedUntil, record.ExpiresAt).
+		Suffix(`ON CONFLICT (tenant_id, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			token = EXCLUDED.token,
+			status_code = NULL,
+			response_body = NULL,
+			content_type = '',
+			location = '',
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= NOW())
+		RETURNING key`).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var key string
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *IdempotencyRepository) FindByKey(
+	ctx context.Context,
+	key string,
+) (*entity.IdempotencyRecord, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Select(
+			"key",
+			"request_hash",
+			"status_code",
+			"response_body",
+			"content_type",
+			"location",
+			"locked_until",
+			"expires_at",
+		).
+		From("idempotency_keys").
+		Where(byTenant(ctx)).
+		Where(squirrel.Eq{"key": key}).
+		Where("expires_at > NOW()").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var record entity.IdempotencyRecord
+	err = conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ResponseBody,
+		&record.ContentType,
+		&record.Location,
+		&record.LockedUntil,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrIdempotencyKeyNotFound
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (repo *IdempotencyRepository) SaveResponse(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("idempotency_keys").
+		Set("status_code", record.StatusCode).
+		Set("response_body", record.ResponseBody).
+		Set("content_type", record.ContentType).
+		Set("location", record.Location).
+		Where(held(ctx, record.Key, record.Token)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn(ctx, repo.db).Exec(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *IdempotencyRepository) Extend(
+	ctx context.Context,
+	key string,
+	token string,
+	lockedUntil time.Time,
+) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("idempotency_keys").
+		Set("locked_until", lockedUntil).
+		Where(held(ctx, key, token)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn(ctx, repo.db).Exec(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *IdempotencyRepository) Delete(ctx context.Context, key, token string) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Delete("idempotency_keys").
+		Where(held(ctx, key, token)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn(ctx, repo.db).Exec(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteExpired не ограничивается арендатором: её вызывает фоновая задача
+// очистки ключей всех арендаторов.
+func (repo *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Delete("idempotency_keys").
+		Where(squirrel.LtOrEq{"expires_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := conn(ctx, repo.db).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// held выбирает ключ без ответа, занятый запросом с токеном token.
+func held(ctx context.Context, key, token string) squirrel.Sqlizer {
+	return squirrel.And{
+		byTenant(ctx),
+		squirrel.Eq{"key": key, "token": token, "status_code": nil},
+	}
+}
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	"github.com/noredis/subscriptions/internal/infrastructure/repository"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	contracttest.IdempotencyRepository(t, func(t *testing.T) interfaces.IdempotencyRepository {
+		return repository.NewIdempotencyRepository(newTestPool(t))
+	})
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/pkg/postgres"
+	"github.com/rs/zerolog"
+)
+
+// Locker выдаёт advisory-блокировки PostgreSQL, общие для всех экземпляров
+// сервиса, подключённых к одной БД.
+type Locker struct {
+	db *pgxpool.Pool
+}
+
+func NewLocker(db *pgxpool.Pool) interfaces.Locker {
+	return &Locker{db: db}
+}
+
+func (locker *Locker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	unlock, ok, err := postgres.TryAdvisoryLock(ctx, locker.db, postgres.LockKey(name))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return func() {
+		// Блокировку нужно снять и после отмены контекста задачи.
+		if err := unlock(context.WithoutCancel(ctx)); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("lock", name).Msg("failed to release lock")
+		}
+	}, true, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/pkg/postgres"
+)
+
+// outboxChannel — канал NOTIFY о новых сообщениях outbox.
+const outboxChannel = "outbox"
+
+// OutboxNotifier оповещает читателей outbox через LISTEN/NOTIFY, поэтому
+// сигнал получают все экземпляры сервиса, подключённые к одной БД.
+type OutboxNotifier struct {
+	db       *pgxpool.Pool
+	listener *postgres.Listener
+}
+
+func NewOutboxNotifier(db *pgxpool.Pool, listener *postgres.Listener) interfaces.OutboxNotifier {
+	return &OutboxNotifier{db: db, listener: listener}
+}
+
+// NewOutboxListener создаёт Listener канала, в который пишет OutboxNotifier.
+func NewOutboxListener(db *pgxpool.Pool, logger postgres.Logger) *postgres.Listener {
+	return postgres.NewListener(db, outboxChannel, logger)
+}
+
+func (notifier *OutboxNotifier) Notify(ctx context.Context) error {
+	_, err := conn(ctx, notifier.db).Exec(ctx, "SELECT pg_notify($1, '')", outboxChannel)
+	return err
+}
+
+func (notifier *OutboxNotifier) Listen(ctx context.Context) <-chan struct{} {
+	return notifier.listener.Subscribe(ctx)
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) interfaces.OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+var outboxColumns = []string{
+	"seq",
+	"id",
+	"tenant_id",
+	"type",
+	"aggregate_id",
+	"payload",
+	"occurred_at",
+	"attempts",
+	"error",
+	"next_attempt_at",
+	"published_at",
+}
+
+func (repo *OutboxRepository) Insert(ctx context.Context, messages ...*entity.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	qb := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Insert("outbox").
+		Columns("id", "tenant_id", "type", "aggregate_id", "payload", "occurred_at", "next_attempt_at")
+	for _, message := range messages {
+		qb = qb.Values(
+			message.ID,
+			message.TenantID,
+			message.Type,
+			message.AggregateID,
+			message.Payload,
+			message.OccurredAt,
+			message.NextAttemptAt,
+		)
+	}
+
+	query, args, err := qb.Suffix("ON CONFLICT (id) DO NOTHING").ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).Exec(ctx, query, args...)
+	return err
+}
+
+// Claim блокирует выбранные строки через FOR UPDATE SKIP LOCKED, поэтому
+// параллельные экземпляры сервиса получают разные сообщения.
+func (repo *OutboxRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*entity.OutboxMessage, error) {
+	// Вложенный запрос собирается с плейсхолдерами "?", их нумерует внешний запрос.
+	dueSQL, dueArgs, err := squirrel.
+		Select("id").
+		From("outbox").
+		Where(squirrel.Eq{"published_at": nil}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now}).
+		OrderBy("next_attempt_at", "occurred_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("outbox").
+		Set("next_attempt_at", leaseUntil).
+		Where(squirrel.Expr("id IN ("+dueSQL+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(outboxColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *OutboxRepository) Update(ctx context.Context, message *entity.OutboxMessage) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("outbox").
+		Set("attempts", message.Attempts).
+		Set("error", message.Error).
+		Set("next_attempt_at", message.NextAttemptAt).
+		Set("published_at", message.PublishedAt).
+		Where(squirrel.Eq{"id": message.ID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).Exec(ctx, query, args...)
+	return err
+}
+
+func (repo *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Delete("outbox").
+		Where(squirrel.Lt{"published_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := conn(ctx, repo.db).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// FindAfter выдаёт сообщения только из транзакций старше самой старой
+// незавершённой: номера из последовательности выдаются при вставке, а
+// транзакции фиксируются в другом порядке.
+func (repo *OutboxRepository) FindAfter(
+	ctx context.Context,
+	seq int64,
+	types []string,
+	limit int,
+) ([]*entity.OutboxMessage, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Select(outboxColumns...).
+		From("outbox").
+		Where(squirrel.Gt{"seq": seq}).
+		Where(squirrel.Eq{"type": types}).
+		Where(byTenant(ctx)).
+		Where(visibleToAll).
+		OrderBy("seq").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *OutboxRepository) LastSeq(ctx context.Context) (int64, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Select("COALESCE(MAX(seq), 0)").
+		From("outbox").
+		Where(visibleToAll).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var seq int64
+	err = conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&seq)
+	return seq, err
+}
+
+// visibleToAll отбирает сообщения транзакций, завершённых раньше начала
+// самой старой из выполняющихся.
+var visibleToAll = squirrel.Expr("txid < pg_snapshot_xmin(pg_current_snapshot())")
+
+func (repo *OutboxRepository) find(
+	ctx context.Context,
+	query string,
+	args []any,
+) ([]*entity.OutboxMessage, error) {
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*entity.OutboxMessage, 0)
+	for rows.Next() {
+		var message entity.OutboxMessage
+		err := rows.Scan(
+			&message.Seq,
+			&message.ID,
+			&message.TenantID,
+			&message.Type,
+			&message.AggregateID,
+			&message.Payload,
+			&message.OccurredAt,
+			&message.Attempts,
+			&message.Error,
+			&message.NextAttemptAt,
+			&message.PublishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &message)
+	}
+
+	return messages, rows.Err()
+}
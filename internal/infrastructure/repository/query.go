@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/noredis/subscriptions/internal/infrastructure/repository")
+
+// startQuery открывает span для запроса, собранного squirrel, и пишет запрос
+// в логгер запроса на уровне debug.
+func startQuery(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	zerolog.Ctx(ctx).Debug().Str("operation", name).Str("query", query).Msg("executing query")
+	return traceext.StartQuery(ctx, tracer, name, semconv.DBSystemPostgreSQL, query)
+}
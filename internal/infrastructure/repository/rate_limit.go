@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/service"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+// RateLimitStore хранит корзины в PostgreSQL, чтобы лимиты были общими
+// для всех экземпляров сервиса.
+type RateLimitStore struct {
+	db *pgxpool.Pool
+}
+
+func NewRateLimitStore(db *pgxpool.Pool) interfaces.RateLimitStore {
+	return &RateLimitStore{db: db}
+}
+
+func (store *RateLimitStore) Take(
+	ctx context.Context,
+	key string,
+	n int,
+	limit entity.RateLimit,
+) (*entity.RateLimitResult, error) {
+	var result entity.RateLimitResult
+
+	err := pgx.BeginFunc(ctx, store.db, func(tx pgx.Tx) error {
+		builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+		// Пустая строка создаётся заранее, чтобы параллельные запросы
+		// с тем же ключом ждали друг друга на FOR UPDATE.
+		query, args, err := builder.
+			Insert("rate_limit_buckets").
+			Columns("tenant_id", "key", "tokens", "updated_at", "expires_at").
+			Values(tenant.FromContext(ctx), key, 0, nil, time.Now()).
+			Suffix("ON CONFLICT (tenant_id, key) DO NOTHING").
+			ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return err
+		}
+
+		query, args, err = builder.
+			Select("tokens", "updated_at").
+			From("rate_limit_buckets").
+			Where(byTenant(ctx)).
+			Where(squirrel.Eq{"key": key}).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		var (
+			bucket    entity.TokenBucket
+			updatedAt *time.Time
+		)
+		if err := tx.QueryRow(ctx, query, args...).Scan(&bucket.Tokens, &updatedAt); err != nil {
+			return err
+		}
+		if updatedAt != nil {
+			bucket.UpdatedAt = *updatedAt
+		}
+
+		now := time.Now()
+		bucket, result = service.TakeTokens(bucket, limit, n, now)
+
+		query, args, err = builder.
+			Update("rate_limit_buckets").
+			Set("tokens", bucket.Tokens).
+			Set("updated_at", bucket.UpdatedAt).
+			Set("expires_at", now.Add(result.Reset)).
+			Where(byTenant(ctx)).
+			Where(squirrel.Eq{"key": key}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DeleteExpired не ограничивается арендатором: её вызывает фоновая задача
+// очистки корзин всех арендаторов.
+func (store *RateLimitStore) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Delete("rate_limit_buckets").
+		Where(squirrel.LtOrEq{"expires_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := store.db.Exec(tenant.WithAllTenants(ctx), query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	"github.com/noredis/subscriptions/internal/infrastructure/repository"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	contracttest.RateLimitStore(t, func(t *testing.T) interfaces.RateLimitStore {
+		return repository.NewRateLimitStore(newTestPool(t))
+	})
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type ReminderRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReminderRepository(db *pgxpool.Pool) interfaces.ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+func (repo *ReminderRepository) Reserve(ctx context.Context, reminder *entity.Reminder) (bool, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Insert("reminders").
+		Columns("tenant_id", "subscription_id", "kind", "due_date", "sent_at").
+		Values(
+			reminder.Subscription.TenantID,
+			reminder.Subscription.ID,
+			reminder.Kind,
+			reminder.DueDate,
+			reminder.SentAt,
+		).
+		Suffix("ON CONFLICT DO NOTHING RETURNING kind").
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var kind string
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&kind); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *ReminderRepository) Delete(ctx context.Context, reminder *entity.Reminder) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Delete("reminders").
+		Where(squirrel.Eq{
+			"tenant_id":       reminder.Subscription.TenantID,
+			"subscription_id": reminder.Subscription.ID,
+			"kind":            reminder.Kind,
+			"due_date":        reminder.DueDate,
+		}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).Exec(ctx, query, args...)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -11,6 +12,8 @@ import (
 	"github.com/noredis/subscriptions/internal/domain/entity"
 	"github.com/noredis/subscriptions/internal/domain/failure"
 	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/traceext"
 )
 
 type SubscriptionRepository struct {
@@ -24,20 +27,25 @@ func NewSubscriptionRepository(db *pgxpool.Pool) interfaces.SubscriptionReposito
 func (repo *SubscriptionRepository) Insert(
 	ctx context.Context,
 	sub *entity.Subscription,
-) (*entity.Subscription, error) {
+) (_ *entity.Subscription, err error) {
+	tenantID := tenant.FromContext(ctx)
+
 	query, args, err := squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
 		Insert("subscriptions").
-		Columns("service_name", "price", "user_id", "start_date", "end_date").
-		Values(sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate).
+		Columns("tenant_id", "service_name", "price", "user_id", "start_date", "end_date").
+		Values(tenantID, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate).
 		Suffix("RETURNING id").
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Insert", query)
+	defer func() { traceext.End(span, err) }()
+
 	var id int
-	if err := repo.db.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&id); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			return nil, failure.ErrUserAlreadyHasThisSubscription
@@ -47,13 +55,14 @@ func (repo *SubscriptionRepository) Insert(
 	}
 
 	sub.ID = id
+	sub.TenantID = tenantID
 	return sub, nil
 }
 
 func (repo *SubscriptionRepository) Update(
 	ctx context.Context,
 	sub *entity.Subscription,
-) (*entity.Subscription, error) {
+) (_ *entity.Subscription, err error) {
 	query, args, err := squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
 		Update("subscriptions").
@@ -63,12 +72,16 @@ func (repo *SubscriptionRepository) Update(
 		Set("start_date", sub.StartDate).
 		Set("end_date", sub.EndDate).
 		Where(squirrel.Eq{"id": sub.ID}).
+		Where(byTenant(ctx)).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := repo.db.Exec(ctx, query, args...); err != nil {
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Update", query)
+	defer func() { traceext.End(span, err) }()
+
+	if _, err := conn(ctx, repo.db).Exec(ctx, query, args...); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			return nil, failure.ErrUserAlreadyHasThisSubscription
@@ -80,17 +93,21 @@ func (repo *SubscriptionRepository) Update(
 	return sub, nil
 }
 
-func (repo *SubscriptionRepository) Delete(ctx context.Context, id int) error {
+func (repo *SubscriptionRepository) Delete(ctx context.Context, id int) (err error) {
 	query, args, err := squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
 		Delete("subscriptions").
 		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
 		ToSql()
 	if err != nil {
 		return err
 	}
 
-	if _, err := repo.db.Exec(ctx, query, args...); err != nil {
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Delete", query)
+	defer func() { traceext.End(span, err) }()
+
+	if _, err := conn(ctx, repo.db).Exec(ctx, query, args...); err != nil {
 		return err
 	}
 	return nil
@@ -99,20 +116,25 @@ func (repo *SubscriptionRepository) Delete(ctx context.Context, id int) error {
 func (repo *SubscriptionRepository) ExistsByID(
 	ctx context.Context,
 	id int,
-) (bool, error) {
+) (_ bool, err error) {
 	query, args, err := squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
 		Select("1").
 		From("subscriptions").
 		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
 		Limit(1).
+		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
 		return false, err
 	}
 
+	ctx, span := startQuery(ctx, "SubscriptionRepository.ExistsByID", query)
+	defer func() { traceext.End(span, err) }()
+
 	var dummy int
-	if err := repo.db.QueryRow(ctx, query, args...).Scan(&dummy); err != nil {
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&dummy); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, nil
 		}
@@ -124,18 +146,29 @@ func (repo *SubscriptionRepository) ExistsByID(
 func (repo *SubscriptionRepository) FindByID(
 	ctx context.Context,
 	id int,
-) (*entity.Subscription, error) {
+) (_ *entity.Subscription, err error) {
 	query, args, err := repo.getQuery().
 		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
 		Limit(1).
 		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, span := startQuery(ctx, "SubscriptionRepository.FindByID", query)
+	defer func() { traceext.End(span, err) }()
+
 	var sub entity.Subscription
-	err = repo.db.QueryRow(ctx, query, args...).
-		Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate)
+	err = conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(
+		&sub.ID,
+		&sub.TenantID,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, failure.ErrSubscriptionNotFound
@@ -148,11 +181,11 @@ func (repo *SubscriptionRepository) FindByID(
 func (repo *SubscriptionRepository) Find(
 	ctx context.Context,
 	f *entity.SubscriptionFilter,
-) ([]*entity.Subscription, error) {
+) (_ []*entity.Subscription, err error) {
 	subscriptions := make([]*entity.Subscription, 0)
 
 	qb := repo.getQuery()
-	qb = repo.filterHelper(qb, f)
+	qb = repo.filterHelper(ctx, qb, f).OrderBy("id")
 
 	offset := (f.Page - 1) * f.Limit
 	qb = qb.Limit(uint64(f.Limit)).Offset(uint64(offset))
@@ -162,7 +195,10 @@ func (repo *SubscriptionRepository) Find(
 		return nil, err
 	}
 
-	rows, err := repo.db.Query(ctx, query, args...)
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Find", query)
+	defer func() { traceext.End(span, err) }()
+
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -170,8 +206,15 @@ func (repo *SubscriptionRepository) Find(
 
 	for rows.Next() {
 		var sub entity.Subscription
-		err := rows.
-			Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate)
+		err := rows.Scan(
+			&sub.ID,
+			&sub.TenantID,
+			&sub.ServiceName,
+			&sub.Price,
+			&sub.UserID,
+			&sub.StartDate,
+			&sub.EndDate,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -184,18 +227,21 @@ func (repo *SubscriptionRepository) Find(
 func (repo *SubscriptionRepository) FindAll(
 	ctx context.Context,
 	f *entity.SubscriptionFilter,
-) ([]*entity.Subscription, error) {
+) (_ []*entity.Subscription, err error) {
 	subscriptions := make([]*entity.Subscription, 0)
 
 	qb := repo.getQuery()
-	qb = repo.filterHelper(qb, f)
+	qb = repo.filterHelper(ctx, qb, f).OrderBy("id")
 
 	query, args, err := qb.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := repo.db.Query(ctx, query, args...)
+	ctx, span := startQuery(ctx, "SubscriptionRepository.FindAll", query)
+	defer func() { traceext.End(span, err) }()
+
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -203,8 +249,15 @@ func (repo *SubscriptionRepository) FindAll(
 
 	for rows.Next() {
 		var sub entity.Subscription
-		err := rows.
-			Scan(&sub.ID, &sub.ServiceName, &sub.Price, &sub.UserID, &sub.StartDate, &sub.EndDate)
+		err := rows.Scan(
+			&sub.ID,
+			&sub.TenantID,
+			&sub.ServiceName,
+			&sub.Price,
+			&sub.UserID,
+			&sub.StartDate,
+			&sub.EndDate,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -217,29 +270,107 @@ func (repo *SubscriptionRepository) FindAll(
 func (repo *SubscriptionRepository) Total(
 	ctx context.Context,
 	f *entity.SubscriptionFilter,
-) (int, error) {
+) (_ int, err error) {
 	cb := squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
 		Select("COUNT(*)").
 		From("subscriptions")
-	cb = repo.filterHelper(cb, f)
+	cb = repo.filterHelper(ctx, cb, f)
 
 	query, args, err := cb.ToSql()
 	if err != nil {
 		return 0, err
 	}
 
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Total", query)
+	defer func() { traceext.End(span, err) }()
+
 	var total int
-	if err := repo.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&total); err != nil {
 		return 0, err
 	}
 	return total, err
 }
 
+// FindEnding не ограничивается арендатором: её вызывают фоновые задачи,
+// которые обходят подписки всех арендаторов.
+func (repo *SubscriptionRepository) FindEnding(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]*entity.Subscription, error) {
+	qb := repo.getQuery().
+		Where("end_date >= date_trunc('month', ?::date)", from).
+		Where(squirrel.LtOrEq{"end_date": to}).
+		OrderBy("id")
+
+	return repo.query(ctx, "SubscriptionRepository.FindEnding", qb)
+}
+
+// FindActive, как и FindEnding, обходит подписки всех арендаторов.
+func (repo *SubscriptionRepository) FindActive(
+	ctx context.Context,
+	at time.Time,
+) ([]*entity.Subscription, error) {
+	qb := repo.getQuery().
+		Where(squirrel.LtOrEq{"start_date": at}).
+		Where(squirrel.Or{
+			squirrel.Eq{"end_date": nil},
+			squirrel.GtOrEq{"end_date": at},
+		}).
+		OrderBy("id")
+
+	return repo.query(ctx, "SubscriptionRepository.FindActive", qb)
+}
+
+func (repo *SubscriptionRepository) query(
+	ctx context.Context,
+	name string,
+	qb squirrel.SelectBuilder,
+) (_ []*entity.Subscription, err error) {
+	subscriptions := make([]*entity.Subscription, 0)
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := startQuery(ctx, name, query)
+	defer func() { traceext.End(span, err) }()
+
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub entity.Subscription
+		err := rows.Scan(
+			&sub.ID,
+			&sub.TenantID,
+			&sub.ServiceName,
+			&sub.Price,
+			&sub.UserID,
+			&sub.StartDate,
+			&sub.EndDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+
+	return subscriptions, rows.Err()
+}
+
 func (repo *SubscriptionRepository) filterHelper(
+	ctx context.Context,
 	sb squirrel.SelectBuilder,
 	f *entity.SubscriptionFilter,
 ) squirrel.SelectBuilder {
+	sb = sb.Where(byTenant(ctx))
+
 	if f.ServiceName != "" {
 		sb = sb.Where(squirrel.Eq{"service_name": f.ServiceName})
 	}
@@ -267,6 +398,11 @@ func (repo *SubscriptionRepository) filterHelper(
 func (repo *SubscriptionRepository) getQuery() squirrel.SelectBuilder {
 	return squirrel.StatementBuilder.
 		PlaceholderFormat(squirrel.Dollar).
-		Select("id", "service_name", "price", "user_id", "start_date", "end_date").
+		Select("id", "tenant_id", "service_name", "price", "user_id", "start_date", "end_date").
 		From("subscriptions")
 }
+
+// byTenant ограничивает запрос арендатором из контекста.
+func byTenant(ctx context.Context) squirrel.Eq {
+	return squirrel.Eq{"tenant_id": tenant.FromContext(ctx)}
+}
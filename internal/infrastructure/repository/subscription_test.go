@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	"github.com/noredis/subscriptions/internal/infrastructure/repository"
+	"github.com/noredis/subscriptions/migrations"
+	"github.com/noredis/subscriptions/pkg/migrate"
+)
+
+func TestSubscriptionRepository(t *testing.T) {
+	contracttest.SubscriptionRepository(t, func(t *testing.T) interfaces.SubscriptionRepository {
+		return repository.NewSubscriptionRepository(newTestPool(t))
+	})
+}
+
+func TestTxManager(t *testing.T) {
+	contracttest.TxManager(t, func(t *testing.T) (interfaces.TxManager, interfaces.SubscriptionRepository) {
+		db := newTestPool(t)
+		return repository.NewTxManager(db), repository.NewSubscriptionRepository(db)
+	})
+}
+
+// newTestPool подключается к TEST_DATABASE_URL, применяет миграции и очищает
+// таблицы. Без переменной тест пропускается. База должна быть отдельной:
+// её данные удаляются.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	db, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := migrate.New(migrate.NewPostgresDriver(db), migrations.Postgres(), log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err := db.Exec(ctx, "TRUNCATE subscriptions, rate_limit_buckets, idempotency_keys CASCADE"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) interfaces.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (repo *WebhookRepository) Insert(
+	ctx context.Context,
+	webhook *entity.Webhook,
+) (*entity.Webhook, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Insert("webhooks").
+		Columns("tenant_id", "url", "secret", "events", "created_at").
+		Values(tenantID, webhook.URL, webhook.Secret, webhook.Events, webhook.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&webhook.ID); err != nil {
+		return nil, err
+	}
+
+	webhook.TenantID = tenantID
+	return webhook, nil
+}
+
+func (repo *WebhookRepository) FindByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	query, args, err := repo.getQuery().
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook entity.Webhook
+	err = conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(
+		&webhook.ID,
+		&webhook.TenantID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (repo *WebhookRepository) FindAll(ctx context.Context) ([]*entity.Webhook, error) {
+	return repo.find(ctx, repo.getQuery().Where(byTenant(ctx)))
+}
+
+func (repo *WebhookRepository) FindByEvent(
+	ctx context.Context,
+	event string,
+) ([]*entity.Webhook, error) {
+	return repo.find(ctx, repo.getQuery().
+		Where(byTenant(ctx)).
+		Where(squirrel.Expr("? = ANY(events)", event)),
+	)
+}
+
+func (repo *WebhookRepository) Delete(ctx context.Context, id int) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Delete("webhooks").
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := conn(ctx, repo.db).Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return failure.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) find(
+	ctx context.Context,
+	qb squirrel.SelectBuilder,
+) ([]*entity.Webhook, error) {
+	webhooks := make([]*entity.Webhook, 0)
+
+	query, args, err := qb.OrderBy("id").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webhook entity.Webhook
+		err := rows.Scan(
+			&webhook.ID,
+			&webhook.TenantID,
+			&webhook.URL,
+			&webhook.Secret,
+			&webhook.Events,
+			&webhook.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (repo *WebhookRepository) getQuery() squirrel.SelectBuilder {
+	return squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Select("id", "tenant_id", "url", "secret", "events", "created_at").
+		From("webhooks")
+}
+
+type WebhookDeliveryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookDeliveryRepository(db *pgxpool.Pool) interfaces.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+var webhookDeliveryColumns = []string{
+	"id",
+	"webhook_id",
+	"tenant_id",
+	"event_id",
+	"event_type",
+	"payload",
+	"status",
+	"attempts",
+	"response_status",
+	"error",
+	"created_at",
+	"next_attempt_at",
+	"delivered_at",
+}
+
+func (repo *WebhookDeliveryRepository) Insert(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) (bool, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Insert("webhook_deliveries").
+		Columns(
+			"webhook_id", "tenant_id", "event_id", "event_type", "payload",
+			"status", "created_at", "next_attempt_at",
+		).
+		Values(
+			delivery.WebhookID, delivery.TenantID, delivery.EventID, delivery.EventType, delivery.Payload,
+			delivery.Status, delivery.CreatedAt, delivery.NextAttemptAt,
+		).
+		Suffix("ON CONFLICT (webhook_id, event_id) DO NOTHING RETURNING id").
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	if err := conn(ctx, repo.db).QueryRow(ctx, query, args...).Scan(&delivery.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Claim блокирует выбранные строки через FOR UPDATE SKIP LOCKED, поэтому
+// параллельные экземпляры сервиса получают разные доставки.
+func (repo *WebhookDeliveryRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	// Вложенный запрос собирается с плейсхолдерами "?", их нумерует внешний запрос.
+	due := squirrel.
+		Select("id").
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"status": entity.DeliveryStatusPending}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now}).
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	dueSQL, dueArgs, err := due.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("webhook_deliveries").
+		Set("next_attempt_at", leaseUntil).
+		Where(squirrel.Expr("id IN ("+dueSQL+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(webhookDeliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *WebhookDeliveryRepository) Update(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Update("webhook_deliveries").
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("response_status", delivery.ResponseStatus).
+		Set("error", delivery.Error).
+		Set("next_attempt_at", delivery.NextAttemptAt).
+		Set("delivered_at", delivery.DeliveredAt).
+		Where(squirrel.Eq{"id": delivery.ID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).Exec(ctx, query, args...)
+	return err
+}
+
+func (repo *WebhookDeliveryRepository) FindByWebhook(
+	ctx context.Context,
+	webhookID int,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	query, args, err := squirrel.StatementBuilder.
+		PlaceholderFormat(squirrel.Dollar).
+		Select(webhookDeliveryColumns...).
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"webhook_id": webhookID}).
+		Where(byTenant(ctx)).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *WebhookDeliveryRepository) find(
+	ctx context.Context,
+	query string,
+	args []any,
+) ([]*entity.WebhookDelivery, error) {
+	deliveries := make([]*entity.WebhookDelivery, 0)
+
+	rows, err := conn(ctx, repo.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var delivery entity.WebhookDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.TenantID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.ResponseStatus,
+			&delivery.Error,
+			&delivery.CreatedAt,
+			&delivery.NextAttemptAt,
+			&delivery.DeliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, rows.Err()
+}
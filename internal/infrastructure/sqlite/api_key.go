@@ -0,0 +1,167 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) interfaces.APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (repo *APIKeyRepository) Insert(
+	ctx context.Context,
+	key *entity.APIKey,
+) (*entity.APIKey, error) {
+	query, args, err := squirrel.
+		Insert("api_keys").
+		Columns("name", "tenant_id", "prefix", "key_hash", "scopes", "created_at").
+		Values(
+			key.Name,
+			key.TenantID,
+			key.Prefix,
+			key.KeyHash,
+			strings.Join(key.Scopes, " "),
+			key.CreatedAt.UnixMilli(),
+		).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&key.ID); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (repo *APIKeyRepository) FindByHash(
+	ctx context.Context,
+	hash string,
+) (*entity.APIKey, error) {
+	return repo.findOne(ctx, squirrel.Eq{"key_hash": hash})
+}
+
+func (repo *APIKeyRepository) FindByID(ctx context.Context, id int) (*entity.APIKey, error) {
+	return repo.findOne(ctx, squirrel.Eq{"id": id})
+}
+
+func (repo *APIKeyRepository) FindAll(ctx context.Context) ([]*entity.APIKey, error) {
+	keys := make([]*entity.APIKey, 0)
+
+	query, args, err := repo.getQuery().OrderBy("id").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (repo *APIKeyRepository) Revoke(ctx context.Context, id int, revokedAt time.Time) error {
+	query, args, err := squirrel.
+		Update("api_keys").
+		Set("revoked_at", revokedAt.UnixMilli()).
+		Where(squirrel.Eq{"id": id, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return failure.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (repo *APIKeyRepository) findOne(ctx context.Context, pred squirrel.Sqlizer) (*entity.APIKey, error) {
+	query, args, err := repo.getQuery().
+		Where(pred).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scanAPIKey(conn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (repo *APIKeyRepository) getQuery() squirrel.SelectBuilder {
+	return squirrel.
+		Select("id", "name", "tenant_id", "prefix", "key_hash", "scopes", "created_at", "revoked_at").
+		From("api_keys")
+}
+
+func scanAPIKey(row scanner) (*entity.APIKey, error) {
+	var (
+		key       entity.APIKey
+		scopes    string
+		createdAt int64
+		revokedAt sql.NullInt64
+	)
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.TenantID,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&createdAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	key.CreatedAt = time.UnixMilli(createdAt)
+	if revokedAt.Valid {
+		t := time.UnixMilli(revokedAt.Int64)
+		key.RevokedAt = &t
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,201 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) interfaces.IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (repo *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+) (bool, error) {
+	now := time.Now().UnixMilli()
+	query, args, err := squirrel.
+		Insert("idempotency_keys").
+		Columns("tenant_id", "key", "request_hash", "token", "locked_until", "expires_at").
+		Values(tenant.FromContext(ctx), record.Key, record.RequestHash, record.Token, record.LockedUntil.UnixMilli(), record.ExpiresAt.UnixMilli()).
+		Suffix(`ON CONFLICT (tenant_id, key) DO UPDATE SET
+			request_hash = excluded.request_hash,
+			token = excluded.token,
+			status_code = NULL,
+			response_body = NULL,
+			content_type = '',
+			location = '',
+			locked_until = excluded.locked_until,
+			expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= ?
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= ?)
+		RETURNING key`, now, now).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var key string
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *IdempotencyRepository) FindByKey(
+	ctx context.Context,
+	key string,
+) (*entity.IdempotencyRecord, error) {
+	query, args, err := squirrel.
+		Select(
+			"key",
+			"request_hash",
+			"status_code",
+			"response_body",
+			"content_type",
+			"location",
+			"locked_until",
+			"expires_at",
+		).
+		From("idempotency_keys").
+		Where(byTenant(ctx)).
+		Where(squirrel.Eq{"key": key}).
+		Where(squirrel.Gt{"expires_at": time.Now().UnixMilli()}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		record      entity.IdempotencyRecord
+		statusCode  sql.NullInt64
+		lockedUntil int64
+		expiresAt   int64
+	)
+	err = conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&statusCode,
+		&record.ResponseBody,
+		&record.ContentType,
+		&record.Location,
+		&lockedUntil,
+		&expiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrIdempotencyKeyNotFound
+		}
+		return nil, err
+	}
+
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		record.StatusCode = &code
+	}
+	record.LockedUntil = time.UnixMilli(lockedUntil)
+	record.ExpiresAt = time.UnixMilli(expiresAt)
+
+	return &record, nil
+}
+
+func (repo *IdempotencyRepository) SaveResponse(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+) error {
+	query, args, err := squirrel.
+		Update("idempotency_keys").
+		Set("status_code", record.StatusCode).
+		Set("response_body", record.ResponseBody).
+		Set("content_type", record.ContentType).
+		Set("location", record.Location).
+		Where(held(ctx, record.Key, record.Token)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *IdempotencyRepository) Extend(
+	ctx context.Context,
+	key string,
+	token string,
+	lockedUntil time.Time,
+) error {
+	query, args, err := squirrel.
+		Update("idempotency_keys").
+		Set("locked_until", lockedUntil.UnixMilli()).
+		Where(held(ctx, key, token)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *IdempotencyRepository) Delete(ctx context.Context, key, token string) error {
+	query, args, err := squirrel.
+		Delete("idempotency_keys").
+		Where(held(ctx, key, token)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteExpired не ограничивается арендатором: её вызывает фоновая задача
+// очистки ключей всех арендаторов.
+func (repo *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args, err := squirrel.
+		Delete("idempotency_keys").
+		Where(squirrel.LtOrEq{"expires_at": before.UnixMilli()}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// held выбирает ключ без ответа, занятый запросом с токеном token.
+func held(ctx context.Context, key, token string) squirrel.Sqlizer {
+	return squirrel.And{
+		byTenant(ctx),
+		squirrel.Eq{"key": key, "token": token, "status_code": nil},
+	}
+}
@@ -0,0 +1,15 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	sqliterepo "github.com/noredis/subscriptions/internal/infrastructure/sqlite"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	contracttest.IdempotencyRepository(t, func(t *testing.T) interfaces.IdempotencyRepository {
+		return sqliterepo.NewIdempotencyRepository(newTestDB(t))
+	})
+}
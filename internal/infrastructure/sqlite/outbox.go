@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) interfaces.OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+var outboxColumns = []string{
+	"seq",
+	"id",
+	"tenant_id",
+	"type",
+	"aggregate_id",
+	"payload",
+	"occurred_at",
+	"attempts",
+	"error",
+	"next_attempt_at",
+	"published_at",
+}
+
+func (repo *OutboxRepository) Insert(ctx context.Context, messages ...*entity.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	qb := squirrel.
+		Insert("outbox").
+		Columns("id", "tenant_id", "type", "aggregate_id", "payload", "occurred_at", "next_attempt_at")
+	for _, message := range messages {
+		qb = qb.Values(
+			message.ID,
+			message.TenantID,
+			message.Type,
+			message.AggregateID,
+			message.Payload,
+			message.OccurredAt.UnixMilli(),
+			message.NextAttemptAt.UnixMilli(),
+		)
+	}
+
+	query, args, err := qb.Suffix("ON CONFLICT (id) DO NOTHING").ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// Claim выполняется одним запросом UPDATE ... RETURNING. SQLite допускает
+// одного писателя, поэтому дополнительная блокировка строк не нужна.
+func (repo *OutboxRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*entity.OutboxMessage, error) {
+	dueSQL, dueArgs, err := squirrel.
+		Select("id").
+		From("outbox").
+		Where(squirrel.Eq{"published_at": nil}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now.UnixMilli()}).
+		OrderBy("next_attempt_at", "occurred_at").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.
+		Update("outbox").
+		Set("next_attempt_at", leaseUntil.UnixMilli()).
+		Where(squirrel.Expr("id IN ("+dueSQL+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(outboxColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *OutboxRepository) Update(ctx context.Context, message *entity.OutboxMessage) error {
+	var publishedAt sql.NullInt64
+	if message.PublishedAt != nil {
+		publishedAt = sql.NullInt64{Int64: message.PublishedAt.UnixMilli(), Valid: true}
+	}
+
+	query, args, err := squirrel.
+		Update("outbox").
+		Set("attempts", message.Attempts).
+		Set("error", message.Error).
+		Set("next_attempt_at", message.NextAttemptAt.UnixMilli()).
+		Set("published_at", publishedAt).
+		Where(squirrel.Eq{"id": message.ID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (repo *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	query, args, err := squirrel.
+		Delete("outbox").
+		Where(squirrel.Lt{"published_at": before.UnixMilli()}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// FindAfter читает сообщения по номеру без дополнительных условий: SQLite
+// допускает одного писателя, поэтому номера фиксируются по возрастанию.
+func (repo *OutboxRepository) FindAfter(
+	ctx context.Context,
+	seq int64,
+	types []string,
+	limit int,
+) ([]*entity.OutboxMessage, error) {
+	query, args, err := squirrel.
+		Select(outboxColumns...).
+		From("outbox").
+		Where(squirrel.Gt{"seq": seq}).
+		Where(squirrel.Eq{"type": types}).
+		Where(byTenant(ctx)).
+		OrderBy("seq").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *OutboxRepository) LastSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := conn(ctx, repo.db).
+		QueryRowContext(ctx, "SELECT COALESCE(MAX(seq), 0) FROM outbox").
+		Scan(&seq)
+	return seq, err
+}
+
+func (repo *OutboxRepository) find(
+	ctx context.Context,
+	query string,
+	args []any,
+) ([]*entity.OutboxMessage, error) {
+	rows, err := conn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*entity.OutboxMessage, 0)
+	for rows.Next() {
+		var (
+			message       entity.OutboxMessage
+			occurredAt    int64
+			nextAttemptAt int64
+			publishedAt   sql.NullInt64
+		)
+
+		err := rows.Scan(
+			&message.Seq,
+			&message.ID,
+			&message.TenantID,
+			&message.Type,
+			&message.AggregateID,
+			&message.Payload,
+			&occurredAt,
+			&message.Attempts,
+			&message.Error,
+			&nextAttemptAt,
+			&publishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		message.OccurredAt = time.UnixMilli(occurredAt)
+		message.NextAttemptAt = time.UnixMilli(nextAttemptAt)
+		if publishedAt.Valid {
+			t := time.UnixMilli(publishedAt.Int64)
+			message.PublishedAt = &t
+		}
+		messages = append(messages, &message)
+	}
+
+	return messages, rows.Err()
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+type ReminderRepository struct {
+	db *sql.DB
+}
+
+func NewReminderRepository(db *sql.DB) interfaces.ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+func (repo *ReminderRepository) Reserve(ctx context.Context, reminder *entity.Reminder) (bool, error) {
+	query, args, err := squirrel.
+		Insert("reminders").
+		Columns("tenant_id", "subscription_id", "kind", "due_date", "sent_at").
+		Values(
+			reminder.Subscription.TenantID,
+			reminder.Subscription.ID,
+			reminder.Kind,
+			formatDate(reminder.DueDate),
+			reminder.SentAt.UnixMilli(),
+		).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (repo *ReminderRepository) Delete(ctx context.Context, reminder *entity.Reminder) error {
+	query, args, err := squirrel.
+		Delete("reminders").
+		Where(squirrel.Eq{
+			"tenant_id":       reminder.Subscription.TenantID,
+			"subscription_id": reminder.Subscription.ID,
+			"kind":            reminder.Kind,
+			"due_date":        formatDate(reminder.DueDate),
+		}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	return err
+}
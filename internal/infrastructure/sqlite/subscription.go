@@ -0,0 +1,382 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/traceext"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const dateLayout = "2006-01-02"
+
+type SubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) interfaces.SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+func (repo *SubscriptionRepository) Insert(
+	ctx context.Context,
+	sub *entity.Subscription,
+) (_ *entity.Subscription, err error) {
+	tenantID := tenant.FromContext(ctx)
+
+	query, args, err := squirrel.
+		Insert("subscriptions").
+		Columns("tenant_id", "service_name", "price", "user_id", "start_date", "end_date").
+		Values(
+			tenantID,
+			sub.ServiceName,
+			sub.Price,
+			strings.ToLower(sub.UserID),
+			formatDate(sub.StartDate),
+			formatNullDate(sub.EndDate),
+		).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Insert", query)
+	defer func() { traceext.End(span, err) }()
+
+	var id int
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return nil, failure.ErrUserAlreadyHasThisSubscription
+		}
+
+		return nil, err
+	}
+
+	sub.ID = id
+	sub.TenantID = tenantID
+	return sub, nil
+}
+
+func (repo *SubscriptionRepository) Update(
+	ctx context.Context,
+	sub *entity.Subscription,
+) (_ *entity.Subscription, err error) {
+	query, args, err := squirrel.
+		Update("subscriptions").
+		Set("service_name", sub.ServiceName).
+		Set("price", sub.Price).
+		Set("user_id", strings.ToLower(sub.UserID)).
+		Set("start_date", formatDate(sub.StartDate)).
+		Set("end_date", formatNullDate(sub.EndDate)).
+		Where(squirrel.Eq{"id": sub.ID}).
+		Where(byTenant(ctx)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Update", query)
+	defer func() { traceext.End(span, err) }()
+
+	if _, err := conn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			return nil, failure.ErrUserAlreadyHasThisSubscription
+		}
+
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (repo *SubscriptionRepository) Delete(ctx context.Context, id int) (err error) {
+	query, args, err := squirrel.
+		Delete("subscriptions").
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Delete", query)
+	defer func() { traceext.End(span, err) }()
+
+	if _, err := conn(ctx, repo.db).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *SubscriptionRepository) ExistsByID(
+	ctx context.Context,
+	id int,
+) (_ bool, err error) {
+	query, args, err := squirrel.
+		Select("1").
+		From("subscriptions").
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	ctx, span := startQuery(ctx, "SubscriptionRepository.ExistsByID", query)
+	defer func() { traceext.End(span, err) }()
+
+	var dummy int
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&dummy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *SubscriptionRepository) FindByID(
+	ctx context.Context,
+	id int,
+) (_ *entity.Subscription, err error) {
+	query, args, err := repo.getQuery().
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := startQuery(ctx, "SubscriptionRepository.FindByID", query)
+	defer func() { traceext.End(span, err) }()
+
+	sub, err := scanSubscription(conn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (repo *SubscriptionRepository) Find(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) ([]*entity.Subscription, error) {
+	qb := repo.getQuery()
+	qb = repo.filterHelper(ctx, qb, f).OrderBy("id")
+
+	offset := (f.Page - 1) * f.Limit
+	qb = qb.Limit(uint64(f.Limit)).Offset(uint64(offset))
+
+	return repo.query(ctx, "SubscriptionRepository.Find", qb)
+}
+
+func (repo *SubscriptionRepository) FindAll(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) ([]*entity.Subscription, error) {
+	qb := repo.getQuery()
+	qb = repo.filterHelper(ctx, qb, f).OrderBy("id")
+
+	return repo.query(ctx, "SubscriptionRepository.FindAll", qb)
+}
+
+func (repo *SubscriptionRepository) Total(
+	ctx context.Context,
+	f *entity.SubscriptionFilter,
+) (_ int, err error) {
+	cb := squirrel.
+		Select("COUNT(*)").
+		From("subscriptions")
+	cb = repo.filterHelper(ctx, cb, f)
+
+	query, args, err := cb.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, span := startQuery(ctx, "SubscriptionRepository.Total", query)
+	defer func() { traceext.End(span, err) }()
+
+	var total int
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, err
+}
+
+// FindEnding не ограничивается арендатором: её вызывают фоновые задачи,
+// которые обходят подписки всех арендаторов.
+func (repo *SubscriptionRepository) FindEnding(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]*entity.Subscription, error) {
+	qb := repo.getQuery().
+		Where("end_date >= date(?, 'start of month')", formatDate(from)).
+		Where(squirrel.LtOrEq{"end_date": formatDate(to)}).
+		OrderBy("id")
+
+	return repo.query(ctx, "SubscriptionRepository.FindEnding", qb)
+}
+
+// FindActive, как и FindEnding, обходит подписки всех арендаторов.
+func (repo *SubscriptionRepository) FindActive(
+	ctx context.Context,
+	at time.Time,
+) ([]*entity.Subscription, error) {
+	qb := repo.getQuery().
+		Where(squirrel.LtOrEq{"start_date": formatDate(at)}).
+		Where(squirrel.Or{
+			squirrel.Eq{"end_date": nil},
+			squirrel.GtOrEq{"end_date": formatDate(at)},
+		}).
+		OrderBy("id")
+
+	return repo.query(ctx, "SubscriptionRepository.FindActive", qb)
+}
+
+func (repo *SubscriptionRepository) query(
+	ctx context.Context,
+	name string,
+	qb squirrel.SelectBuilder,
+) (_ []*entity.Subscription, err error) {
+	subscriptions := make([]*entity.Subscription, 0)
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := startQuery(ctx, name, query)
+	defer func() { traceext.End(span, err) }()
+
+	rows, err := conn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (repo *SubscriptionRepository) filterHelper(
+	ctx context.Context,
+	sb squirrel.SelectBuilder,
+	f *entity.SubscriptionFilter,
+) squirrel.SelectBuilder {
+	sb = sb.Where(byTenant(ctx))
+
+	if f.ServiceName != "" {
+		sb = sb.Where(squirrel.Eq{"service_name": f.ServiceName})
+	}
+
+	if f.UserID != "" {
+		sb = sb.Where(squirrel.Eq{"user_id": strings.ToLower(f.UserID)})
+	}
+
+	if f.StartDate != nil {
+		sb = sb.Where(
+			squirrel.Or{
+				squirrel.GtOrEq{"end_date": formatDate(*f.StartDate)},
+				squirrel.Expr("end_date IS NULL"),
+			},
+		)
+	}
+
+	if f.EndDate != nil {
+		sb = sb.Where(squirrel.LtOrEq{"start_date": formatDate(*f.EndDate)})
+	}
+
+	return sb
+}
+
+func (repo *SubscriptionRepository) getQuery() squirrel.SelectBuilder {
+	return squirrel.
+		Select("id", "tenant_id", "service_name", "price", "user_id", "start_date", "end_date").
+		From("subscriptions")
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row scanner) (*entity.Subscription, error) {
+	var (
+		sub       entity.Subscription
+		startDate string
+		endDate   sql.NullString
+	)
+
+	err := row.Scan(
+		&sub.ID,
+		&sub.TenantID,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.UserID,
+		&startDate,
+		&endDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.StartDate, err = time.Parse(dateLayout, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if endDate.Valid {
+		date, err := time.Parse(dateLayout, endDate.String)
+		if err != nil {
+			return nil, err
+		}
+
+		sub.EndDate = &date
+	}
+
+	return &sub, nil
+}
+
+func formatDate(date time.Time) string {
+	return date.Format(dateLayout)
+}
+
+func formatNullDate(date *time.Time) sql.NullString {
+	if date == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatDate(*date), Valid: true}
+}
+
+// byTenant ограничивает запрос арендатором из контекста.
+func byTenant(ctx context.Context) squirrel.Eq {
+	return squirrel.Eq{"tenant_id": tenant.FromContext(ctx)}
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
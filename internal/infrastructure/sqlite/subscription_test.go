@@ -0,0 +1,48 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/infrastructure/contracttest"
+	sqliterepo "github.com/noredis/subscriptions/internal/infrastructure/sqlite"
+	"github.com/noredis/subscriptions/migrations"
+	"github.com/noredis/subscriptions/pkg/migrate"
+	"github.com/noredis/subscriptions/pkg/sqlite"
+)
+
+// TxManager SQLite не проверяется общим контрактом: единственное соединение
+// не допускает записи вне открытой транзакции.
+func TestSubscriptionRepository(t *testing.T) {
+	contracttest.SubscriptionRepository(t, func(t *testing.T) interfaces.SubscriptionRepository {
+		return sqliterepo.NewSubscriptionRepository(newTestDB(t))
+	})
+}
+
+// newTestDB открывает новую базу SQLite во временном каталоге и применяет миграции.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := sqlite.New(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(migrate.NewSQLiteDriver(db), migrations.SQLite(), log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
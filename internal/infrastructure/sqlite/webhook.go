@@ -0,0 +1,373 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) interfaces.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (repo *WebhookRepository) Insert(
+	ctx context.Context,
+	webhook *entity.Webhook,
+) (*entity.Webhook, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	query, args, err := squirrel.
+		Insert("webhooks").
+		Columns("tenant_id", "url", "secret", "events", "created_at").
+		Values(
+			tenantID,
+			webhook.URL,
+			webhook.Secret,
+			strings.Join(webhook.Events, " "),
+			webhook.CreatedAt.UnixMilli(),
+		).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&webhook.ID); err != nil {
+		return nil, err
+	}
+
+	webhook.TenantID = tenantID
+	return webhook, nil
+}
+
+func (repo *WebhookRepository) FindByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	query, args, err := repo.getQuery().
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := scanWebhook(conn(ctx, repo.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, failure.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (repo *WebhookRepository) FindAll(ctx context.Context) ([]*entity.Webhook, error) {
+	webhooks := make([]*entity.Webhook, 0)
+
+	query, args, err := repo.getQuery().
+		Where(byTenant(ctx)).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// FindByEvent отбирает вебхуки по типу события после чтения: типы хранятся
+// одной строкой через пробел, а вебхуков у арендатора немного.
+func (repo *WebhookRepository) FindByEvent(
+	ctx context.Context,
+	event string,
+) ([]*entity.Webhook, error) {
+	webhooks, err := repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(webhooks, func(webhook *entity.Webhook) bool {
+		return !slices.Contains(webhook.Events, event)
+	}), nil
+}
+
+func (repo *WebhookRepository) Delete(ctx context.Context, id int) error {
+	query, args, err := squirrel.
+		Delete("webhooks").
+		Where(squirrel.Eq{"id": id}).
+		Where(byTenant(ctx)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return failure.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) getQuery() squirrel.SelectBuilder {
+	return squirrel.
+		Select("id", "tenant_id", "url", "secret", "events", "created_at").
+		From("webhooks")
+}
+
+func scanWebhook(row scanner) (*entity.Webhook, error) {
+	var (
+		webhook   entity.Webhook
+		events    string
+		createdAt int64
+	)
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.TenantID,
+		&webhook.URL,
+		&webhook.Secret,
+		&events,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = strings.Fields(events)
+	webhook.CreatedAt = time.UnixMilli(createdAt)
+
+	return &webhook, nil
+}
+
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) interfaces.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+var webhookDeliveryColumns = []string{
+	"id",
+	"webhook_id",
+	"tenant_id",
+	"event_id",
+	"event_type",
+	"payload",
+	"status",
+	"attempts",
+	"response_status",
+	"error",
+	"created_at",
+	"next_attempt_at",
+	"delivered_at",
+}
+
+func (repo *WebhookDeliveryRepository) Insert(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) (bool, error) {
+	query, args, err := squirrel.
+		Insert("webhook_deliveries").
+		Columns(
+			"webhook_id", "tenant_id", "event_id", "event_type", "payload",
+			"status", "created_at", "next_attempt_at",
+		).
+		Values(
+			delivery.WebhookID, delivery.TenantID, delivery.EventID, delivery.EventType, delivery.Payload,
+			delivery.Status, delivery.CreatedAt.UnixMilli(), delivery.NextAttemptAt.UnixMilli(),
+		).
+		Suffix("ON CONFLICT (webhook_id, event_id) DO NOTHING RETURNING id").
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	if err := conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&delivery.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Claim выполняется одним запросом UPDATE ... RETURNING. SQLite допускает
+// одного писателя, поэтому дополнительная блокировка строк не нужна.
+func (repo *WebhookDeliveryRepository) Claim(
+	ctx context.Context,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	dueSQL, dueArgs, err := squirrel.
+		Select("id").
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"status": entity.DeliveryStatusPending}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now.UnixMilli()}).
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.
+		Update("webhook_deliveries").
+		Set("next_attempt_at", leaseUntil.UnixMilli()).
+		Where(squirrel.Expr("id IN ("+dueSQL+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(webhookDeliveryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *WebhookDeliveryRepository) Update(
+	ctx context.Context,
+	delivery *entity.WebhookDelivery,
+) error {
+	var deliveredAt sql.NullInt64
+	if delivery.DeliveredAt != nil {
+		deliveredAt = sql.NullInt64{Int64: delivery.DeliveredAt.UnixMilli(), Valid: true}
+	}
+
+	query, args, err := squirrel.
+		Update("webhook_deliveries").
+		Set("status", delivery.Status).
+		Set("attempts", delivery.Attempts).
+		Set("response_status", delivery.ResponseStatus).
+		Set("error", delivery.Error).
+		Set("next_attempt_at", delivery.NextAttemptAt.UnixMilli()).
+		Set("delivered_at", deliveredAt).
+		Where(squirrel.Eq{"id": delivery.ID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, repo.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+func (repo *WebhookDeliveryRepository) FindByWebhook(
+	ctx context.Context,
+	webhookID int,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	query, args, err := squirrel.
+		Select(webhookDeliveryColumns...).
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"webhook_id": webhookID}).
+		Where(byTenant(ctx)).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.find(ctx, query, args)
+}
+
+func (repo *WebhookDeliveryRepository) find(
+	ctx context.Context,
+	query string,
+	args []any,
+) ([]*entity.WebhookDelivery, error) {
+	deliveries := make([]*entity.WebhookDelivery, 0)
+
+	rows, err := conn(ctx, repo.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanWebhookDelivery(row scanner) (*entity.WebhookDelivery, error) {
+	var (
+		delivery       entity.WebhookDelivery
+		responseStatus sql.NullInt64
+		createdAt      int64
+		nextAttemptAt  int64
+		deliveredAt    sql.NullInt64
+	)
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.TenantID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&responseStatus,
+		&delivery.Error,
+		&createdAt,
+		&nextAttemptAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	delivery.CreatedAt = time.UnixMilli(createdAt)
+	delivery.NextAttemptAt = time.UnixMilli(nextAttemptAt)
+	if deliveredAt.Valid {
+		t := time.UnixMilli(deliveredAt.Int64)
+		delivery.DeliveredAt = &t
+	}
+
+	return &delivery, nil
+}
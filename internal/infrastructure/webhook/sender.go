@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+)
+
+// maxResponseBody — сколько байт ответа вычитывается, чтобы переиспользовать соединение.
+const maxResponseBody = 64 << 10
+
+// HTTPSender отправляет вебхуки POST-запросами с JSON-телом.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+var _ interfaces.WebhookSender = (*HTTPSender)(nil)
+
+func (sender *HTTPSender) Send(ctx context.Context, req interfaces.WebhookRequest) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "subscriptions-webhooks")
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := sender.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	return resp.StatusCode, nil
+}
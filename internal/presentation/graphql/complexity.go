@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxLimitFactor — наибольший множитель limit при оценке. Любой запрос с
+// таким множителем превышает разумный предел сложности.
+const maxLimitFactor = 1 << 20
+
+// fieldWeights — стоимость полей, которые дороже остальных: они читают из
+// хранилища все подходящие подписки. Остальные поля стоят 1.
+var fieldWeights = map[string]int{
+	"Query.cost": 10,
+	"User.cost":  10,
+}
+
+// costFields — поля, которые рассчитывают стоимость, как GET /costs.
+var costFields = map[string]int{
+	"Query.cost": 1,
+	"User.cost":  1,
+}
+
+// complexity оценивает запрос до выполнения: поле стоит свой вес плюс
+// стоимость подвыборки, а подвыборка поля с аргументом limit умножается на
+// limit, потому что повторяется для каждого элемента страницы. Документ
+// должен пройти валидацию: циклы фрагментов не проверяются.
+func complexity(schema gql.Schema, doc *ast.Document, operation *ast.OperationDefinition, vars map[string]any) int {
+	return newComplexityCounter(schema, doc, vars, fieldWeights, 1).
+		selectionSet(schema.QueryType(), operation.SelectionSet)
+}
+
+// costs считает так же, как complexity, сколько раз запрос рассчитает
+// стоимость: для ограничения частоты это столько же запросов к /costs.
+func costs(schema gql.Schema, doc *ast.Document, operation *ast.OperationDefinition, vars map[string]any) int {
+	return newComplexityCounter(schema, doc, vars, costFields, 0).
+		selectionSet(schema.QueryType(), operation.SelectionSet)
+}
+
+type complexityCounter struct {
+	schema    gql.Schema
+	vars      map[string]any
+	fragments map[string]*ast.FragmentDefinition
+	// weights задаёт вес полей "Тип.поле", остальные поля весят defaultWeight.
+	weights       map[string]int
+	defaultWeight int
+}
+
+func newComplexityCounter(
+	schema gql.Schema,
+	doc *ast.Document,
+	vars map[string]any,
+	weights map[string]int,
+	defaultWeight int,
+) *complexityCounter {
+	c := &complexityCounter{
+		schema:        schema,
+		vars:          vars,
+		fragments:     make(map[string]*ast.FragmentDefinition),
+		weights:       weights,
+		defaultWeight: defaultWeight,
+	}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			c.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return c
+}
+
+func (c *complexityCounter) selectionSet(parent gql.Type, set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	total := 0
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			total += c.field(parent, selection)
+		case *ast.InlineFragment:
+			typ := parent
+			if selection.TypeCondition != nil {
+				typ = c.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			total += c.selectionSet(typ, selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if fragment, ok := c.fragments[selection.Name.Value]; ok {
+				total += c.selectionSet(c.schema.Type(fragment.TypeCondition.Name.Value), fragment.SelectionSet)
+			}
+		}
+	}
+	return total
+}
+
+func (c *complexityCounter) field(parent gql.Type, field *ast.Field) int {
+	name := field.Name.Value
+	if name == gql.TypeNameMetaFieldDef.Name {
+		return 0
+	}
+
+	def := c.fieldDefinition(parent, name)
+	if def == nil {
+		return c.defaultWeight
+	}
+
+	weight, ok := c.weights[parent.Name()+"."+name]
+	if !ok {
+		weight = c.defaultWeight
+	}
+
+	named, _ := gql.GetNamed(def.Type).(gql.Type)
+	child := c.selectionSet(named, field.SelectionSet)
+	if limit, ok := c.intArgument(def, field, "limit"); ok {
+		// Ограничение limit не даёт произведению переполниться.
+		child *= min(max(limit, 1), maxLimitFactor)
+	}
+
+	return weight + child
+}
+
+func (c *complexityCounter) fieldDefinition(parent gql.Type, name string) *gql.FieldDefinition {
+	if parent == c.schema.QueryType() {
+		switch name {
+		case gql.SchemaMetaFieldDef.Name:
+			return gql.SchemaMetaFieldDef
+		case gql.TypeMetaFieldDef.Name:
+			return gql.TypeMetaFieldDef
+		}
+	}
+
+	switch parent := parent.(type) {
+	case *gql.Object:
+		return parent.Fields()[name]
+	case *gql.Interface:
+		return parent.Fields()[name]
+	}
+	return nil
+}
+
+// intArgument возвращает целочисленный аргумент поля из запроса, переменных
+// или значения по умолчанию.
+func (c *complexityCounter) intArgument(def *gql.FieldDefinition, field *ast.Field, name string) (int, bool) {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(value.Value)
+			return n, err == nil
+		case *ast.Variable:
+			if n, ok := intValue(c.vars[value.Name.Value]); ok {
+				return n, true
+			}
+		}
+	}
+
+	for _, arg := range def.Args {
+		if arg.Name() == name {
+			return intValue(arg.DefaultValue)
+		}
+	}
+	return 0, false
+}
+
+func intValue(value any) (int, bool) {
+	switch value := value.(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	case json.Number:
+		n, err := value.Int64()
+		return int(n), err == nil
+	}
+	return 0, false
+}
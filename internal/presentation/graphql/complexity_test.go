@@ -0,0 +1,201 @@
+package graphql
+
+import (
+	"testing"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/noredis/subscriptions/internal/application/dto"
+)
+
+func parse(t *testing.T, schema gql.Schema, query string) (*ast.Document, *ast.OperationDefinition) {
+	t.Helper()
+
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if validation := gql.ValidateDocument(&schema, doc, nil); !validation.IsValid {
+		t.Fatalf("validate: %v", validation.Errors)
+	}
+
+	operation := findOperation(doc, "")
+	if operation == nil {
+		t.Fatal("operation not found")
+	}
+	return doc, operation
+}
+
+func newTestSchema(t *testing.T) gql.Schema {
+	t.Helper()
+
+	schema, err := newSchema(nil, nil)
+	if err != nil {
+		t.Fatalf("new schema: %v", err)
+	}
+	return schema
+}
+
+func TestCosts(t *testing.T) {
+	schema := newTestSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  int
+	}{
+		{
+			name:  "no cost",
+			query: `{ subscriptions { items { id } } }`,
+			want:  0,
+		},
+		{
+			name:  "query cost",
+			query: `{ cost(filter: {startDate: "01-2025", endDate: "12-2025"}) { total } }`,
+			want:  1,
+		},
+		{
+			name: "aliases",
+			query: `{
+				a: cost(filter: {startDate: "01-2025", endDate: "12-2025"}) { total }
+				b: cost(filter: {startDate: "01-2024", endDate: "12-2024"}) { total }
+				c: user(id: "u") { cost(filter: {startDate: "01-2025", endDate: "12-2025"}) { total } }
+			}`,
+			want: 3,
+		},
+		{
+			name:  "subscription cost does not read storage",
+			query: `{ subscriptions(limit: 50) { items { cost(startDate: "01-2025", endDate: "12-2025") { cost } } } }`,
+			want:  0,
+		},
+		{
+			name: "fragment",
+			query: `query { user(id: "u") { ...userCost } }
+				fragment userCost on User { cost(filter: {startDate: "01-2025", endDate: "12-2025"}) { total } }`,
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, operation := parse(t, schema, tt.query)
+
+			if got := costs(schema, doc, operation, tt.vars); got != tt.want {
+				t.Fatalf("costs = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComplexity(t *testing.T) {
+	schema := newTestSchema(t)
+
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  int
+	}{
+		{
+			name:  "limit multiplies selection",
+			query: `{ subscriptions(limit: 5) { items { id } } }`,
+			want:  1 + 5*2,
+		},
+		{
+			name:  "default limit",
+			query: `{ subscriptions { items { id } } }`,
+			want:  1 + defaultLimit*2,
+		},
+		{
+			name:  "limit from variable",
+			query: `query($n: Int) { subscriptions(limit: $n) { items { id } } }`,
+			vars:  map[string]any{"n": 3},
+			want:  1 + 3*2,
+		},
+		{
+			name:  "limit from json variable",
+			query: `query($n: Int) { subscriptions(limit: $n) { items { id } } }`,
+			vars:  map[string]any{"n": float64(3)},
+			want:  1 + 3*2,
+		},
+		{
+			name:  "missing variable uses default",
+			query: `query($n: Int) { subscriptions(limit: $n) { items { id } } }`,
+			want:  1 + defaultLimit*2,
+		},
+		{
+			name:  "zero limit counts once",
+			query: `{ subscriptions(limit: 0) { items { id } } }`,
+			want:  1 + 2,
+		},
+		{
+			name:  "limit is capped",
+			query: `{ subscriptions(limit: 2000000000) { items { id } } }`,
+			want:  1 + maxLimitFactor*2,
+		},
+		{
+			name:  "nested limits multiply",
+			query: `{ user(id: "u") { subscriptions(limit: 4) { items { id price } } } }`,
+			want:  1 + 1 + 4*3,
+		},
+		{
+			name:  "cost weight",
+			query: `{ cost(filter: {startDate: "01-2025", endDate: "12-2025"}) { total } }`,
+			want:  fieldWeights["Query.cost"] + 1,
+		},
+		{
+			name: "fragment",
+			query: `query { subscriptions(limit: 2) { ...page } }
+				fragment page on SubscriptionPage { items { id price } }`,
+			want: 1 + 2*3,
+		},
+		{
+			name:  "inline fragment",
+			query: `{ subscriptions(limit: 2) { ... on SubscriptionPage { total } } }`,
+			want:  1 + 2*1,
+		},
+		{
+			name:  "typename is free",
+			query: `{ __typename subscriptions(limit: 1) { total } }`,
+			want:  1 + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, operation := parse(t, schema, tt.query)
+
+			if got := complexity(schema, doc, operation, tt.vars); got != tt.want {
+				t.Fatalf("complexity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareRejectsComplexQuery(t *testing.T) {
+	const maxComplexity = 1 + defaultLimit*2 - 1
+
+	executor, err := NewExecutor(nil, nil, maxComplexity)
+	if err != nil {
+		t.Fatalf("new executor: %v", err)
+	}
+
+	query, result := executor.Prepare(dto.GraphQLRequest{Query: `{ subscriptions(limit: 19) { items { id } } }`})
+	if result != nil || query == nil {
+		t.Fatalf("query within limit rejected: %+v", result)
+	}
+
+	query, result = executor.Prepare(dto.GraphQLRequest{Query: `{ subscriptions { items { id } } }`})
+	if query != nil || result == nil || len(result.Errors) != 1 {
+		t.Fatalf("query above limit accepted: %+v", result)
+	}
+
+	extensions := result.Errors[0].Extensions
+	if extensions["code"] != ComplexityErrorCode ||
+		extensions["complexity"] != 1+defaultLimit*2 ||
+		extensions["limit"] != maxComplexity {
+		t.Fatalf("extensions = %v, want complexity error", extensions)
+	}
+}
@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/dto"
+)
+
+// ComplexityErrorCode — код ошибки запроса, сложность которого превышает предел.
+const ComplexityErrorCode = "query_too_complex"
+
+// Executor выполняет запросы GraphQL к подпискам и их стоимости. Перед
+// выполнением запрос проходит валидацию и оценку сложности в Prepare.
+type Executor struct {
+	schema        gql.Schema
+	maxComplexity int
+}
+
+func NewExecutor(
+	subscriptions *appservice.SubscriptionService,
+	costs *appservice.CostService,
+	maxComplexity int,
+) (*Executor, error) {
+	schema, err := newSchema(subscriptions, costs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Executor{
+		schema:        schema,
+		maxComplexity: maxComplexity,
+	}, nil
+}
+
+// Query — разобранный и проверенный запрос.
+type Query struct {
+	doc *ast.Document
+	req dto.GraphQLRequest
+	// Costs — сколько раз запрос рассчитает стоимость.
+	Costs int
+}
+
+// Prepare разбирает запрос, проверяет его и оценивает сложность. Если запрос
+// выполнять нельзя, возвращает результат с ошибками.
+func (executor *Executor) Prepare(req dto.GraphQLRequest) (*Query, *gql.Result) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(req.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return nil, &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := gql.ValidateDocument(&executor.schema, doc, nil)
+	if !validation.IsValid {
+		return nil, &gql.Result{Errors: validation.Errors}
+	}
+
+	query := &Query{doc: doc, req: req}
+
+	// Без подходящей операции оценивать нечего: Execute вернёт ошибку сам.
+	if operation := findOperation(doc, req.OperationName); operation != nil {
+		if n := complexity(executor.schema, doc, operation, req.Variables); n > executor.maxComplexity {
+			return nil, &gql.Result{Errors: []gqlerrors.FormattedError{{
+				Message: fmt.Sprintf("query complexity %d exceeds limit %d", n, executor.maxComplexity),
+				Extensions: map[string]any{
+					"code":       ComplexityErrorCode,
+					"complexity": n,
+					"limit":      executor.maxComplexity,
+				},
+			}}}
+		}
+		query.Costs = costs(executor.schema, doc, operation, req.Variables)
+	}
+
+	return query, nil
+}
+
+// Execute выполняет запрос, подготовленный Prepare.
+func (executor *Executor) Execute(ctx context.Context, query *Query) *gql.Result {
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        executor.schema,
+		AST:           query.doc,
+		OperationName: query.req.OperationName,
+		Args:          query.req.Variables,
+		Context:       ctx,
+	})
+}
+
+// findOperation выбирает операцию по имени или единственную операцию документа.
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		operation, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
+
+// OriginalError возвращает ошибку резолвера, из-за которой в результат
+// попала ошибка err, или nil для ошибок разбора и валидации запроса.
+func OriginalError(err gqlerrors.FormattedError) error {
+	var located *gqlerrors.Error
+	if errors.As(err.OriginalError(), &located) {
+		return located.OriginalError
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+)
+
+// user — источник полей типа User.
+type user struct {
+	id string
+}
+
+// resolver читает данные для полей схемы через прикладные сервисы, поэтому
+// доступ к подпискам ограничен так же, как в REST API.
+type resolver struct {
+	subscriptions *appservice.SubscriptionService
+	costs         *appservice.CostService
+}
+
+func newSchema(subscriptions *appservice.SubscriptionService, costs *appservice.CostService) (gql.Schema, error) {
+	r := &resolver{subscriptions: subscriptions, costs: costs}
+
+	subscriptionFilter := gql.NewInputObject(gql.InputObjectConfig{
+		Name:        "SubscriptionFilter",
+		Description: "Фильтры выборки подписок. Даты в формате MM-YYYY.",
+		Fields: gql.InputObjectConfigFieldMap{
+			"serviceName": &gql.InputObjectFieldConfig{Type: gql.String},
+			"userId":      &gql.InputObjectFieldConfig{Type: gql.String},
+			"startDate":   &gql.InputObjectFieldConfig{Type: gql.String},
+			"endDate":     &gql.InputObjectFieldConfig{Type: gql.String},
+		},
+	})
+
+	costFilter := gql.NewInputObject(gql.InputObjectConfig{
+		Name:        "CostFilter",
+		Description: "Период расчёта в формате MM-YYYY и необязательные фильтры.",
+		Fields: gql.InputObjectConfigFieldMap{
+			"serviceName": &gql.InputObjectFieldConfig{Type: gql.String},
+			"userId":      &gql.InputObjectFieldConfig{Type: gql.String},
+			"startDate":   &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+			"endDate":     &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+		},
+	})
+
+	costItem := gql.NewObject(gql.ObjectConfig{
+		Name:        "CostItem",
+		Description: "Стоимость подписки за период.",
+		Fields: gql.Fields{
+			"subscriptionId": prop(gql.NewNonNull(gql.Int), func(item *dto.CostBreakdownItem) any { return item.SubscriptionID }),
+			"serviceName":    prop(gql.NewNonNull(gql.String), func(item *dto.CostBreakdownItem) any { return item.ServiceName }),
+			"userId":         prop(gql.NewNonNull(gql.String), func(item *dto.CostBreakdownItem) any { return item.UserID }),
+			"price":          prop(gql.NewNonNull(gql.Int), func(item *dto.CostBreakdownItem) any { return item.Price }),
+			"months":         prop(gql.NewNonNull(gql.Int), func(item *dto.CostBreakdownItem) any { return item.Months }),
+			"cost":           prop(gql.NewNonNull(gql.Int), func(item *dto.CostBreakdownItem) any { return item.Cost }),
+		},
+	})
+
+	cost := gql.NewObject(gql.ObjectConfig{
+		Name:        "Cost",
+		Description: "Стоимость подписок за период. items запрашивает детализацию по подпискам.",
+		Fields: gql.Fields{
+			"total": prop(gql.NewNonNull(gql.Int), func(resp *dto.CostBreakdownResponse) any { return resp.TotalCost }),
+			"items": prop(
+				gql.NewNonNull(gql.NewList(gql.NewNonNull(costItem))),
+				func(resp *dto.CostBreakdownResponse) any { return resp.Items },
+			),
+		},
+	})
+
+	subscription := gql.NewObject(gql.ObjectConfig{
+		Name:        "Subscription",
+		Description: "Подписка пользователя на сервис. Даты в формате MM-YYYY.",
+		Fields: gql.Fields{
+			"id":          prop(gql.NewNonNull(gql.Int), func(sub *dto.SubscriptionResponse) any { return sub.ID }),
+			"serviceName": prop(gql.NewNonNull(gql.String), func(sub *dto.SubscriptionResponse) any { return sub.ServiceName }),
+			"price":       prop(gql.NewNonNull(gql.Int), func(sub *dto.SubscriptionResponse) any { return sub.Price }),
+			"userId":      prop(gql.NewNonNull(gql.String), func(sub *dto.SubscriptionResponse) any { return sub.UserID }),
+			"startDate":   prop(gql.NewNonNull(gql.String), func(sub *dto.SubscriptionResponse) any { return sub.StartDate }),
+			"endDate": prop(gql.String, func(sub *dto.SubscriptionResponse) any {
+				if sub.EndDate == "" {
+					return nil
+				}
+				return sub.EndDate
+			}),
+			"cost": &gql.Field{
+				Type:        costItem,
+				Description: "Стоимость подписки за период.",
+				Args: gql.FieldConfigArgument{
+					"startDate": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"endDate":   &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: r.subscriptionCost,
+			},
+		},
+	})
+
+	subscriptionPage := gql.NewObject(gql.ObjectConfig{
+		Name:        "SubscriptionPage",
+		Description: "Страница подписок. total - число подписок под фильтр на всех страницах.",
+		Fields: gql.Fields{
+			"items": prop(
+				gql.NewNonNull(gql.NewList(gql.NewNonNull(subscription))),
+				func(resp *dto.SubscriptionListResponse) any { return resp.Data },
+			),
+			"page":  prop(gql.NewNonNull(gql.Int), func(resp *dto.SubscriptionListResponse) any { return resp.Page }),
+			"limit": prop(gql.NewNonNull(gql.Int), func(resp *dto.SubscriptionListResponse) any { return resp.Limit }),
+			"total": prop(gql.NewNonNull(gql.Int), func(resp *dto.SubscriptionListResponse) any { return resp.Total }),
+		},
+	})
+
+	subscriptionsArgs := gql.FieldConfigArgument{
+		"filter": &gql.ArgumentConfig{Type: subscriptionFilter},
+		"page":   &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultPage},
+		"limit":  &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultLimit},
+	}
+	costArgs := gql.FieldConfigArgument{
+		"filter": &gql.ArgumentConfig{Type: gql.NewNonNull(costFilter)},
+	}
+
+	userType := gql.NewObject(gql.ObjectConfig{
+		Name:        "User",
+		Description: "Подписки и стоимость подписок одного пользователя. userId в фильтрах не учитывается.",
+		Fields: gql.Fields{
+			"id": prop(gql.NewNonNull(gql.String), func(u *user) any { return u.id }),
+			"subscriptions": &gql.Field{
+				Type:    subscriptionPage,
+				Args:    subscriptionsArgs,
+				Resolve: r.userSubscriptions,
+			},
+			"cost": &gql.Field{
+				Type:    cost,
+				Args:    costArgs,
+				Resolve: r.userCost,
+			},
+		},
+	})
+
+	// Поля, которые читают данные через сервисы, допускают null: ошибка в
+	// одном из них не должна обнулять результат остальных.
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"subscription": &gql.Field{
+				Type:        subscription,
+				Description: "Подписка по идентификатору.",
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.Int)},
+				},
+				Resolve: r.subscription,
+			},
+			"subscriptions": &gql.Field{
+				Type:        subscriptionPage,
+				Description: "Подписки под фильтр с пагинацией.",
+				Args:        subscriptionsArgs,
+				Resolve:     r.subscriptionList,
+			},
+			"user": &gql.Field{
+				Type:        userType,
+				Description: "Сводка по подпискам пользователя.",
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: r.user,
+			},
+			"cost": &gql.Field{
+				Type:        cost,
+				Description: "Стоимость подписок за период.",
+				Args:        costArgs,
+				Resolve:     r.cost,
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query})
+}
+
+func (r *resolver) subscription(p gql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, auth.ScopeSubscriptionsRead); err != nil {
+		return nil, err
+	}
+
+	id, _ := p.Args["id"].(int)
+	return r.subscriptions.Index(p.Context, id)
+}
+
+func (r *resolver) subscriptionList(p gql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, auth.ScopeSubscriptionsRead); err != nil {
+		return nil, err
+	}
+
+	return r.subscriptions.List(p.Context, subscriptionFilterArgs(p.Args))
+}
+
+func (r *resolver) user(p gql.ResolveParams) (any, error) {
+	id, _ := p.Args["id"].(string)
+
+	// Пользователь без роли администратора видит только себя, как в фильтре
+	// user_id REST API.
+	if _, ok := auth.ScopeUserID(p.Context, id); !ok {
+		return nil, failure.ErrForbidden
+	}
+
+	return &user{id: id}, nil
+}
+
+func (r *resolver) userSubscriptions(p gql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, auth.ScopeSubscriptionsRead); err != nil {
+		return nil, err
+	}
+
+	filters := subscriptionFilterArgs(p.Args)
+	filters.UserID = p.Source.(*user).id
+
+	return r.subscriptions.List(p.Context, filters)
+}
+
+func (r *resolver) cost(p gql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, auth.ScopeCostsRead); err != nil {
+		return nil, err
+	}
+
+	return r.costFor(p, costFilterArgs(p.Args))
+}
+
+func (r *resolver) userCost(p gql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, auth.ScopeCostsRead); err != nil {
+		return nil, err
+	}
+
+	filters := costFilterArgs(p.Args)
+	filters.UserID = p.Source.(*user).id
+
+	return r.costFor(p, filters)
+}
+
+// costFor запрашивает детализацию, только если выбрано поле items: для
+// суммы подписки не нужно превращать в позиции.
+func (r *resolver) costFor(p gql.ResolveParams, filters dto.CostFilterDTO) (any, error) {
+	if selects(p.Info, "items") {
+		return r.costs.Breakdown(p.Context, filters)
+	}
+
+	resp, err := r.costs.Total(p.Context, filters)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CostBreakdownResponse{TotalCost: resp.TotalCost}, nil
+}
+
+func (r *resolver) subscriptionCost(p gql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, auth.ScopeCostsRead); err != nil {
+		return nil, err
+	}
+
+	startDate, _ := p.Args["startDate"].(string)
+	endDate, _ := p.Args["endDate"].(string)
+
+	return r.costs.SubscriptionCost(p.Context, p.Source.(*dto.SubscriptionResponse), dto.CostFilterDTO{
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+}
+
+// requireScope проверяет право на поле так же, как middleware RequireScope
+// проверяет его на маршрут REST API.
+func requireScope(ctx context.Context, scope string) error {
+	if !auth.HasScope(ctx, scope) {
+		return fmt.Errorf("%w: insufficient scope: %s is required", failure.ErrForbidden, scope)
+	}
+	return nil
+}
+
+func subscriptionFilterArgs(args map[string]any) dto.SubscriptionFilterDTO {
+	filter, _ := args["filter"].(map[string]any)
+	page, _ := args["page"].(int)
+	limit, _ := args["limit"].(int)
+
+	return dto.SubscriptionFilterDTO{
+		Page:        page,
+		Limit:       limit,
+		ServiceName: stringArg(filter, "serviceName"),
+		UserID:      stringArg(filter, "userId"),
+		StartDate:   stringArg(filter, "startDate"),
+		EndDate:     stringArg(filter, "endDate"),
+	}
+}
+
+func costFilterArgs(args map[string]any) dto.CostFilterDTO {
+	filter, _ := args["filter"].(map[string]any)
+
+	return dto.CostFilterDTO{
+		ServiceName: stringArg(filter, "serviceName"),
+		UserID:      stringArg(filter, "userId"),
+		StartDate:   stringArg(filter, "startDate"),
+		EndDate:     stringArg(filter, "endDate"),
+	}
+}
+
+func stringArg(args map[string]any, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// prop описывает поле, значение которого берётся из источника типа T.
+func prop[T any](typ gql.Output, get func(T) any) *gql.Field {
+	return &gql.Field{
+		Type: typ,
+		Resolve: func(p gql.ResolveParams) (any, error) {
+			return get(p.Source.(T)), nil
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// selects сообщает, выбрано ли поле name в подвыборке текущего поля, в том
+// числе через фрагменты.
+func selects(info gql.ResolveInfo, name string) bool {
+	for _, field := range info.FieldASTs {
+		if selectionSetSelects(info, field.SelectionSet, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func selectionSetSelects(info gql.ResolveInfo, set *ast.SelectionSet, name string) bool {
+	if set == nil {
+		return false
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Name != nil && selection.Name.Value == name {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectionSetSelects(info, selection.SelectionSet, name) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			fragment, ok := info.Fragments[selection.Name.Value].(*ast.FragmentDefinition)
+			if ok && selectionSetSelects(info, fragment.SelectionSet, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	subscriptionsv1 "github.com/noredis/subscriptions/pkg/pb/subscriptions/v1"
+	"google.golang.org/grpc"
+)
+
+type CostHandler struct {
+	subscriptionsv1.UnimplementedCostServiceServer
+
+	service  *appservice.CostService
+	problems *Problems
+}
+
+func NewCostHandler(service *appservice.CostService, problems *Problems) *CostHandler {
+	return &CostHandler{
+		service:  service,
+		problems: problems,
+	}
+}
+
+func (handler *CostHandler) Register(server *grpc.Server) {
+	subscriptionsv1.RegisterCostServiceServer(server, handler)
+}
+
+func (handler *CostHandler) GetTotalCost(
+	ctx context.Context,
+	req *subscriptionsv1.GetTotalCostRequest,
+) (*subscriptionsv1.GetTotalCostResponse, error) {
+	resp, err := handler.service.Total(ctx, mapCostFilter(req.GetFilter()))
+	if err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to calculate total cost")
+	}
+
+	return &subscriptionsv1.GetTotalCostResponse{TotalCost: int64(resp.TotalCost)}, nil
+}
+
+func (handler *CostHandler) GetCostBreakdown(
+	ctx context.Context,
+	req *subscriptionsv1.GetCostBreakdownRequest,
+) (*subscriptionsv1.GetCostBreakdownResponse, error) {
+	resp, err := handler.service.Breakdown(ctx, mapCostFilter(req.GetFilter()))
+	if err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to calculate cost breakdown")
+	}
+
+	items := make([]*subscriptionsv1.CostBreakdownItem, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, &subscriptionsv1.CostBreakdownItem{
+			SubscriptionId: int64(item.SubscriptionID),
+			ServiceName:    item.ServiceName,
+			UserId:         item.UserID,
+			Price:          int64(item.Price),
+			Months:         int32(item.Months),
+			Cost:           int64(item.Cost),
+		})
+	}
+
+	return &subscriptionsv1.GetCostBreakdownResponse{
+		TotalCost: int64(resp.TotalCost),
+		Items:     items,
+	}, nil
+}
+
+func mapCostFilter(filter *subscriptionsv1.CostFilter) dto.CostFilterDTO {
+	return dto.CostFilterDTO{
+		ServiceName: filter.GetServiceName(),
+		UserID:      filter.GetUserId(),
+		StartDate:   filter.GetStartDate(),
+		EndDate:     filter.GetEndDate(),
+	}
+}
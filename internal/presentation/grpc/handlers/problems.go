@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/noredis/subscriptions/pkg/validatorext"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain — домен кодов ошибок в google.rpc.ErrorInfo.
+const errorDomain = "subscriptions"
+
+// Problems превращает ошибки в статусы gRPC по тому же реестру, что и HTTP API:
+// код статуса соответствует HTTP-статусу ошибки, код проблемы передаётся
+// в google.rpc.ErrorInfo, ошибки полей - в google.rpc.BadRequest.
+type Problems struct {
+	registry *httpext.ProblemRegistry
+	uni      *ut.UniversalTranslator
+}
+
+func NewProblems(registry *httpext.ProblemRegistry, uni *ut.UniversalTranslator) *Problems {
+	return &Problems{registry: registry, uni: uni}
+}
+
+// Status описывает ошибку на языке из метаданных accept-language вызова.
+func (problems *Problems) Status(ctx context.Context, err error) *status.Status {
+	resp := problems.registry.ResolveIn(err, problems.translator(ctx))
+
+	st := status.New(grpcCode(resp.Status), resp.Body.Error)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: resp.Problem.Code, Domain: errorDomain},
+	}
+	if len(resp.Body.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(resp.Body.Fields))
+		for _, field := range resp.Body.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Description,
+			})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	// Детали не добавляются, только если их не удалось сериализовать.
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// error пишет ошибку в лог вызова и возвращает её статус.
+func (problems *Problems) error(ctx context.Context, err error, err500msg string) error {
+	st := problems.Status(ctx, err)
+
+	logger := zerolog.Ctx(ctx)
+	if st.Code() == codes.Internal {
+		logger.Error().Err(err).Msg(err500msg)
+	} else {
+		logger.Info().Err(err).Msg(st.Message())
+	}
+
+	return st.Err()
+}
+
+// translator выбирает язык по метаданным accept-language так же, как HTTP
+// API по заголовку Accept-Language. Без подходящего языка используется
+// язык по умолчанию.
+func (problems *Problems) translator(ctx context.Context) ut.Translator {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, header := range md.Get("accept-language") {
+		for _, tag := range strings.Split(header, ",") {
+			tag, _, _ = strings.Cut(tag, ";")
+			tag = strings.ToLower(strings.TrimSpace(tag))
+
+			for _, locale := range validatorext.Locales {
+				if tag == locale || strings.HasPrefix(tag, locale+"-") {
+					trans, _ := problems.uni.GetTranslator(locale)
+					return trans
+				}
+			}
+		}
+	}
+
+	trans, _ := problems.uni.GetTranslator(validatorext.Locales[0])
+	return trans
+}
+
+// grpcCode сопоставляет HTTP-статус ошибки коду gRPC.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusFailedDependency:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
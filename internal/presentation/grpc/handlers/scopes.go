@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/noredis/subscriptions/internal/application/auth"
+	subscriptionsv1 "github.com/noredis/subscriptions/pkg/pb/subscriptions/v1"
+)
+
+// Scopes — права, которые требуются для вызова методов gRPC API. Они
+// совпадают с правами соответствующих маршрутов HTTP API.
+var Scopes = map[string]string{
+	subscriptionsv1.SubscriptionService_CreateSubscription_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionsv1.SubscriptionService_UpdateSubscription_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionsv1.SubscriptionService_DeleteSubscription_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionsv1.SubscriptionService_BatchSubscriptions_FullMethodName: auth.ScopeSubscriptionsWrite,
+	subscriptionsv1.SubscriptionService_GetSubscription_FullMethodName:    auth.ScopeSubscriptionsRead,
+	subscriptionsv1.SubscriptionService_ListSubscriptions_FullMethodName:  auth.ScopeSubscriptionsRead,
+	subscriptionsv1.CostService_GetTotalCost_FullMethodName:               auth.ScopeCostsRead,
+	subscriptionsv1.CostService_GetCostBreakdown_FullMethodName:           auth.ScopeCostsRead,
+}
@@ -0,0 +1,35 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/presentation/grpc/handlers"
+	subscriptionsv1 "github.com/noredis/subscriptions/pkg/pb/subscriptions/v1"
+	"google.golang.org/grpc"
+)
+
+// TestScopesCoverServices проверяет, что у каждого метода gRPC API есть
+// право: RequireScopes запрещает вызов метода без права.
+func TestScopesCoverServices(t *testing.T) {
+	services := []grpc.ServiceDesc{
+		subscriptionsv1.SubscriptionService_ServiceDesc,
+		subscriptionsv1.CostService_ServiceDesc,
+	}
+
+	for _, service := range services {
+		methods := make([]string, 0, len(service.Methods)+len(service.Streams))
+		for _, method := range service.Methods {
+			methods = append(methods, method.MethodName)
+		}
+		for _, stream := range service.Streams {
+			methods = append(methods, stream.StreamName)
+		}
+
+		for _, method := range methods {
+			fullMethod := "/" + service.ServiceName + "/" + method
+			if _, ok := handlers.Scopes[fullMethod]; !ok {
+				t.Errorf("method %s has no scope", fullMethod)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	subscriptionsv1 "github.com/noredis/subscriptions/pkg/pb/subscriptions/v1"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultListPageSize = 500
+	maxListPageSize     = 1000
+)
+
+type SubscriptionHandler struct {
+	subscriptionsv1.UnimplementedSubscriptionServiceServer
+
+	service  *appservice.SubscriptionService
+	problems *Problems
+}
+
+func NewSubscriptionHandler(service *appservice.SubscriptionService, problems *Problems) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		service:  service,
+		problems: problems,
+	}
+}
+
+func (handler *SubscriptionHandler) Register(server *grpc.Server) {
+	subscriptionsv1.RegisterSubscriptionServiceServer(server, handler)
+}
+
+func (handler *SubscriptionHandler) CreateSubscription(
+	ctx context.Context,
+	req *subscriptionsv1.CreateSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	resp, err := handler.service.Create(ctx, mapSubscriptionInput(req.GetSubscription()))
+	if err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to create subscription")
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int("id", resp.ID).
+		Str("service_name", resp.ServiceName).
+		Str("user_id", resp.UserID).
+		Msg("subscription created")
+	return mapSubscription(resp), nil
+}
+
+func (handler *SubscriptionHandler) GetSubscription(
+	ctx context.Context,
+	req *subscriptionsv1.GetSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	resp, err := handler.service.Index(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to index subscription")
+	}
+
+	return mapSubscription(resp), nil
+}
+
+func (handler *SubscriptionHandler) UpdateSubscription(
+	ctx context.Context,
+	req *subscriptionsv1.UpdateSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	resp, err := handler.service.Update(ctx, mapSubscriptionInput(req.GetSubscription()), int(req.GetId()))
+	if err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to update subscription")
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int("id", resp.ID).
+		Str("service_name", resp.ServiceName).
+		Str("user_id", resp.UserID).
+		Msg("subscription updated")
+	return mapSubscription(resp), nil
+}
+
+func (handler *SubscriptionHandler) DeleteSubscription(
+	ctx context.Context,
+	req *subscriptionsv1.DeleteSubscriptionRequest,
+) (*emptypb.Empty, error) {
+	if err := handler.service.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to delete subscription")
+	}
+
+	zerolog.Ctx(ctx).Info().Int64("id", req.GetId()).Msg("subscription deleted")
+	return &emptypb.Empty{}, nil
+}
+
+// ListSubscriptions читает подписки страницами по page_size и отправляет их
+// по одной, пока страницы не закончатся. Изменения, сделанные во время
+// чтения, могут сдвинуть страницы.
+func (handler *SubscriptionHandler) ListSubscriptions(
+	req *subscriptionsv1.ListSubscriptionsRequest,
+	stream grpc.ServerStreamingServer[subscriptionsv1.Subscription],
+) error {
+	ctx := stream.Context()
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+	pageSize = min(pageSize, maxListPageSize)
+
+	filter := req.GetFilter()
+	filters := dto.SubscriptionFilterDTO{
+		Limit:       pageSize,
+		ServiceName: filter.GetServiceName(),
+		UserID:      filter.GetUserId(),
+		StartDate:   filter.GetStartDate(),
+		EndDate:     filter.GetEndDate(),
+	}
+
+	for filters.Page = 1; ; filters.Page++ {
+		resp, err := handler.service.List(ctx, filters)
+		if err != nil {
+			return handler.problems.error(ctx, err, "failed to list subscriptions")
+		}
+
+		for _, sub := range resp.Data {
+			if err := stream.Send(mapSubscription(sub)); err != nil {
+				return err
+			}
+		}
+
+		if len(resp.Data) < pageSize || filters.Page*pageSize >= resp.Total {
+			return nil
+		}
+	}
+}
+
+func (handler *SubscriptionHandler) BatchSubscriptions(
+	ctx context.Context,
+	req *subscriptionsv1.BatchSubscriptionsRequest,
+) (*subscriptionsv1.BatchSubscriptionsResponse, error) {
+	batch := dto.BatchRequest{
+		Operations: make([]dto.BatchOperation, 0, len(req.GetOperations())),
+	}
+	for _, op := range req.GetOperations() {
+		batch.Operations = append(batch.Operations, mapBatchOperation(op))
+	}
+
+	result, err := handler.service.Batch(ctx, batch)
+	if err != nil {
+		return nil, handler.problems.error(ctx, err, "failed to execute batch")
+	}
+
+	resp := &subscriptionsv1.BatchSubscriptionsResponse{
+		Committed: result.Committed,
+		Results:   make([]*subscriptionsv1.BatchOperationResult, len(result.Operations)),
+	}
+
+	for i, op := range result.Operations {
+		opResp := &subscriptionsv1.BatchOperationResult{
+			Index: int32(i),
+			Op:    op.Op,
+			Code:  int32(codes.OK),
+		}
+		if op.Data != nil {
+			opResp.Subscription = mapSubscription(op.Data)
+		}
+
+		if op.Err != nil {
+			st := handler.problems.Status(ctx, op.Err)
+			opResp.Code = int32(st.Code())
+			opResp.Message = st.Message()
+
+			if !errors.Is(op.Err, failure.ErrBatchRolledBack) {
+				zerolog.Ctx(ctx).Info().Err(op.Err).Int("index", i).Msg("batch operation failed")
+			}
+		}
+
+		resp.Results[i] = opResp
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int("operations", len(resp.Results)).
+		Bool("committed", resp.Committed).
+		Msg("batch executed")
+	return resp, nil
+}
+
+func mapBatchOperation(op *subscriptionsv1.BatchOperation) dto.BatchOperation {
+	switch {
+	case op.GetCreate() != nil:
+		data := mapSubscriptionInput(op.GetCreate().GetSubscription())
+		return dto.BatchOperation{Op: dto.BatchOpCreate, Data: &data}
+	case op.GetUpdate() != nil:
+		data := mapSubscriptionInput(op.GetUpdate().GetSubscription())
+		return dto.BatchOperation{Op: dto.BatchOpUpdate, ID: int(op.GetUpdate().GetId()), Data: &data}
+	case op.GetDelete() != nil:
+		return dto.BatchOperation{Op: dto.BatchOpDelete, ID: int(op.GetDelete().GetId())}
+	default:
+		// Операция без типа не проходит валидацию пакета.
+		return dto.BatchOperation{}
+	}
+}
+
+func mapSubscriptionInput(input *subscriptionsv1.SubscriptionInput) dto.SubscriptionRequest {
+	return dto.SubscriptionRequest{
+		ServiceName: input.GetServiceName(),
+		Price:       int(input.GetPrice()),
+		UserID:      input.GetUserId(),
+		StartDate:   input.GetStartDate(),
+		EndDate:     input.GetEndDate(),
+	}
+}
+
+func mapSubscription(sub *dto.SubscriptionResponse) *subscriptionsv1.Subscription {
+	return &subscriptionsv1.Subscription{
+		Id:          int64(sub.ID),
+		ServiceName: sub.ServiceName,
+		Price:       int64(sub.Price),
+		UserId:      sub.UserID,
+		StartDate:   sub.StartDate,
+		EndDate:     sub.EndDate,
+	}
+}
@@ -0,0 +1,67 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationKey = "authorization"
+	apiKeyKey        = "x-api-key"
+)
+
+// Authentication проверяет JWT или API-ключ из метаданных authorization и
+// x-api-key и кладёт пользователя в контекст вызова.
+func Authentication(authenticator *appservice.Authenticator, logger *zerolog.Logger) Interceptor {
+	return func(ctx context.Context, _ string, next func(ctx context.Context) error) error {
+		token, ok := strings.CutPrefix(firstValue(ctx, authorizationKey), "Bearer ")
+		if key := firstValue(ctx, apiKeyKey); key != "" {
+			token, ok = key, true
+		}
+		if !ok || token == "" {
+			return status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+
+		principal, err := authenticator.Authenticate(ctx, token)
+		if err != nil {
+			switch {
+			case errors.Is(err, failure.ErrInvalidAPIKey):
+				return status.Error(codes.Unauthenticated, err.Error())
+			case errors.Is(err, failure.ErrInvalidToken):
+				logger.Info().Err(err).Msg("invalid token")
+				return status.Error(codes.Unauthenticated, failure.ErrInvalidToken.Error())
+			}
+
+			logger.Error().Err(err).Msg("failed to authenticate")
+			return status.Error(codes.Internal, "internal server error")
+		}
+
+		return next(auth.WithPrincipal(ctx, principal))
+	}
+}
+
+// RequireScopes пропускает вызов, только если пользователю выдано право,
+// которое scopes сопоставляет методу. Вызов метода без права запрещается,
+// поэтому публичные сервисы, например проверку здоровья, нужно исключить
+// через Skip. Без аутентификации пользователя в контексте нет и проверка
+// не выполняется.
+func RequireScopes(scopes map[string]string) Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		scope, ok := scopes[method]
+		if !ok {
+			return status.Error(codes.PermissionDenied, "method "+method+" has no required scope")
+		}
+		if !auth.HasScope(ctx, scope) {
+			return status.Error(codes.PermissionDenied, "insufficient scope: "+scope+" is required")
+		}
+		return next(ctx)
+	}
+}
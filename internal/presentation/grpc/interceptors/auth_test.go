@@ -0,0 +1,73 @@
+package interceptors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/presentation/grpc/interceptors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequireScopes(t *testing.T) {
+	const (
+		read   = "/subscriptions.v1.SubscriptionService/GetSubscription"
+		health = "/grpc.health.v1.Health/Check"
+	)
+
+	interceptor := interceptors.Skip(
+		interceptors.RequireScopes(map[string]string{read: auth.ScopeSubscriptionsRead}),
+		"grpc.health.v1.Health",
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		scopes []string
+		want   codes.Code
+	}{
+		{
+			name:   "scope granted",
+			method: read,
+			scopes: []string{auth.ScopeSubscriptionsRead},
+			want:   codes.OK,
+		},
+		{
+			name:   "scope missing",
+			method: read,
+			scopes: []string{auth.ScopeCostsRead},
+			want:   codes.PermissionDenied,
+		},
+		{
+			name:   "method without scope",
+			method: "/subscriptions.v1.SubscriptionService/PurgeSubscriptions",
+			scopes: []string{auth.ScopeAdmin},
+			want:   codes.PermissionDenied,
+		},
+		{
+			name:   "public service",
+			method: health,
+			want:   codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Scopes: tt.scopes})
+
+			var called bool
+			err := interceptor(ctx, tt.method, func(context.Context) error {
+				called = true
+				return nil
+			})
+
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s", got, tt.want)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Fatalf("called = %t, want %t", called, tt.want == codes.OK)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// Interceptor — обработчик, общий для унарных и потоковых вызовов. Он
+// получает полное имя метода (/package.Service/Method) и вызывает next с
+// контекстом, который увидит обработчик.
+type Interceptor func(ctx context.Context, method string, next func(ctx context.Context) error) error
+
+// ServerOptions собирает перехватчики в цепочки для унарных и потоковых
+// вызовов. Перехватчики вызываются в порядке перечисления.
+func ServerOptions(interceptors ...Interceptor) []grpc.ServerOption {
+	unary := make([]grpc.UnaryServerInterceptor, 0, len(interceptors))
+	stream := make([]grpc.StreamServerInterceptor, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		unary = append(unary, Unary(interceptor))
+		stream = append(stream, Stream(interceptor))
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+func Unary(interceptor Interceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		var resp any
+		err := interceptor(ctx, info.FullMethod, func(ctx context.Context) (err error) {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func Stream(interceptor Interceptor) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return interceptor(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+// Skip не применяет interceptor к методам перечисленных сервисов, например
+// к проверке здоровья и рефлексии.
+func Skip(interceptor Interceptor, services ...string) Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+		for _, skipped := range services {
+			if service == skipped {
+				return next(ctx)
+			}
+		}
+		return interceptor(ctx, method, next)
+	}
+}
+
+// serverStream подменяет контекст потока на контекст из перехватчиков.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
@@ -0,0 +1,55 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Logging пишет итог каждого вызова в логгер вызова (см. RequestID).
+func Logging() Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		start := time.Now()
+
+		err := next(ctx)
+
+		duration := time.Since(start)
+		code := status.Code(err)
+
+		logger := zerolog.Ctx(ctx)
+
+		evt := logger.Info()
+		if isServerError(code) {
+			evt = logger.Error()
+		} else if code != codes.OK {
+			evt = logger.Warn()
+		}
+
+		if p, ok := peer.FromContext(ctx); ok {
+			evt = evt.Str("ip", p.Addr.String())
+		}
+
+		evt.
+			Str("method", method).
+			Str("code", code.String()).
+			Dur("duration", duration).
+			Msg("rpc_completed")
+
+		return err
+	}
+}
+
+// isServerError отделяет ошибки сервера от ошибок в запросе клиента,
+// как статусы 5xx в HTTP.
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,25 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+type RPCObserver interface {
+	ObserveRPC(method, code string, duration time.Duration)
+}
+
+// Metrics записывает количество и длительность вызовов по полному имени
+// метода и коду статуса.
+func Metrics(observer RPCObserver) Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		start := time.Now()
+
+		err := next(ctx)
+
+		observer.ObserveRPC(method, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
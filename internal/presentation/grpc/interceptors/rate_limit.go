@@ -0,0 +1,115 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimitRule задаёт класс и лимит метода. Классы совпадают с классами
+// маршрутов HTTP API, поэтому вызовы gRPC и HTTP-запросы расходуют одни корзины.
+type RateLimitRule struct {
+	Class string
+	Limit entity.RateLimit
+}
+
+// RateLimit ограничивает частоту вызовов так же, как одноимённый middleware
+// HTTP API. Методы без правила, например проверка здоровья, не ограничиваются.
+// При превышении лимита возвращается ResourceExhausted с RetryInfo.
+// Регистрируется после Authentication и Tenant.
+func RateLimit(
+	store interfaces.RateLimitStore,
+	rules map[string]RateLimitRule,
+	logger *zerolog.Logger,
+) Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		rule, ok := rules[method]
+		if !ok {
+			return next(ctx)
+		}
+
+		if err := take(ctx, store, rule.Class+":"+rateLimitIdentity(ctx), rule.Limit, logger); err != nil {
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+// RateLimitByIP ограничивает все вызовы с IP-адреса до аутентификации так же,
+// как одноимённый middleware HTTP API. Регистрируется перед Authentication.
+func RateLimitByIP(
+	store interfaces.RateLimitStore,
+	limit entity.RateLimit,
+	logger *zerolog.Logger,
+) Interceptor {
+	return func(ctx context.Context, _ string, next func(ctx context.Context) error) error {
+		if err := take(ctx, store, "ip:"+peerIP(ctx), limit, logger); err != nil {
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+// take берёт токен из корзины key и возвращает ResourceExhausted с RetryInfo,
+// если лимит исчерпан.
+func take(
+	ctx context.Context,
+	store interfaces.RateLimitStore,
+	key string,
+	limit entity.RateLimit,
+	logger *zerolog.Logger,
+) error {
+	result, err := store.Take(ctx, key, 1, limit)
+	if err != nil {
+		// Недоступность хранилища лимитов не должна останавливать сервис.
+		logger.Error().Err(err).Str("key", key).Msg("failed to check rate limit")
+		return nil
+	}
+
+	if !result.Allowed {
+		logger.Info().Str("key", key).Msg("rate limit exceeded")
+
+		st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+		if withDetails, err := st.WithDetails(
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)},
+		); err == nil {
+			st = withDetails
+		}
+		return st.Err()
+	}
+
+	return nil
+}
+
+func rateLimitIdentity(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		if strings.HasPrefix(principal.Subject, appservice.APIKeySubjectPrefix) {
+			return principal.Subject
+		}
+		return "user:" + principal.Subject
+	}
+
+	return "ip:" + peerIP(ctx)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
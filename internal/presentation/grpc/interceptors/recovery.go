@@ -0,0 +1,29 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recovery превращает панику обработчика в статус Internal, чтобы она не
+// остановила сервер.
+func Recovery() Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				zerolog.Ctx(ctx).Error().
+					Str("method", method).
+					Interface("panic", r).
+					Bytes("stack", debug.Stack()).
+					Msg("panic recovered")
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return next(ctx)
+	}
+}
@@ -0,0 +1,43 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/noredis/subscriptions/pkg/rules"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestID принимает идентификатор вызова из метаданных x-request-id или
+// генерирует новый, возвращает его в заголовках ответа и кладёт в контекст
+// дочерний логгер с полем request_id, как одноимённый middleware HTTP API.
+func RequestID(logger *zerolog.Logger) Interceptor {
+	key := strings.ToLower(httpext.RequestIDHeader)
+
+	return func(ctx context.Context, _ string, next func(ctx context.Context) error) error {
+		id := firstValue(ctx, key)
+		if !rules.IsRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		// Ошибка означает, что заголовки уже отправлены, чего до вызова
+		// обработчика не бывает.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(key, id))
+
+		requestLogger := logger.With().Str("request_id", id).Ctx(ctx).Logger()
+		return next(requestLogger.WithContext(ctx))
+	}
+}
+
+// firstValue возвращает первое значение ключа метаданных вызова.
+func firstValue(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
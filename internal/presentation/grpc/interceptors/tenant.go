@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/rules"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Tenant определяет арендатора вызова по метаданным header так же, как
+// одноимённый middleware HTTP API. Регистрируется после Authentication.
+func Tenant(header string, logger *zerolog.Logger) Interceptor {
+	key := strings.ToLower(header)
+
+	return func(ctx context.Context, _ string, next func(ctx context.Context) error) error {
+		tenantID := firstValue(ctx, key)
+
+		if principal, ok := auth.PrincipalFrom(ctx); ok {
+			switch {
+			case principal.Tenant != "":
+				if tenantID != "" && tenantID != principal.Tenant {
+					logger.Info().
+						Str("subject", principal.Subject).
+						Str("tenant", tenantID).
+						Msg("tenant mismatch")
+					return status.Error(codes.PermissionDenied, "access to tenant denied")
+				}
+				tenantID = principal.Tenant
+			case !principal.Admin:
+				logger.Info().
+					Str("subject", principal.Subject).
+					Msg("principal without tenant")
+				return status.Error(codes.PermissionDenied, "access to tenant denied")
+			}
+		}
+
+		if tenantID == "" {
+			return status.Error(codes.InvalidArgument, key+" metadata is required")
+		}
+		if !rules.IsTenantID(tenantID) {
+			return status.Error(codes.InvalidArgument, "invalid tenant id")
+		}
+
+		return next(tenant.WithTenant(ctx, tenantID))
+	}
+}
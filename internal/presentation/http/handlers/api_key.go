@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+	"github.com/noredis/subscriptions/pkg/httpext"
+)
+
+type APIKeyHandler struct {
+	service  *appservice.APIKeyService
+	problems *httpext.ProblemRegistry
+}
+
+func NewAPIKeyHandler(
+	service *appservice.APIKeyService,
+	problems *httpext.ProblemRegistry,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:  service,
+		problems: problems,
+	}
+}
+
+func (handler *APIKeyHandler) Register(app *fiber.App) {
+	admin := middlewares.RequireScope(auth.ScopeAdmin)
+
+	app.Post("/api-keys", admin, middlewares.NoIdempotency, handler.Issue)
+	app.Get("/api-keys", admin, handler.List)
+	app.Delete("/api-keys/:id", admin, handler.Revoke)
+}
+
+// Issue выпускает API-ключ.
+//
+// @Summary      Выпустить API-ключ
+// @Description  Создаёт API-ключ с указанными правами. Ключ возвращается только в этом ответе. Заголовок Idempotency-Key не поддерживается.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.APIKeyRequest         true  "Название и права ключа"
+// @Success      201      {object}  dto.IssuedAPIKeyResponse  "Ключ выпущен"
+// @Failure      400      {object}  httpext.FiberError        "Некорректный запрос"
+// @Failure      413      {object}  httpext.FiberError        "Тело запроса слишком большое"
+// @Failure      415      {object}  httpext.FiberError        "Тело запроса не в формате JSON"
+// @Failure      422      {object}  httpext.FiberError        "Ошибка валидации"
+// @Failure      401      {object}  httpext.FiberError        "Требуется аутентификация"
+// @Failure      403      {object}  httpext.FiberError        "Доступ запрещён"
+// @Failure      429      {object}  httpext.FiberError        "Превышен лимит запросов"
+// @Failure      500      {object}  httpext.FiberError        "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /api-keys [post]
+func (handler *APIKeyHandler) Issue(c *fiber.Ctx) error {
+	req := new(dto.APIKeyRequest)
+
+	if err := httpext.DecodeJSON(c, req); err != nil {
+		return handler.error(c, err, "failed to parse api key request")
+	}
+
+	resp, err := handler.service.Issue(c.UserContext(), *req)
+	if err != nil {
+		return handler.error(c, err, "failed to issue api key")
+	}
+
+	logger(c).Info().
+		Int("id", resp.ID).
+		Str("name", resp.Name).
+		Strs("scopes", resp.Scopes).
+		Msg("api key issued")
+	return c.Status(http.StatusCreated).JSON(*resp)
+}
+
+// List возвращает все API-ключи без их значений.
+//
+// @Summary      Получить список API-ключей
+// @Description  Возвращает выпущенные API-ключи, включая отозванные.
+// @Tags         api-keys
+// @Produce      json
+// @Success      200  {array}   dto.APIKeyResponse  "Список ключей"
+// @Failure      401  {object}  httpext.FiberError  "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError  "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError  "Превышен лимит запросов"
+// @Failure      500  {object}  httpext.FiberError  "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /api-keys [get]
+func (handler *APIKeyHandler) List(c *fiber.Ctx) error {
+	resp, err := handler.service.List(c.UserContext())
+	if err != nil {
+		return handler.error(c, err, "failed to list api keys")
+	}
+
+	return c.JSON(resp)
+}
+
+// Revoke отзывает API-ключ.
+//
+// @Summary      Отозвать API-ключ
+// @Description  Отзывает API-ключ по его идентификатору.
+// @Tags         api-keys
+// @Param        id   path      int                 true  "ID ключа"
+// @Success      204  "Ключ отозван"
+// @Failure      400  {object}  httpext.FiberError  "Некорректный запрос"
+// @Failure      404  {object}  httpext.FiberError  "Ключ не найден"
+// @Failure      401  {object}  httpext.FiberError  "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError  "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError  "Превышен лимит запросов"
+// @Failure      500  {object}  httpext.FiberError  "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /api-keys/{id} [delete]
+func (handler *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return httpext.Error(c, http.StatusBadRequest, "bad request")
+	}
+
+	if err := handler.service.Revoke(c.UserContext(), id); err != nil {
+		return handler.error(c, err, "failed to revoke api key")
+	}
+
+	logger(c).Info().
+		Int("id", id).
+		Msg("api key revoked")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (handler *APIKeyHandler) error(c *fiber.Ctx, err error, err500msg string) error {
+	resp := handler.problems.Resolve(c, err)
+
+	if resp.Status == http.StatusInternalServerError {
+		logger(c).Error().Err(err).Msg(err500msg)
+	} else {
+		logger(c).Info().Err(err).Msg(resp.Body.Error)
+	}
+
+	return httpext.Send(c, resp)
+}
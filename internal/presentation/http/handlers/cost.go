@@ -1,34 +1,36 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
 	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
 	"github.com/noredis/subscriptions/pkg/httpext"
-	"github.com/rs/zerolog"
 )
 
 type CostHandler struct {
-	logger  *zerolog.Logger
-	service *appservice.CostService
+	service  *appservice.CostService
+	problems *httpext.ProblemRegistry
 }
 
 func NewCostHandler(
-	logger *zerolog.Logger,
 	service *appservice.CostService,
+	problems *httpext.ProblemRegistry,
 ) *CostHandler {
 	return &CostHandler{
-		logger:  logger,
-		service: service,
+		service:  service,
+		problems: problems,
 	}
 }
 
 func (handler *CostHandler) Register(app *fiber.App) {
-	app.Get("/costs/total", handler.Total)
+	read := middlewares.RequireScope(auth.ScopeCostsRead)
+
+	app.Get("/costs/total", read, handler.Total)
+	app.Get("/costs/breakdown", read, handler.Breakdown)
 }
 
 // Total возвращает суммарную стоимость подписок.
@@ -44,7 +46,11 @@ func (handler *CostHandler) Register(app *fiber.App) {
 // @Success      200  {object}  dto.TotalCostResponse  "Суммарная стоимость"
 // @Failure      400  {object}  httpext.FiberError     "Некорректный запрос"
 // @Failure      422  {object}  httpext.FiberError     "Ошибка валидации"
+// @Failure      401  {object}  httpext.FiberError     "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError     "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError     "Превышен лимит запросов"
 // @Failure      500  {object}  httpext.FiberError     "Внутренняя ошибка сервера"
+// @Security     BearerAuth
 // @Router       /cost/total [get]
 func (handler *CostHandler) Total(c *fiber.Ctx) error {
 	filters := dto.CostFilterDTO{
@@ -54,23 +60,57 @@ func (handler *CostHandler) Total(c *fiber.Ctx) error {
 		EndDate:     c.Query("end_date"),
 	}
 
-	cost, err := handler.service.Total(c.Context(), filters)
+	cost, err := handler.service.Total(c.UserContext(), filters)
 	if err != nil {
-		return handler.error(c, err)
+		return handler.error(c, err, "failed to calculate cost")
 	}
 
 	return c.Status(http.StatusOK).JSON(*cost)
 }
 
-func (handler *CostHandler) error(c *fiber.Ctx, err error) error {
-	var vErrs validator.ValidationErrors
+// Breakdown возвращает стоимость каждой подписки за период.
+//
+// @Summary      Получить стоимость подписок по отдельности
+// @Description  Возвращает стоимость каждой подписки за период с учётом фильтров.
+// @Tags         cost
+// @Produce      json
+// @Param        service_name  query     string  false  "Фильтр по имени сервиса"
+// @Param        user_id       query     string  false  "Фильтр по ID пользователя"
+// @Param        start_date    query     string  false  "Дата начала (MM-YYYY)"
+// @Param        end_date      query     string  false  "Дата окончания (MM-YYYY)"
+// @Success      200  {object}  dto.CostBreakdownResponse  "Стоимость по подпискам"
+// @Failure      400  {object}  httpext.FiberError         "Некорректный запрос"
+// @Failure      422  {object}  httpext.FiberError         "Ошибка валидации"
+// @Failure      401  {object}  httpext.FiberError         "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError         "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError         "Превышен лимит запросов"
+// @Failure      500  {object}  httpext.FiberError         "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /costs/breakdown [get]
+func (handler *CostHandler) Breakdown(c *fiber.Ctx) error {
+	filters := dto.CostFilterDTO{
+		ServiceName: c.Query("service_name"),
+		UserID:      c.Query("user_id"),
+		StartDate:   c.Query("start_date"),
+		EndDate:     c.Query("end_date"),
+	}
+
+	breakdown, err := handler.service.Breakdown(c.UserContext(), filters)
+	if err != nil {
+		return handler.error(c, err, "failed to calculate cost")
+	}
+
+	return c.Status(http.StatusOK).JSON(*breakdown)
+}
 
-	switch {
-	case errors.As(err, &vErrs):
-		handler.logger.Info().Err(err).Msg("validation failed")
-		return httpext.ValidationError(c, vErrs)
-	default:
-		handler.logger.Error().Err(err).Msg("failed to calculate total cost")
-		return httpext.Error(c, http.StatusInternalServerError, "internal server error")
+func (handler *CostHandler) error(c *fiber.Ctx, err error, err500msg string) error {
+	resp := handler.problems.Resolve(c, err)
+
+	if resp.Status == http.StatusInternalServerError {
+		logger(c).Error().Err(err).Msg(err500msg)
+	} else {
+		logger(c).Info().Err(err).Msg(resp.Body.Error)
 	}
+
+	return httpext.Send(c, resp)
 }
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/presentation/graphql"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+	"github.com/noredis/subscriptions/pkg/httpext"
+)
+
+type GraphQLHandler struct {
+	executor *graphql.Executor
+	problems *httpext.ProblemRegistry
+}
+
+func NewGraphQLHandler(
+	executor *graphql.Executor,
+	problems *httpext.ProblemRegistry,
+) *GraphQLHandler {
+	return &GraphQLHandler{
+		executor: executor,
+		problems: problems,
+	}
+}
+
+// Register добавляет /graphql. Права проверяются на каждом поле схемы, а не
+// на маршруте: один запрос может читать и подписки, и стоимость.
+func (handler *GraphQLHandler) Register(app *fiber.App) {
+	app.Get("/graphql", handler.Get)
+	app.Post("/graphql", handler.Post)
+}
+
+// Post выполняет запрос GraphQL.
+//
+// @Summary      Выполнить запрос GraphQL
+// @Description  Выполняет запрос к типам Subscription, User и Cost. Запрос отклоняется до выполнения,
+// @Description  если его сложность превышает GRAPHQL_MAX_COMPLEXITY. Ошибки полей возвращаются в errors
+// @Description  со статусом 200, их extensions содержат code, status и fields, как ошибки REST API.
+// @Description  Каждое поле cost у Query и User расходует лимит запросов к /costs.
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.GraphQLRequest   true  "Запрос"
+// @Success      200      {object}  dto.GraphQLResponse  "Результат запроса"
+// @Failure      400      {object}  httpext.FiberError   "Некорректный запрос"
+// @Failure      401      {object}  httpext.FiberError   "Требуется аутентификация"
+// @Failure      415      {object}  httpext.FiberError   "Неподдерживаемый формат тела запроса"
+// @Failure      429      {object}  httpext.FiberError   "Превышен лимит запросов"
+// @Security     BearerAuth
+// @Router       /graphql [post]
+func (handler *GraphQLHandler) Post(c *fiber.Ctx) error {
+	req := new(dto.GraphQLRequest)
+
+	if err := httpext.DecodeJSON(c, req); err != nil {
+		return handler.error(c, err, "failed to parse graphql request")
+	}
+
+	return handler.execute(c, *req)
+}
+
+// Get выполняет запрос GraphQL из параметров строки запроса.
+//
+// @Summary      Выполнить запрос GraphQL
+// @Description  То же, что POST /graphql, для запросов, которые удобно кэшировать.
+// @Tags         graphql
+// @Produce      json
+// @Param        query          query     string  true   "Запрос"
+// @Param        operationName  query     string  false  "Имя операции"
+// @Param        variables      query     string  false  "Переменные (JSON-объект)"
+// @Success      200  {object}  dto.GraphQLResponse  "Результат запроса"
+// @Failure      400  {object}  httpext.FiberError   "Некорректный запрос"
+// @Failure      401  {object}  httpext.FiberError   "Требуется аутентификация"
+// @Failure      429  {object}  httpext.FiberError   "Превышен лимит запросов"
+// @Security     BearerAuth
+// @Router       /graphql [get]
+func (handler *GraphQLHandler) Get(c *fiber.Ctx) error {
+	req := dto.GraphQLRequest{
+		Query:         c.Query("query"),
+		OperationName: c.Query("operationName"),
+	}
+
+	if variables := c.Query("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return handler.error(
+				c,
+				&httpext.DecodeError{Message: "variables must be a JSON object"},
+				"failed to parse graphql variables",
+			)
+		}
+	}
+
+	return handler.execute(c, req)
+}
+
+func (handler *GraphQLHandler) execute(c *fiber.Ctx, req dto.GraphQLRequest) error {
+	if req.Query == "" {
+		return handler.error(c, &httpext.DecodeError{Message: "query is required"}, "empty graphql query")
+	}
+
+	query, result := handler.executor.Prepare(req)
+	if result == nil {
+		// Поле cost стоит столько же, сколько запрос к /costs.
+		if allowed, err := middlewares.TakeCostsRateLimit(c, query.Costs); !allowed {
+			return err
+		}
+		result = handler.executor.Execute(c.UserContext(), query)
+	}
+
+	resp := dto.GraphQLResponse{Data: result.Data}
+	for _, gqlErr := range result.Errors {
+		respErr := dto.GraphQLError{
+			Message:    gqlErr.Message,
+			Path:       gqlErr.Path,
+			Extensions: gqlErr.Extensions,
+		}
+		for _, loc := range gqlErr.Locations {
+			respErr.Locations = append(respErr.Locations, dto.GraphQLLocation{Line: loc.Line, Column: loc.Column})
+		}
+
+		// Ошибки резолверов описываются по тому же реестру, что и ошибки REST API.
+		if err := graphql.OriginalError(gqlErr); err != nil {
+			problem := handler.problems.Resolve(c, err)
+			if problem.Status == http.StatusInternalServerError {
+				logger(c).Error().Err(err).Interface("path", gqlErr.Path).Msg("failed to resolve graphql field")
+			}
+
+			respErr.Message = problem.Body.Error
+			respErr.Extensions = map[string]any{
+				"code":   problem.Problem.Code,
+				"status": problem.Status,
+			}
+			if len(problem.Body.Fields) > 0 {
+				respErr.Extensions["fields"] = problem.Body.Fields
+			}
+		}
+
+		resp.Errors = append(resp.Errors, respErr)
+	}
+
+	if len(resp.Errors) > 0 {
+		logger(c).Info().Int("errors", len(resp.Errors)).Msg(resp.Errors[0].Message)
+	}
+
+	return c.Status(http.StatusOK).JSON(resp)
+}
+
+func (handler *GraphQLHandler) error(c *fiber.Ctx, err error, err500msg string) error {
+	resp := handler.problems.Resolve(c, err)
+
+	if resp.Status == http.StatusInternalServerError {
+		logger(c).Error().Err(err).Msg(err500msg)
+	} else {
+		logger(c).Info().Err(err).Msg(resp.Body.Error)
+	}
+
+	return httpext.Send(c, resp)
+}
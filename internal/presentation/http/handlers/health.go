@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+)
+
+type HealthHandler struct {
+	service *appservice.HealthService
+}
+
+func NewHealthHandler(service *appservice.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+func (handler *HealthHandler) Register(app *fiber.App) {
+	app.Get("/health/live", handler.Live)
+	app.Get("/health/ready", handler.Ready)
+}
+
+// Live проверяет, что процесс сервиса работает.
+//
+// @Summary      Liveness-проба
+// @Description  Возвращает 200 OK, пока процесс сервиса работает. Зависимости не проверяются.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  dto.HealthResponse
+// @Router       /health/live [get]
+func (handler *HealthHandler) Live(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(handler.service.Live())
+}
+
+// Ready проверяет, готов ли сервис принимать запросы.
+//
+// @Summary      Readiness-проба
+// @Description  Проверяет соединение с БД и версию схемы. Возвращает 503, если хотя бы одна
+// @Description  зависимость недоступна или сервис останавливается.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  dto.HealthResponse
+// @Failure      503  {object}  dto.HealthResponse
+// @Router       /health/ready [get]
+func (handler *HealthHandler) Ready(c *fiber.Ctx) error {
+	resp, ready := handler.service.Ready(c.UserContext())
+	if !ready {
+		return c.Status(http.StatusServiceUnavailable).JSON(resp)
+	}
+
+	return c.Status(http.StatusOK).JSON(resp)
+}
@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+// logger возвращает логгер запроса с его идентификатором.
+func logger(c *fiber.Ctx) *zerolog.Logger {
+	return zerolog.Ctx(c.UserContext())
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/pkg/httpext"
+)
+
+// NewProblemRegistry сопоставляет ошибки предметной области с типами проблем
+// RFC 7807. Здесь же задаётся HTTP-статус каждой ошибки для прежнего формата
+// и её текст на русском языке.
+func NewProblemRegistry(baseURI string, uni *ut.UniversalTranslator) *httpext.ProblemRegistry {
+	return httpext.NewProblemRegistry(baseURI, uni).
+		Register(
+			failure.ErrSubscriptionNotFound,
+			http.StatusNotFound, "subscription_not_found", "Subscription not found",
+		).
+		Register(
+			failure.ErrUserAlreadyHasThisSubscription,
+			http.StatusConflict, "subscription_already_exists", "Subscription already exists",
+		).
+		Register(
+			failure.ErrBatchRolledBack,
+			http.StatusFailedDependency, "batch_rolled_back", "Batch operation rolled back",
+		).
+		Register(
+			failure.ErrAPIKeyNotFound,
+			http.StatusNotFound, "api_key_not_found", "API key not found",
+		).
+		Register(
+			failure.ErrInvalidAPIKey,
+			http.StatusUnauthorized, "invalid_api_key", "Invalid API key",
+		).
+		Register(
+			failure.ErrAPIKeyTenantRequired,
+			http.StatusUnprocessableEntity, "api_key_tenant_required", "API key tenant is required",
+		).
+		Register(
+			failure.ErrWebhookNotFound,
+			http.StatusNotFound, "webhook_not_found", "Webhook not found",
+		).
+		Register(
+			failure.ErrTooManyStreams,
+			http.StatusTooManyRequests, "too_many_streams", "Too many open streams",
+		).
+		Register(
+			failure.ErrForbidden,
+			http.StatusForbidden, "access_denied", "Access denied",
+		).
+		Messages("ru", map[string]string{
+			"validation_error":            "ошибка валидации",
+			"invalid_body":                "некорректное тело запроса",
+			"unsupported_media_type":      "тело запроса должно быть в формате application/json",
+			httpext.FieldErrorUnknown:     "неизвестное поле {0}",
+			httpext.FieldErrorInvalidType: "{0} должен иметь тип {1}",
+			"internal_error":              "внутренняя ошибка сервера",
+			"subscription_not_found":      "подписка не найдена",
+			"subscription_already_exists": "у пользователя уже есть подписка на этот сервис",
+			"batch_rolled_back":           "операция отменена, потому что другая операция пакета завершилась ошибкой",
+			"api_key_not_found":           "API-ключ не найден",
+			"invalid_api_key":             "неверный API-ключ",
+			"api_key_tenant_required":     "для API-ключа нужно указать арендатора",
+			"webhook_not_found":           "вебхук не найден",
+			"too_many_streams":            "открыто слишком много потоков",
+			"access_denied":               "доступ запрещён",
+		})
+}
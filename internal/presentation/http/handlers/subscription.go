@@ -1,41 +1,55 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
 	"github.com/noredis/subscriptions/internal/application/dto"
 	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
 	"github.com/noredis/subscriptions/pkg/httpext"
-	"github.com/rs/zerolog"
 )
 
 type SubscriptionHandler struct {
-	service *appservice.SubscriptionService
-	logger  *zerolog.Logger
+	service   *appservice.SubscriptionService
+	streams   *appservice.SubscriptionStreamService
+	heartbeat time.Duration
+	problems  *httpext.ProblemRegistry
 }
 
 func NewSubscriptionHandler(
 	service *appservice.SubscriptionService,
-	logger *zerolog.Logger,
+	streams *appservice.SubscriptionStreamService,
+	heartbeat time.Duration,
+	problems *httpext.ProblemRegistry,
 ) *SubscriptionHandler {
 	return &SubscriptionHandler{
-		service: service,
-		logger:  logger,
+		service:   service,
+		streams:   streams,
+		heartbeat: heartbeat,
+		problems:  problems,
 	}
 }
 
 func (handler *SubscriptionHandler) Register(app *fiber.App) {
-	app.Post("/subscriptions", handler.Create)
-	app.Put("/subscriptions/:id", handler.Update)
-	app.Delete("/subscriptions/:id", handler.Delete)
-	app.Get("/subscriptions/:id", handler.Index)
-	app.Get("/subscriptions", handler.List)
+	read := middlewares.RequireScope(auth.ScopeSubscriptionsRead)
+	write := middlewares.RequireScope(auth.ScopeSubscriptionsWrite)
+
+	app.Post("/subscriptions", write, handler.Create)
+	app.Post("/subscriptions/batch", write, handler.Batch)
+	app.Put("/subscriptions/:id", write, handler.Update)
+	app.Delete("/subscriptions/:id", write, handler.Delete)
+	app.Get("/subscriptions/stream", read, handler.Stream)
+	app.Get("/subscriptions/:id", read, handler.Index)
+	app.Get("/subscriptions", read, handler.List)
 }
 
 // Create создаёт новую подписку.
@@ -46,26 +60,32 @@ func (handler *SubscriptionHandler) Register(app *fiber.App) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      dto.SubscriptionRequest   true  "Данные для создания подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности"
 // @Success      201      {object}  dto.SubscriptionResponse  "Подписка успешно создана"
 // @Failure      400      {object}  httpext.FiberError        "Некорректный запрос"
+// @Failure      413      {object}  httpext.FiberError        "Тело запроса слишком большое"
+// @Failure      415      {object}  httpext.FiberError        "Тело запроса не в формате JSON"
 // @Failure      409      {object}  httpext.FiberError        "Подписка уже существует"
 // @Failure      422      {object}  httpext.FiberError        "Ошибка валидации"
+// @Failure      401      {object}  httpext.FiberError        "Требуется аутентификация"
+// @Failure      403      {object}  httpext.FiberError        "Доступ запрещён"
+// @Failure      429      {object}  httpext.FiberError        "Превышен лимит запросов"
 // @Failure      500      {object}  httpext.FiberError        "Внутренняя ошибка сервера"
+// @Security     BearerAuth
 // @Router       /subscriptions [post]
 func (handler *SubscriptionHandler) Create(c *fiber.Ctx) error {
 	req := new(dto.SubscriptionRequest)
 
-	if err := c.BodyParser(req); err != nil {
-		handler.logger.Warn().Err(err).Msg("failed to parse subscription request")
-		return httpext.Error(c, http.StatusBadRequest, "bad request")
+	if err := httpext.DecodeJSON(c, req); err != nil {
+		return handler.error(c, err, "failed to parse subscription request")
 	}
 
-	resp, err := handler.service.Create(c.Context(), *req)
+	resp, err := handler.service.Create(c.UserContext(), *req)
 	if err != nil {
 		return handler.error(c, err, "failed to create subscription")
 	}
 
-	handler.logger.Info().
+	logger(c).Info().
 		Int("id", resp.ID).
 		Str("service_name", resp.ServiceName).
 		Str("user_id", resp.UserID).
@@ -74,6 +94,79 @@ func (handler *SubscriptionHandler) Create(c *fiber.Ctx) error {
 	return c.Status(http.StatusCreated).JSON(*resp)
 }
 
+// Batch выполняет набор операций над подписками в одной транзакции.
+//
+// @Summary      Пакетное изменение подписок
+// @Description  Выполняет операции create/update/delete в одной транзакции.
+// @Description  При ошибке любой операции все изменения откатываются, а статус ответа
+// @Description  совпадает со статусом неуспешной операции.
+// @Description  Пакет содержит до 10000 операций; для крупных пакетов может понадобиться
+// @Description  увеличить APP_BODY_LIMIT.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.BatchRequest    true  "Список операций"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности"
+// @Success      200      {object}  dto.BatchResponse   "Все операции выполнены"
+// @Failure      400      {object}  httpext.FiberError  "Некорректный запрос"
+// @Failure      413      {object}  httpext.FiberError  "Тело запроса слишком большое"
+// @Failure      415      {object}  httpext.FiberError  "Тело запроса не в формате JSON"
+// @Failure      404      {object}  dto.BatchResponse   "Подписка не найдена"
+// @Failure      409      {object}  dto.BatchResponse   "Подписка уже существует"
+// @Failure      422      {object}  dto.BatchResponse   "Ошибка валидации"
+// @Failure      401      {object}  httpext.FiberError  "Требуется аутентификация"
+// @Failure      403      {object}  httpext.FiberError  "Доступ запрещён"
+// @Failure      429      {object}  httpext.FiberError  "Превышен лимит запросов"
+// @Failure      500      {object}  httpext.FiberError  "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /subscriptions/batch [post]
+func (handler *SubscriptionHandler) Batch(c *fiber.Ctx) error {
+	req := new(dto.BatchRequest)
+
+	if err := httpext.DecodeJSON(c, req); err != nil {
+		return handler.error(c, err, "failed to parse batch request")
+	}
+
+	result, err := handler.service.Batch(c.UserContext(), *req)
+	if err != nil {
+		return handler.error(c, err, "failed to execute batch")
+	}
+
+	status := http.StatusOK
+	resp := dto.BatchResponse{
+		Committed: result.Committed,
+		Results:   make([]dto.BatchOperationResponse, len(result.Operations)),
+	}
+
+	for i, op := range result.Operations {
+		opResp := dto.BatchOperationResponse{
+			Index:  i,
+			Op:     op.Op,
+			Status: batchSuccessStatus(op.Op),
+			Data:   op.Data,
+		}
+
+		if op.Err != nil {
+			errResp := handler.problems.Resolve(c, op.Err)
+			opResp.Status = errResp.Status
+			opResp.Error = &errResp.Body
+
+			if !errors.Is(op.Err, failure.ErrBatchRolledBack) {
+				status = errResp.Status
+				logger(c).Info().Err(op.Err).Int("index", i).Msg("batch operation failed")
+			}
+		}
+
+		resp.Results[i] = opResp
+	}
+
+	logger(c).Info().
+		Int("operations", len(resp.Results)).
+		Bool("committed", resp.Committed).
+		Msg("batch executed")
+	return c.Status(status).JSON(resp)
+}
+
 // Update обновляет данные подписки.
 //
 // @Summary      Обновить подписку
@@ -83,19 +176,25 @@ func (handler *SubscriptionHandler) Create(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        id       path      int                        true  "ID подписки"
 // @Param        request  body      dto.SubscriptionRequest    true  "Данные для обновления подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности"
 // @Success      200      {object}  dto.SubscriptionResponse   "Подписка успешно обновлена"
 // @Failure      400      {object}  httpext.FiberError         "Некорректный запрос"
+// @Failure      413      {object}  httpext.FiberError         "Тело запроса слишком большое"
+// @Failure      415      {object}  httpext.FiberError         "Тело запроса не в формате JSON"
 // @Failure      404      {object}  httpext.FiberError         "Подписка не найдена"
 // @Failure      409      {object}  httpext.FiberError         "Подписка уже существует"
 // @Failure      422      {object}  httpext.FiberError         "Ошибка валидации"
+// @Failure      401      {object}  httpext.FiberError         "Требуется аутентификация"
+// @Failure      403      {object}  httpext.FiberError         "Доступ запрещён"
+// @Failure      429      {object}  httpext.FiberError         "Превышен лимит запросов"
 // @Failure      500      {object}  httpext.FiberError         "Внутренняя ошибка сервера"
+// @Security     BearerAuth
 // @Router       /subscriptions/{id} [put]
 func (handler *SubscriptionHandler) Update(c *fiber.Ctx) error {
 	req := new(dto.SubscriptionRequest)
 
-	if err := c.BodyParser(req); err != nil {
-		handler.logger.Warn().Err(err).Msg("failed to parse subscription request")
-		return httpext.Error(c, http.StatusBadRequest, "bad request")
+	if err := httpext.DecodeJSON(c, req); err != nil {
+		return handler.error(c, err, "failed to parse subscription request")
 	}
 
 	idParam := c.Params("id")
@@ -104,12 +203,12 @@ func (handler *SubscriptionHandler) Update(c *fiber.Ctx) error {
 		return httpext.Error(c, http.StatusBadRequest, "bad request")
 	}
 
-	resp, err := handler.service.Update(c.Context(), *req, id)
+	resp, err := handler.service.Update(c.UserContext(), *req, id)
 	if err != nil {
 		return handler.error(c, err, "failed to update subscription")
 	}
 
-	handler.logger.Info().
+	logger(c).Info().
 		Int("id", resp.ID).
 		Str("service_name", resp.ServiceName).
 		Str("user_id", resp.UserID).
@@ -123,10 +222,15 @@ func (handler *SubscriptionHandler) Update(c *fiber.Ctx) error {
 // @Description  Удаляет подписку по её идентификатору.
 // @Tags         subscriptions
 // @Param        id   path      int                   true  "ID подписки"
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности"
 // @Success      204  "Подписка успешно удалена"
 // @Failure      400  {object}  httpext.FiberError    "Некорректный запрос"
 // @Failure      404  {object}  httpext.FiberError    "Подписка не найдена"
+// @Failure      401  {object}  httpext.FiberError    "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError    "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError    "Превышен лимит запросов"
 // @Failure      500  {object}  httpext.FiberError    "Внутренняя ошибка сервера"
+// @Security     BearerAuth
 // @Router       /subscriptions/{id} [delete]
 func (handler *SubscriptionHandler) Delete(c *fiber.Ctx) error {
 	idParam := c.Params("id")
@@ -135,12 +239,12 @@ func (handler *SubscriptionHandler) Delete(c *fiber.Ctx) error {
 		return httpext.Error(c, http.StatusBadRequest, "bad request")
 	}
 
-	err = handler.service.Delete(c.Context(), id)
+	err = handler.service.Delete(c.UserContext(), id)
 	if err != nil {
 		return handler.error(c, err, "failed to delete subscription")
 	}
 
-	handler.logger.Info().
+	logger(c).Info().
 		Int("id", id).
 		Msg("subscription deleted")
 	return c.SendStatus(http.StatusNoContent)
@@ -156,7 +260,11 @@ func (handler *SubscriptionHandler) Delete(c *fiber.Ctx) error {
 // @Success      200  {object}  dto.SubscriptionResponse  "Данные подписки"
 // @Failure      400  {object}  httpext.FiberError        "Некорректный идентификатор"
 // @Failure      404  {object}  httpext.FiberError        "Подписка не найдена"
+// @Failure      401  {object}  httpext.FiberError        "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError        "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError        "Превышен лимит запросов"
 // @Failure      500  {object}  httpext.FiberError        "Внутренняя ошибка сервера"
+// @Security     BearerAuth
 // @Router       /subscriptions/{id} [get]
 func (handler *SubscriptionHandler) Index(c *fiber.Ctx) error {
 	idParam := c.Params("id")
@@ -165,7 +273,7 @@ func (handler *SubscriptionHandler) Index(c *fiber.Ctx) error {
 		return httpext.Error(c, http.StatusBadRequest, "bad request")
 	}
 
-	resp, err := handler.service.Index(c.Context(), id)
+	resp, err := handler.service.Index(c.UserContext(), id)
 	if err != nil {
 		return handler.error(c, err, "failed to index subscription")
 	}
@@ -188,7 +296,11 @@ func (handler *SubscriptionHandler) Index(c *fiber.Ctx) error {
 // @Success      200  {object}  dto.SubscriptionListResponse  "Список подписок"
 // @Failure      400  {object}  httpext.FiberError            "Некорректный запрос"
 // @Failure      422  {object}  httpext.FiberError            "Ошибка валидации"
+// @Failure      401  {object}  httpext.FiberError            "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError            "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError            "Превышен лимит запросов"
 // @Failure      500  {object}  httpext.FiberError            "Внутренняя ошибка сервера"
+// @Security     BearerAuth
 // @Router       /subscriptions [get]
 func (handler *SubscriptionHandler) List(c *fiber.Ctx) error {
 	page := c.QueryInt("page", 1)
@@ -203,7 +315,7 @@ func (handler *SubscriptionHandler) List(c *fiber.Ctx) error {
 		EndDate:     c.Query("end_date"),
 	}
 
-	resp, err := handler.service.List(c.Context(), filters)
+	resp, err := handler.service.List(c.UserContext(), filters)
 	if err != nil {
 		return handler.error(c, err, "failed to list subscriptions")
 	}
@@ -211,21 +323,125 @@ func (handler *SubscriptionHandler) List(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(*resp)
 }
 
+// Stream отправляет изменения подписок в формате Server-Sent Events.
+//
+// @Summary      Поток изменений подписок
+// @Description  Отправляет события subscription.created, subscription.updated и subscription.deleted
+// @Description  в формате Server-Sent Events по мере фиксации изменений. Поле id события - его номер:
+// @Description  после переподключения клиент передаёт его в заголовке Last-Event-ID и получает
+// @Description  пропущенные события. Без заголовка отправляются только новые изменения.
+// @Description  Раз в STREAM_HEARTBEAT отправляется комментарий, чтобы соединение не закрывалось.
+// @Description  Поток закрывается, когда истекает JWT или отзывается API-ключ. Одновременно
+// @Description  открыто не больше STREAM_MAX_PER_PRINCIPAL потоков одного пользователя.
+// @Tags         subscriptions
+// @Produce      text/event-stream
+// @Param        service_name   query     string  false  "Фильтр по имени сервиса"
+// @Param        user_id        query     string  false  "Фильтр по ID пользователя"
+// @Param        Last-Event-ID  header    int     false  "Номер последнего полученного события"
+// @Success      200  {object}  dto.Event           "Поток событий"
+// @Failure      400  {object}  httpext.FiberError  "Некорректный запрос"
+// @Failure      401  {object}  httpext.FiberError  "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError  "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError  "Превышен лимит запросов или открыто слишком много потоков"
+// @Failure      500  {object}  httpext.FiberError  "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /subscriptions/stream [get]
+func (handler *SubscriptionHandler) Stream(c *fiber.Ctx) error {
+	var lastEventID *int64
+	if header := c.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id < 0 {
+			return httpext.Error(c, http.StatusBadRequest, "bad request")
+		}
+		lastEventID = &id
+	}
+
+	filters := dto.SubscriptionStreamFilterDTO{
+		ServiceName: c.Query("service_name"),
+		UserID:      c.Query("user_id"),
+	}
+
+	// Тело ответа пишется после выхода из обработчика, поэтому поток
+	// не должен завершаться вместе с запросом. Срок действия учётных данных
+	// пользователя из контекста проверяет SubscriptionStreamService.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.UserContext()))
+
+	events, err := handler.streams.Subscribe(ctx, filters, lastEventID)
+	if err != nil {
+		cancel()
+		return handler.error(c, err, "failed to open subscription stream")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	log := logger(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		if err := writeStream(w, events, handler.heartbeat); err != nil {
+			log.Debug().Err(err).Msg("subscription stream closed by client")
+		}
+	})
+	return nil
+}
+
+// writeStream пишет события до закрытия канала или ошибки записи, которая
+// означает, что клиент отключился.
+func writeStream(w *bufio.Writer, events <-chan dto.StreamEvent, heartbeat time.Duration) error {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	// Комментарий сразу отправляет клиенту заголовки ответа.
+	if _, err := w.WriteString(": connected\n\n"); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if _, err := w.WriteString(": ping\n\n"); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
 func (handler *SubscriptionHandler) error(c *fiber.Ctx, err error, err500msg string) error {
-	var vErrs validator.ValidationErrors
-
-	switch {
-	case errors.As(err, &vErrs):
-		handler.logger.Info().Err(err).Msg("validation for subscription failed")
-		return httpext.ValidationError(c, vErrs)
-	case errors.Is(err, failure.ErrUserAlreadyHasThisSubscription):
-		handler.logger.Info().Err(err).Msg("subscription already exists")
-		return httpext.Error(c, http.StatusConflict, err.Error())
-	case errors.Is(err, failure.ErrSubscriptionNotFound):
-		handler.logger.Info().Err(err).Msg("subscription not found")
-		return httpext.Error(c, http.StatusNotFound, err.Error())
+	resp := handler.problems.Resolve(c, err)
+
+	if resp.Status == http.StatusInternalServerError {
+		logger(c).Error().Err(err).Msg(err500msg)
+	} else {
+		logger(c).Info().Err(err).Msg(resp.Body.Error)
+	}
+
+	return httpext.Send(c, resp)
+}
+
+func batchSuccessStatus(op string) int {
+	switch op {
+	case dto.BatchOpCreate:
+		return http.StatusCreated
+	case dto.BatchOpDelete:
+		return http.StatusNoContent
 	default:
-		handler.logger.Error().Err(err).Msg(err500msg)
-		return httpext.Error(c, http.StatusInternalServerError, "internal server error")
+		return http.StatusOK
 	}
 }
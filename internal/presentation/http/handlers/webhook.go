@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/application/dto"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+	"github.com/noredis/subscriptions/pkg/httpext"
+)
+
+type WebhookHandler struct {
+	service  *appservice.WebhookService
+	problems *httpext.ProblemRegistry
+}
+
+func NewWebhookHandler(
+	service *appservice.WebhookService,
+	problems *httpext.ProblemRegistry,
+) *WebhookHandler {
+	return &WebhookHandler{
+		service:  service,
+		problems: problems,
+	}
+}
+
+func (handler *WebhookHandler) Register(app *fiber.App) {
+	admin := middlewares.RequireScope(auth.ScopeAdmin)
+
+	app.Post("/webhooks", admin, middlewares.NoIdempotency, handler.Create)
+	app.Get("/webhooks", admin, handler.List)
+	app.Delete("/webhooks/:id", admin, handler.Delete)
+	app.Get("/webhooks/:id/deliveries", admin, handler.Deliveries)
+}
+
+// Create регистрирует вебхук.
+//
+// @Summary      Зарегистрировать вебхук
+// @Description  Регистрирует адрес, на который отправляются события указанных типов: subscription.created, subscription.updated, subscription.deleted, subscription.ending_soon.
+// @Description  Запросы подписываются HMAC-SHA256 секретом вебхука. Если секрет не указан, он генерируется. Секрет возвращается только в этом ответе. Заголовок Idempotency-Key не поддерживается.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.WebhookRequest          true  "Адрес, события и секрет вебхука"
+// @Success      201      {object}  dto.CreatedWebhookResponse  "Вебхук зарегистрирован"
+// @Failure      400      {object}  httpext.FiberError          "Некорректный запрос"
+// @Failure      413      {object}  httpext.FiberError          "Тело запроса слишком большое"
+// @Failure      415      {object}  httpext.FiberError          "Тело запроса не в формате JSON"
+// @Failure      422      {object}  httpext.FiberError          "Ошибка валидации"
+// @Failure      401      {object}  httpext.FiberError          "Требуется аутентификация"
+// @Failure      403      {object}  httpext.FiberError          "Доступ запрещён"
+// @Failure      429      {object}  httpext.FiberError          "Превышен лимит запросов"
+// @Failure      500      {object}  httpext.FiberError          "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /webhooks [post]
+func (handler *WebhookHandler) Create(c *fiber.Ctx) error {
+	req := new(dto.WebhookRequest)
+
+	if err := httpext.DecodeJSON(c, req); err != nil {
+		return handler.error(c, err, "failed to parse webhook request")
+	}
+
+	resp, err := handler.service.Register(c.UserContext(), *req)
+	if err != nil {
+		return handler.error(c, err, "failed to register webhook")
+	}
+
+	logger(c).Info().
+		Int("id", resp.ID).
+		Str("url", resp.URL).
+		Strs("events", resp.Events).
+		Msg("webhook registered")
+	return c.Status(http.StatusCreated).JSON(*resp)
+}
+
+// List возвращает вебхуки арендатора без их секретов.
+//
+// @Summary      Получить список вебхуков
+// @Description  Возвращает зарегистрированные вебхуки арендатора без их секретов.
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {array}   dto.WebhookResponse  "Список вебхуков"
+// @Failure      401  {object}  httpext.FiberError   "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError   "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError   "Превышен лимит запросов"
+// @Failure      500  {object}  httpext.FiberError   "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /webhooks [get]
+func (handler *WebhookHandler) List(c *fiber.Ctx) error {
+	resp, err := handler.service.List(c.UserContext())
+	if err != nil {
+		return handler.error(c, err, "failed to list webhooks")
+	}
+
+	return c.JSON(resp)
+}
+
+// Delete удаляет вебхук.
+//
+// @Summary      Удалить вебхук
+// @Description  Удаляет вебхук вместе с журналом его доставок. Неотправленные события отменяются.
+// @Tags         webhooks
+// @Param        id   path      int                 true  "ID вебхука"
+// @Success      204  "Вебхук удалён"
+// @Failure      400  {object}  httpext.FiberError  "Некорректный запрос"
+// @Failure      404  {object}  httpext.FiberError  "Вебхук не найден"
+// @Failure      401  {object}  httpext.FiberError  "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError  "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError  "Превышен лимит запросов"
+// @Failure      500  {object}  httpext.FiberError  "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /webhooks/{id} [delete]
+func (handler *WebhookHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return httpext.Error(c, http.StatusBadRequest, "bad request")
+	}
+
+	if err := handler.service.Delete(c.UserContext(), id); err != nil {
+		return handler.error(c, err, "failed to delete webhook")
+	}
+
+	logger(c).Info().
+		Int("id", id).
+		Msg("webhook deleted")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Deliveries возвращает журнал доставок вебхука.
+//
+// @Summary      Получить журнал доставок вебхука
+// @Description  Возвращает последние 100 доставок вебхука, начиная с новых: статус (pending, succeeded, failed), число попыток, HTTP-статус последнего ответа и ошибку.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      int                          true  "ID вебхука"
+// @Success      200  {array}   dto.WebhookDeliveryResponse  "Журнал доставок"
+// @Failure      400  {object}  httpext.FiberError           "Некорректный запрос"
+// @Failure      404  {object}  httpext.FiberError           "Вебхук не найден"
+// @Failure      401  {object}  httpext.FiberError           "Требуется аутентификация"
+// @Failure      403  {object}  httpext.FiberError           "Доступ запрещён"
+// @Failure      429  {object}  httpext.FiberError           "Превышен лимит запросов"
+// @Failure      500  {object}  httpext.FiberError           "Внутренняя ошибка сервера"
+// @Security     BearerAuth
+// @Router       /webhooks/{id}/deliveries [get]
+func (handler *WebhookHandler) Deliveries(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return httpext.Error(c, http.StatusBadRequest, "bad request")
+	}
+
+	resp, err := handler.service.Deliveries(c.UserContext(), id)
+	if err != nil {
+		return handler.error(c, err, "failed to list webhook deliveries")
+	}
+
+	return c.JSON(resp)
+}
+
+func (handler *WebhookHandler) error(c *fiber.Ctx, err error, err500msg string) error {
+	resp := handler.problems.Resolve(c, err)
+
+	if resp.Status == http.StatusInternalServerError {
+		logger(c).Error().Err(err).Msg(err500msg)
+	} else {
+		logger(c).Info().Err(err).Msg(resp.Body.Error)
+	}
+
+	return httpext.Send(c, resp)
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/rs/zerolog"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Authentication проверяет JWT или API-ключ из заголовков Authorization и X-API-Key
+// и кладёт пользователя в UserContext запроса.
+func Authentication(authenticator *appservice.Authenticator, logger *zerolog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if key := c.Get(APIKeyHeader); key != "" {
+			token, ok = key, true
+		}
+		if !ok || token == "" {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return httpext.Error(c, http.StatusUnauthorized, "missing bearer token")
+		}
+
+		principal, err := authenticator.Authenticate(c.UserContext(), token)
+		if err != nil {
+			switch {
+			case errors.Is(err, failure.ErrInvalidAPIKey):
+				c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				return httpext.Error(c, http.StatusUnauthorized, err.Error())
+			case errors.Is(err, failure.ErrInvalidToken):
+				logger.Info().Err(err).Msg("invalid token")
+				c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				return httpext.Error(c, http.StatusUnauthorized, failure.ErrInvalidToken.Error())
+			}
+
+			logger.Error().Err(err).Msg("failed to authenticate")
+			return httpext.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+
+		c.SetUserContext(auth.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
+
+// RequireScope пропускает запрос, только если пользователю выдано право scope.
+// Без аутентификации пользователя в контексте нет и проверка не выполняется.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !auth.HasScope(c.UserContext(), scope) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			return httpext.Error(c, http.StatusForbidden, "insufficient scope: "+scope+" is required")
+		}
+		return c.Next()
+	}
+}
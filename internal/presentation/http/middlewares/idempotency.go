@@ -0,0 +1,237 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/rs/zerolog"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyKeyMismatchMsg = "idempotency key was already used with a different request"
+	idempotencyKeyBusyMsg     = "request with this idempotency key is still in progress"
+	idempotencyUnsupportedMsg = "idempotency key is not supported for this route"
+
+	idempotencyUnsupportedLocal = "idempotency_unsupported"
+)
+
+// Idempotency сохраняет ответы на изменяющие запросы с заголовком Idempotency-Key
+// и воспроизводит их при повторах с тем же ключом. Ключ выполняющегося запроса
+// арендуется на lease и продлевается, пока запрос выполняется: если процесс упал
+// до сохранения ответа, повтор после окончания аренды выполнится заново, а не
+// получит 409 до истечения ttl.
+func Idempotency(
+	repo interfaces.IdempotencyRepository,
+	ttl time.Duration,
+	lease time.Duration,
+	logger *zerolog.Logger,
+) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		// Запросы GraphQL только читают данные, даже если отправлены методом POST.
+		if key == "" || !isMutating(c.Method()) || c.Path() == "/graphql" {
+			return c.Next()
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			return httpext.Error(c, http.StatusBadRequest, "idempotency key is too long")
+		}
+
+		// Ключи разных пользователей не должны пересекаться, иначе повтор с чужим
+		// ключом вернул бы чужой ответ. Арендатора разделяет хранилище.
+		if principal, ok := auth.PrincipalFrom(c.UserContext()); ok {
+			key = principal.Subject + ":" + key
+		}
+
+		token, err := reservationToken()
+		if err != nil {
+			logger.Error().Err(err).Str("key", key).Msg("failed to generate idempotency token")
+			return httpext.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+
+		now := time.Now()
+		record := &entity.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash(c),
+			Token:       token,
+			LockedUntil: now.Add(lease),
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		reserved, err := repo.Reserve(c.UserContext(), record)
+		if err != nil {
+			logger.Error().Err(err).Str("key", key).Msg("failed to reserve idempotency key")
+			return httpext.Error(c, http.StatusInternalServerError, "internal server error")
+		}
+
+		if !reserved {
+			return replay(c, repo, record, logger)
+		}
+
+		stop := holdLease(c.UserContext(), repo, record, lease, logger)
+		err = c.Next()
+		stop()
+
+		if err != nil {
+			deleteKey(c, repo, record, logger)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= http.StatusInternalServerError || c.Locals(idempotencyUnsupportedLocal) != nil {
+			deleteKey(c, repo, record, logger)
+			return nil
+		}
+
+		record.StatusCode = &status
+		record.ResponseBody = append([]byte(nil), c.Response().Body()...)
+		record.ContentType = string(c.Response().Header.ContentType())
+		record.Location = string(c.Response().Header.Peek(fiber.HeaderLocation))
+
+		if err := repo.SaveResponse(c.UserContext(), record); err != nil {
+			logger.Error().Err(err).Str("key", key).Msg("failed to save idempotent response")
+		}
+
+		return nil
+	}
+}
+
+// NoIdempotency отклоняет запросы с Idempotency-Key на маршрутах, ответ которых
+// содержит секрет, например выпущенный API-ключ: Idempotency хранил бы его
+// в открытом виде до истечения ttl. Ответ такого маршрута не сохраняется.
+func NoIdempotency(c *fiber.Ctx) error {
+	if c.Get(IdempotencyKeyHeader) == "" {
+		return c.Next()
+	}
+
+	c.Locals(idempotencyUnsupportedLocal, true)
+	return httpext.Error(c, http.StatusBadRequest, idempotencyUnsupportedMsg)
+}
+
+func replay(
+	c *fiber.Ctx,
+	repo interfaces.IdempotencyRepository,
+	record *entity.IdempotencyRecord,
+	logger *zerolog.Logger,
+) error {
+	stored, err := repo.FindByKey(c.UserContext(), record.Key)
+	if err != nil {
+		if errors.Is(err, failure.ErrIdempotencyKeyNotFound) {
+			return httpext.Error(c, http.StatusConflict, idempotencyKeyBusyMsg)
+		}
+
+		logger.Error().Err(err).Str("key", record.Key).Msg("failed to find idempotency key")
+		return httpext.Error(c, http.StatusInternalServerError, "internal server error")
+	}
+
+	if stored.RequestHash != record.RequestHash {
+		return httpext.Error(c, http.StatusUnprocessableEntity, idempotencyKeyMismatchMsg)
+	}
+
+	if stored.StatusCode == nil {
+		retryAfter := max(int(time.Until(stored.LockedUntil).Seconds()+1), 1)
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return httpext.Error(c, http.StatusConflict, idempotencyKeyBusyMsg)
+	}
+
+	if stored.ContentType != "" {
+		c.Set(fiber.HeaderContentType, stored.ContentType)
+	}
+	if stored.Location != "" {
+		c.Location(stored.Location)
+	}
+	c.Set(IdempotentReplayedHeader, "true")
+
+	logger.Info().Str("key", record.Key).Msg("idempotent response replayed")
+	return c.Status(*stored.StatusCode).Send(stored.ResponseBody)
+}
+
+// holdLease продлевает аренду ключа, пока выполняется запрос: иначе повтор
+// запроса, который длится дольше lease, например пакета из тысяч операций,
+// занял бы ключ и выполнил изменение второй раз. Возвращённая функция
+// останавливает продление и дожидается его завершения.
+func holdLease(
+	ctx context.Context,
+	repo interfaces.IdempotencyRepository,
+	record *entity.IdempotencyRecord,
+	lease time.Duration,
+	logger *zerolog.Logger,
+) func() {
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(lease / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := repo.Extend(ctx, record.Key, record.Token, time.Now().Add(lease)); err != nil {
+					logger.Error().Err(err).Str("key", record.Key).Msg("failed to extend idempotency lease")
+				}
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func deleteKey(
+	c *fiber.Ctx,
+	repo interfaces.IdempotencyRepository,
+	record *entity.IdempotencyRecord,
+	logger *zerolog.Logger,
+) {
+	if err := repo.Delete(c.UserContext(), record.Key, record.Token); err != nil {
+		logger.Error().Err(err).Str("key", record.Key).Msg("failed to release idempotency key")
+	}
+}
+
+func reservationToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.OriginalURL()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,336 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/failure"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+	"github.com/rs/zerolog"
+)
+
+const secret = "sk_secret"
+
+// recordingRepository запоминает сохранённые ответы.
+type recordingRepository struct {
+	*memory.IdempotencyRepository
+
+	mu    sync.Mutex
+	saved [][]byte
+}
+
+func newRecordingRepository() *recordingRepository {
+	return &recordingRepository{IdempotencyRepository: memory.NewIdempotencyRepository()}
+}
+
+func (repo *recordingRepository) SaveResponse(ctx context.Context, record *entity.IdempotencyRecord) error {
+	repo.mu.Lock()
+	repo.saved = append(repo.saved, record.ResponseBody)
+	repo.mu.Unlock()
+
+	return repo.IdempotencyRepository.SaveResponse(ctx, record)
+}
+
+func send(t *testing.T, app *fiber.App, method, path, key, body string) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, string(respBody)
+}
+
+func TestIdempotencySecretNotStored(t *testing.T) {
+	repo := newRecordingRepository()
+	logger := zerolog.Nop()
+
+	var calls int
+	app := fiber.New()
+	app.Use(middlewares.Idempotency(repo, time.Hour, time.Minute, &logger))
+	app.Post("/api-keys", middlewares.NoIdempotency, func(c *fiber.Ctx) error {
+		calls++
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"key": secret})
+	})
+
+	resp, _ := send(t, app, http.MethodPost, "/api-keys", "key-1", `{}`)
+	if resp.StatusCode != http.StatusBadRequest || calls != 0 {
+		t.Fatalf("status = %d, calls = %d, want 400 and no calls", resp.StatusCode, calls)
+	}
+
+	for _, body := range repo.saved {
+		if bytes.Contains(body, []byte(secret)) {
+			t.Fatalf("secret saved: %s", body)
+		}
+	}
+	if _, err := repo.FindByKey(context.Background(), "key-1"); !errors.Is(err, failure.ErrIdempotencyKeyNotFound) {
+		t.Fatalf("FindByKey error = %v, want ErrIdempotencyKeyNotFound", err)
+	}
+
+	resp, body := send(t, app, http.MethodPost, "/api-keys", "", `{}`)
+	if resp.StatusCode != http.StatusCreated || !strings.Contains(body, secret) {
+		t.Fatalf("status = %d, body = %s, want 201 with key", resp.StatusCode, body)
+	}
+	if len(repo.saved) != 0 {
+		t.Fatalf("saved %d responses, want none", len(repo.saved))
+	}
+}
+
+func TestIdempotencyLeaseExtended(t *testing.T) {
+	const lease = 50 * time.Millisecond
+
+	repo := memory.NewIdempotencyRepository()
+	logger := zerolog.Nop()
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app := fiber.New()
+	app.Use(middlewares.Idempotency(repo, time.Hour, lease, &logger))
+	app.Post("/subscriptions/batch", func(c *fiber.Ctx) error {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		return c.Status(http.StatusCreated).SendString(`{"id":1}`)
+	})
+
+	done := make(chan *http.Response, 1)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions/batch", strings.NewReader(`{}`))
+		req.Header.Set(middlewares.IdempotencyKeyHeader, "key-1")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Errorf("request: %v", err)
+		}
+		done <- resp
+	}()
+
+	<-started
+	// Запрос выполняется дольше нескольких сроков аренды.
+	time.Sleep(4 * lease)
+
+	resp, _ := send(t, app, http.MethodPost, "/subscriptions/batch", "key-1", `{}`)
+	if resp.StatusCode != http.StatusConflict || calls.Load() != 1 {
+		t.Fatalf("retry: status = %d, calls = %d, want 409 and one call", resp.StatusCode, calls.Load())
+	}
+
+	close(release)
+	first := <-done
+	if first == nil || first.StatusCode != http.StatusCreated {
+		t.Fatalf("first request: %+v, want 201", first)
+	}
+	first.Body.Close()
+
+	resp, body := send(t, app, http.MethodPost, "/subscriptions/batch", "key-1", `{}`)
+	if resp.StatusCode != http.StatusCreated || body != `{"id":1}` ||
+		resp.Header.Get(middlewares.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay: status = %d, body = %s, want replayed 201", resp.StatusCode, body)
+	}
+}
+
+func newIdempotencyApp(
+	repo *memory.IdempotencyRepository,
+	lease time.Duration,
+	path string,
+	handler fiber.Handler,
+) *fiber.App {
+	logger := zerolog.Nop()
+
+	app := fiber.New()
+	app.Use(middlewares.Idempotency(repo, time.Hour, lease, &logger))
+	app.Post(path, handler)
+	return app
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls int
+	app := newIdempotencyApp(memory.NewIdempotencyRepository(), time.Minute, "/subscriptions",
+		func(c *fiber.Ctx) error {
+			calls++
+			c.Location("/subscriptions/1")
+			return c.Status(http.StatusCreated).JSON(fiber.Map{"id": calls})
+		},
+	)
+
+	first, firstBody := send(t, app, http.MethodPost, "/subscriptions", "key-1", `{"price":100}`)
+	second, secondBody := send(t, app, http.MethodPost, "/subscriptions", "key-1", `{"price":100}`)
+
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+	if first.Header.Get(middlewares.IdempotentReplayedHeader) != "" {
+		t.Fatal("first response marked as replayed")
+	}
+	if second.StatusCode != http.StatusCreated || secondBody != firstBody ||
+		second.Header.Get(middlewares.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay: status = %d, body = %s, want replayed 201 with %s", second.StatusCode, secondBody, firstBody)
+	}
+	if got := second.Header.Get(fiber.HeaderLocation); got != "/subscriptions/1" {
+		t.Fatalf("Location = %q, want /subscriptions/1", got)
+	}
+	if got := second.Header.Get(fiber.HeaderContentType); got != first.Header.Get(fiber.HeaderContentType) {
+		t.Fatalf("Content-Type = %q, want %q", got, first.Header.Get(fiber.HeaderContentType))
+	}
+}
+
+func TestIdempotencyRequestMismatch(t *testing.T) {
+	var calls int
+	app := newIdempotencyApp(memory.NewIdempotencyRepository(), time.Minute, "/subscriptions",
+		func(c *fiber.Ctx) error {
+			calls++
+			return c.SendStatus(http.StatusCreated)
+		},
+	)
+
+	send(t, app, http.MethodPost, "/subscriptions", "key-1", `{"price":100}`)
+	resp, _ := send(t, app, http.MethodPost, "/subscriptions", "key-1", `{"price":200}`)
+
+	if resp.StatusCode != http.StatusUnprocessableEntity || calls != 1 {
+		t.Fatalf("status = %d, calls = %d, want 422 and one call", resp.StatusCode, calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app := newIdempotencyApp(memory.NewIdempotencyRepository(), 10*time.Second, "/subscriptions",
+		func(c *fiber.Ctx) error {
+			if calls.Add(1) == 1 {
+				close(started)
+				<-release
+			}
+			return c.SendStatus(http.StatusCreated)
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(`{}`))
+		req.Header.Set(middlewares.IdempotencyKeyHeader, "key-1")
+		if resp, err := app.Test(req, -1); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	resp, _ := send(t, app, http.MethodPost, "/subscriptions", "key-1", `{}`)
+	close(release)
+	<-done
+
+	if resp.StatusCode != http.StatusConflict || calls.Load() != 1 {
+		t.Fatalf("status = %d, calls = %d, want 409 and one call", resp.StatusCode, calls.Load())
+	}
+	if retryAfter := resp.Header.Get(fiber.HeaderRetryAfter); retryAfter == "" || retryAfter == "0" {
+		t.Fatalf("Retry-After = %q, want seconds until lease ends", retryAfter)
+	}
+}
+
+func TestIdempotencyLeaseExpired(t *testing.T) {
+	repo := memory.NewIdempotencyRepository()
+
+	// Процесс, занявший ключ, упал, не сохранив ответ.
+	now := time.Now()
+	if _, err := repo.Reserve(context.Background(), &entity.IdempotencyRecord{
+		Key:         "key-1",
+		RequestHash: "crashed",
+		Token:       "crashed",
+		LockedUntil: now.Add(-time.Second),
+		ExpiresAt:   now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	var calls int
+	app := newIdempotencyApp(repo, time.Minute, "/subscriptions", func(c *fiber.Ctx) error {
+		calls++
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := send(t, app, http.MethodPost, "/subscriptions", "key-1", `{}`)
+	if resp.StatusCode != http.StatusCreated || calls != 1 {
+		t.Fatalf("status = %d, calls = %d, want 201 and one call", resp.StatusCode, calls)
+	}
+
+	resp, _ = send(t, app, http.MethodPost, "/subscriptions", "key-1", `{}`)
+	if resp.StatusCode != http.StatusCreated || calls != 1 ||
+		resp.Header.Get(middlewares.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("retry: status = %d, calls = %d, want replayed 201", resp.StatusCode, calls)
+	}
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	repo := memory.NewIdempotencyRepository()
+
+	var calls int
+	app := newIdempotencyApp(repo, time.Minute, "/subscriptions", func(c *fiber.Ctx) error {
+		calls++
+		if calls == 1 {
+			return c.SendStatus(http.StatusInternalServerError)
+		}
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := send(t, app, http.MethodPost, "/subscriptions", "key-1", `{}`)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+	if _, err := repo.FindByKey(context.Background(), "key-1"); !errors.Is(err, failure.ErrIdempotencyKeyNotFound) {
+		t.Fatalf("FindByKey error = %v, want ErrIdempotencyKeyNotFound", err)
+	}
+
+	resp, _ = send(t, app, http.MethodPost, "/subscriptions", "key-1", `{}`)
+	if resp.StatusCode != http.StatusCreated || calls != 2 ||
+		resp.Header.Get(middlewares.IdempotentReplayedHeader) != "" {
+		t.Fatalf("retry: status = %d, calls = %d, want 201 and second call", resp.StatusCode, calls)
+	}
+}
+
+func TestIdempotencySkipsGraphQL(t *testing.T) {
+	repo := memory.NewIdempotencyRepository()
+
+	var calls int
+	app := newIdempotencyApp(repo, time.Minute, "/graphql", func(c *fiber.Ctx) error {
+		calls++
+		return c.SendStatus(http.StatusOK)
+	})
+
+	send(t, app, http.MethodPost, "/graphql", "key-1", `{"query":"{ subscriptions { total } }"}`)
+	resp, _ := send(t, app, http.MethodPost, "/graphql", "key-1", `{"query":"{ subscriptions { total } }"}`)
+
+	if resp.StatusCode != http.StatusOK || calls != 2 ||
+		resp.Header.Get(middlewares.IdempotentReplayedHeader) != "" {
+		t.Fatalf("status = %d, calls = %d, want 200 and two calls", resp.StatusCode, calls)
+	}
+	if _, err := repo.FindByKey(context.Background(), "key-1"); !errors.Is(err, failure.ErrIdempotencyKeyNotFound) {
+		t.Fatalf("FindByKey error = %v, want ErrIdempotencyKeyNotFound", err)
+	}
+}
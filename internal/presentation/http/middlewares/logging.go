@@ -7,7 +7,8 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func Logging(logger *zerolog.Logger) fiber.Handler {
+// Logging пишет итог каждого запроса в логгер запроса (см. RequestID).
+func Logging() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
 
@@ -16,6 +17,8 @@ func Logging(logger *zerolog.Logger) fiber.Handler {
 		duration := time.Since(start)
 		statusCode := c.Response().StatusCode()
 
+		logger := zerolog.Ctx(c.UserContext())
+
 		evt := logger.Info()
 		if statusCode >= 500 {
 			evt = logger.Error()
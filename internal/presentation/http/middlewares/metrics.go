@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const unmatchedRoute = "unmatched"
+
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics записывает количество и длительность запросов с шаблоном маршрута
+// (например, /subscriptions/:id), чтобы число серий не зависело от параметров пути.
+// Маршрут ищется по шаблонам, а не берётся из контекста: если запрос отклонил
+// middleware (аутентификация, арендатор, лимит запросов), Fiber не доходит до
+// маршрута. Маршруты читаются при первом запросе, когда все они уже зарегистрированы.
+func Metrics(observer RequestObserver) fiber.Handler {
+	var (
+		once   sync.Once
+		routes []fiber.Route
+	)
+
+	return func(c *fiber.Ctx) error {
+		once.Do(func() { routes = c.App().GetRoutes(true) })
+
+		start := time.Now()
+
+		err := c.Next()
+
+		// Ошибку превратит в ответ обработчик ошибок Fiber уже после middleware.
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		route := matchRoute(routes, c.Method(), c.Path())
+
+		// Fiber переиспользует буферы запроса, поэтому метод копируется:
+		// метка живёт в реестре дольше контекста.
+		observer.ObserveRequest(utils.CopyString(c.Method()), route, status, time.Since(start))
+
+		return err
+	}
+}
+
+// matchRoute возвращает шаблон первого маршрута, который подходит к методу и пути
+// запроса, как это сделал бы роутер Fiber без учёта регистра и завершающего '/'.
+func matchRoute(routes []fiber.Route, method, path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, route := range routes {
+		if route.Method == method && matchPath(route.Path, segments) {
+			return route.Path
+		}
+	}
+	return unmatchedRoute
+}
+
+// matchPath сравнивает сегменты пути с шаблоном: ":param" совпадает с любым
+// непустым сегментом, "*" и "+" — с остатком пути.
+func matchPath(pattern string, path []string) bool {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+
+	for i, segment := range segments {
+		if segment == "*" || segment == "+" {
+			return segment == "*" || i < len(path) && path[i] != ""
+		}
+		if i >= len(path) {
+			return false
+		}
+
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if path[i] == "" {
+				return false
+			}
+		case !strings.EqualFold(segment, path[i]):
+			return false
+		}
+	}
+
+	return len(segments) == len(path)
+}
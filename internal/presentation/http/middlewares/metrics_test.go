@@ -0,0 +1,130 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+)
+
+type observation struct {
+	method string
+	route  string
+	status int
+}
+
+type recorder struct {
+	last observation
+}
+
+func (r *recorder) ObserveRequest(method, route string, status int, _ time.Duration) {
+	r.last = observation{method: method, route: route, status: status}
+}
+
+func TestMetricsRoute(t *testing.T) {
+	observer := &recorder{}
+
+	app := fiber.New()
+	app.Use(middlewares.Metrics(observer))
+	app.Get("/health", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+	// Как Authentication: запрос без токена отклоняется до маршрута.
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.SendStatus(http.StatusUnauthorized)
+		}
+		return c.Next()
+	})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app.Get("/subscriptions/stream", ok)
+	app.Get("/subscriptions/:id", ok)
+	app.Delete("/subscriptions/:id", ok)
+	app.Get("/webhooks/:id/deliveries", ok)
+	app.Get("/swagger/*", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		auth   bool
+		want   observation
+	}{
+		{
+			name:   "handled route",
+			method: http.MethodGet,
+			path:   "/subscriptions/42",
+			auth:   true,
+			want:   observation{method: http.MethodGet, route: "/subscriptions/:id", status: http.StatusOK},
+		},
+		{
+			name:   "rejected by middleware",
+			method: http.MethodGet,
+			path:   "/subscriptions/42",
+			want:   observation{method: http.MethodGet, route: "/subscriptions/:id", status: http.StatusUnauthorized},
+		},
+		{
+			name:   "static route before parameter",
+			method: http.MethodGet,
+			path:   "/subscriptions/stream",
+			want:   observation{method: http.MethodGet, route: "/subscriptions/stream", status: http.StatusUnauthorized},
+		},
+		{
+			name:   "method is taken into account",
+			method: http.MethodDelete,
+			path:   "/subscriptions/42/",
+			want:   observation{method: http.MethodDelete, route: "/subscriptions/:id", status: http.StatusUnauthorized},
+		},
+		{
+			name:   "nested parameter",
+			method: http.MethodGet,
+			path:   "/Webhooks/7/deliveries",
+			want:   observation{method: http.MethodGet, route: "/webhooks/:id/deliveries", status: http.StatusUnauthorized},
+		},
+		{
+			name:   "wildcard",
+			method: http.MethodGet,
+			path:   "/swagger/index.html",
+			want:   observation{method: http.MethodGet, route: "/swagger/*", status: http.StatusUnauthorized},
+		},
+		{
+			name:   "route before middleware",
+			method: http.MethodGet,
+			path:   "/health",
+			want:   observation{method: http.MethodGet, route: "/health", status: http.StatusOK},
+		},
+		{
+			name:   "unknown path",
+			method: http.MethodGet,
+			path:   "/subscriptions/42/extra",
+			auth:   true,
+			want:   observation{method: http.MethodGet, route: "unmatched", status: http.StatusNotFound},
+		},
+		{
+			name:   "unknown method",
+			method: http.MethodPut,
+			path:   "/subscriptions/42",
+			want:   observation{method: http.MethodPut, route: "unmatched", status: http.StatusUnauthorized},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer token")
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+
+			if observer.last != tt.want {
+				t.Fatalf("observation = %+v, want %+v", observer.last, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/appservice"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/domain/interfaces"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/rs/zerolog"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// Классы маршрутов. Корзины ведутся отдельно для каждого класса.
+const (
+	RateLimitClassRead  = "read"
+	RateLimitClassWrite = "write"
+	RateLimitClassCosts = "costs"
+	// RateLimitClassIP — все запросы с IP-адреса до аутентификации.
+	RateLimitClassIP = "ip"
+)
+
+// RateLimits задаёт лимиты для чтения, записи и расчёта стоимости: /costs
+// читает все подписки через FindAll, поэтому ограничивается отдельно.
+type RateLimits struct {
+	Read  entity.RateLimit
+	Write entity.RateLimit
+	Costs entity.RateLimit
+}
+
+const rateLimiterLocal = "rate_limiter"
+
+// RateLimit ограничивает частоту запросов по алгоритму token bucket. Корзины ведутся
+// отдельно для API-ключа, пользователя или IP-адреса и для каждого класса маршрутов.
+// Регистрируется после Authentication, чтобы различать пользователей.
+func RateLimit(
+	store interfaces.RateLimitStore,
+	limits RateLimits,
+	logger *zerolog.Logger,
+) fiber.Handler {
+	limiter := &rateLimiter{store: store, limits: limits, logger: logger}
+
+	return func(c *fiber.Ctx) error {
+		c.Locals(rateLimiterLocal, limiter)
+
+		class, limit := limits.route(c)
+		if allowed, err := limiter.take(c, class+":"+rateLimitIdentity(c), limit, 1); !allowed {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// RateLimitByIP ограничивает все запросы с IP-адреса до аутентификации: иначе
+// подбор API-ключей и токенов, отклонённый с 401, не ограничивался бы ничем.
+// Регистрируется перед Authentication, а RateLimit — после неё.
+func RateLimitByIP(
+	store interfaces.RateLimitStore,
+	limit entity.RateLimit,
+	logger *zerolog.Logger,
+) fiber.Handler {
+	limiter := &rateLimiter{store: store, logger: logger}
+
+	return func(c *fiber.Ctx) error {
+		if allowed, err := limiter.take(c, RateLimitClassIP+":"+c.IP(), limit, 1); !allowed {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// TakeCostsRateLimit списывает n токенов из лимита расчёта стоимости субъекта
+// запроса. Его вызывает GraphQL: каждое поле cost читает все подписки, как
+// GET /costs, а один запрос может выбрать его много раз. Возвращает false,
+// если лимит исчерпан и ответ 429 уже записан. Без RateLimit ничего не делает.
+func TakeCostsRateLimit(c *fiber.Ctx, n int) (bool, error) {
+	limiter, ok := c.Locals(rateLimiterLocal).(*rateLimiter)
+	if !ok || n <= 0 {
+		return true, nil
+	}
+	return limiter.take(c, RateLimitClassCosts+":"+rateLimitIdentity(c), limiter.limits.Costs, n)
+}
+
+type rateLimiter struct {
+	store  interfaces.RateLimitStore
+	limits RateLimits
+	logger *zerolog.Logger
+}
+
+// take берёт n токенов из корзины key и записывает заголовки RateLimit.
+// Возвращает false, если лимит исчерпан и ответ 429 уже записан.
+func (limiter *rateLimiter) take(c *fiber.Ctx, key string, limit entity.RateLimit, n int) (bool, error) {
+	result, err := limiter.store.Take(c.UserContext(), key, n, limit)
+	if err != nil {
+		// Недоступность хранилища лимитов не должна останавливать сервис.
+		limiter.logger.Error().Err(err).Str("key", key).Msg("failed to check rate limit")
+		return true, nil
+	}
+
+	c.Set(RateLimitLimitHeader, strconv.Itoa(limit.Limit))
+	c.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	c.Set(RateLimitResetHeader, seconds(result.Reset))
+	c.Set(RateLimitPolicyHeader, fmt.Sprintf("%d;w=%s", limit.Limit, seconds(limit.Period)))
+
+	if !result.Allowed {
+		limiter.logger.Info().Str("key", key).Msg("rate limit exceeded")
+		c.Set(fiber.HeaderRetryAfter, seconds(result.RetryAfter))
+		return false, httpext.Error(c, http.StatusTooManyRequests, "rate limit exceeded")
+	}
+
+	return true, nil
+}
+
+func (limits RateLimits) route(c *fiber.Ctx) (string, entity.RateLimit) {
+	switch {
+	case strings.HasPrefix(c.Path(), "/costs"):
+		return RateLimitClassCosts, limits.Costs
+	// Запросы GraphQL только читают данные, даже если отправлены методом POST.
+	case c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Path() == "/graphql":
+		return RateLimitClassRead, limits.Read
+	default:
+		return RateLimitClassWrite, limits.Write
+	}
+}
+
+func rateLimitIdentity(c *fiber.Ctx) string {
+	if principal, ok := auth.PrincipalFrom(c.UserContext()); ok {
+		if strings.HasPrefix(principal.Subject, appservice.APIKeySubjectPrefix) {
+			return principal.Subject
+		}
+		return "user:" + principal.Subject
+	}
+	return "ip:" + c.IP()
+}
+
+// seconds округляет длительность вверх до целых секунд.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
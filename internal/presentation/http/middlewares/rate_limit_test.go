@@ -0,0 +1,92 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/domain/entity"
+	"github.com/noredis/subscriptions/internal/infrastructure/memory"
+	"github.com/noredis/subscriptions/internal/presentation/http/middlewares"
+	"github.com/rs/zerolog"
+)
+
+func TestTakeCostsRateLimit(t *testing.T) {
+	logger := zerolog.Nop()
+	limits := middlewares.RateLimits{
+		Read:  entity.RateLimit{Limit: 100, Period: time.Hour},
+		Write: entity.RateLimit{Limit: 100, Period: time.Hour},
+		Costs: entity.RateLimit{Limit: 10, Period: time.Hour},
+	}
+
+	app := fiber.New()
+	app.Use(middlewares.RateLimit(memory.NewRateLimitStore(), limits, &logger))
+	// Как GraphQL: запрос читает данные и n раз рассчитывает стоимость.
+	app.Post("/graphql", func(c *fiber.Ctx) error {
+		n := c.QueryInt("costs")
+		if allowed, err := middlewares.TakeCostsRateLimit(c, n); !allowed {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+	app.Get("/costs", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "query without cost", method: http.MethodPost, path: "/graphql?costs=0", wantStatus: http.StatusOK},
+		{name: "aliased costs", method: http.MethodPost, path: "/graphql?costs=9", wantStatus: http.StatusOK},
+		{name: "costs over limit", method: http.MethodPost, path: "/graphql?costs=2", wantStatus: http.StatusTooManyRequests},
+		{name: "rest shares bucket", method: http.MethodGet, path: "/costs", wantStatus: http.StatusOK},
+		{name: "rest over limit", method: http.MethodGet, path: "/costs", wantStatus: http.StatusTooManyRequests},
+		{name: "query without cost is still allowed", method: http.MethodPost, path: "/graphql", wantStatus: http.StatusOK},
+	}
+
+	for _, step := range steps {
+		resp, err := app.Test(httptest.NewRequest(step.method, step.path, nil), -1)
+		if err != nil {
+			t.Fatalf("%s: request: %v", step.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", step.name, resp.StatusCode, step.wantStatus)
+		}
+	}
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	logger := zerolog.Nop()
+	store := memory.NewRateLimitStore()
+
+	app := fiber.New()
+	app.Use(middlewares.RateLimitByIP(store, entity.RateLimit{Limit: 2, Period: time.Hour}, &logger))
+	// Как Authentication: запрос с неверным ключом отклоняется.
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) != "Bearer valid" {
+			return c.SendStatus(http.StatusUnauthorized)
+		}
+		return c.Next()
+	})
+	app.Get("/subscriptions", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer guess")
+
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != want {
+			t.Fatalf("request %d: status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/noredis/subscriptions/pkg/rules"
+	"github.com/rs/zerolog"
+)
+
+// RequestID принимает идентификатор запроса из X-Request-ID или генерирует
+// новый, возвращает его в ответе и кладёт в контекст запроса дочерний логгер
+// с полем request_id. Обработчики, сервисы и репозитории получают его через
+// zerolog.Ctx.
+func RequestID(logger *zerolog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(httpext.RequestIDHeader)
+		if !rules.IsRequestID(id) {
+			id = uuid.NewString()
+		} else {
+			id = utils.CopyString(id)
+		}
+
+		c.Set(httpext.RequestIDHeader, id)
+
+		ctx := c.UserContext()
+		requestLogger := logger.With().Str("request_id", id).Ctx(ctx).Logger()
+		c.SetUserContext(requestLogger.WithContext(ctx))
+
+		return c.Next()
+	}
+}
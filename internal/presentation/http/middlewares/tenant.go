@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/internal/application/auth"
+	"github.com/noredis/subscriptions/internal/domain/tenant"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/noredis/subscriptions/pkg/rules"
+	"github.com/rs/zerolog"
+)
+
+// Tenant определяет арендатора запроса и кладёт его в UserContext. Арендатор из токена
+// или API-ключа имеет приоритет, заголовок header может лишь совпадать с ним.
+// Выбрать арендатора заголовком может только администратор без арендатора или
+// запрос без аутентификации. Регистрируется после Authentication.
+func Tenant(header string, logger *zerolog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID := c.Get(header)
+
+		if principal, ok := auth.PrincipalFrom(c.UserContext()); ok {
+			switch {
+			case principal.Tenant != "":
+				if tenantID != "" && tenantID != principal.Tenant {
+					logger.Info().
+						Str("subject", principal.Subject).
+						Str("tenant", tenantID).
+						Msg("tenant mismatch")
+					return httpext.Error(c, http.StatusForbidden, "access to tenant denied")
+				}
+				tenantID = principal.Tenant
+			case !principal.Admin:
+				logger.Info().
+					Str("subject", principal.Subject).
+					Msg("principal without tenant")
+				return httpext.Error(c, http.StatusForbidden, "access to tenant denied")
+			}
+		}
+
+		if tenantID == "" {
+			return httpext.Error(c, http.StatusBadRequest, header+" header is required")
+		}
+		if !rules.IsTenantID(tenantID) {
+			return httpext.Error(c, http.StatusBadRequest, "invalid tenant id")
+		}
+
+		c.SetUserContext(tenant.WithTenant(c.UserContext(), tenantID))
+		return c.Next()
+	}
+}
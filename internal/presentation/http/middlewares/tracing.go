@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/noredis/subscriptions/internal/presentation/http/middlewares")
+
+// Tracing открывает span на каждый запрос, продолжая трассировку из заголовка
+// traceparent, и кладёт его в пользовательский контекст запроса, откуда его
+// подхватывают сервисы и репозитории.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		method := utils.CopyString(c.Method())
+
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), requestCarrier{c})
+		ctx, span := tracer.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(utils.CopyString(c.Path())),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(utils.CopyString(c.Get(fiber.HeaderUserAgent))),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		matched := true
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+				matched = fiberErr.Code != fiber.StatusNotFound
+			}
+			span.RecordError(err)
+		}
+
+		if r := c.Route(); matched && r != nil && r.Method != "USE" {
+			span.SetName(fmt.Sprintf("%s %s", method, r.Path))
+			span.SetAttributes(semconv.HTTPRoute(r.Path))
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+
+		return err
+	}
+}
+
+// requestCarrier читает заголовки трассировки из запроса Fiber.
+type requestCarrier struct {
+	c *fiber.Ctx
+}
+
+var _ propagation.TextMapCarrier = requestCarrier{}
+
+func (carrier requestCarrier) Get(key string) string {
+	return carrier.c.Get(key)
+}
+
+func (carrier requestCarrier) Set(key, value string) {
+	carrier.c.Request().Header.Set(key, value)
+}
+
+func (carrier requestCarrier) Keys() []string {
+	keys := make([]string, 0)
+	carrier.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
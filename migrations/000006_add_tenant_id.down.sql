@@ -0,0 +1,8 @@
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_tenant_id_service_name_user_id_key;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_service_name_user_id_key UNIQUE (service_name, user_id);
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,14 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_service_name_user_id_key;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_tenant_id_service_name_user_id_key UNIQUE (tenant_id, service_name, user_id);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT '';
+
+-- Политика действует только для ролей, не владеющих таблицей: сервис под такой ролью
+-- должен передавать арендатора в app.tenant_id (DB_ROW_LEVEL_SECURITY=true).
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
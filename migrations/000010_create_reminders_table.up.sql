@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS reminders(
+    tenant_id TEXT NOT NULL,
+    subscription_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    due_date DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, subscription_id, kind, due_date)
+);
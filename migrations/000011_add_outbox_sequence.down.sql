@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_seq;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS txid;
+ALTER TABLE outbox DROP COLUMN IF EXISTS seq;
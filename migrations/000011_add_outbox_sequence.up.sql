@@ -0,0 +1,4 @@
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS txid XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_seq ON outbox(seq);
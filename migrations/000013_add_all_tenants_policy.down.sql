@@ -0,0 +1,4 @@
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
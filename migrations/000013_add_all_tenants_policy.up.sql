@@ -0,0 +1,11 @@
+-- Фоновые задачи обходят подписки всех арендаторов и передают app.all_tenants = 'on'.
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
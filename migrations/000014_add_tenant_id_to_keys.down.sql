@@ -0,0 +1,14 @@
+DROP POLICY IF EXISTS rate_limit_buckets_tenant_isolation ON rate_limit_buckets;
+ALTER TABLE rate_limit_buckets DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS idempotency_keys_tenant_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+
+TRUNCATE rate_limit_buckets;
+ALTER TABLE rate_limit_buckets DROP CONSTRAINT IF EXISTS rate_limit_buckets_pkey;
+ALTER TABLE rate_limit_buckets DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE rate_limit_buckets ADD PRIMARY KEY (key);
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+UPDATE idempotency_keys SET key = tenant_id || ':' || key;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
@@ -0,0 +1,35 @@
+-- Ключи идемпотентности раньше хранили арендатора префиксом "tenant:".
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+UPDATE idempotency_keys
+SET tenant_id = split_part(key, ':', 1), key = substr(key, strpos(key, ':') + 1)
+WHERE strpos(key, ':') > 0;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
+
+-- Корзины восстанавливаются сами, поэтому старые удаляются без переноса.
+TRUNCATE rate_limit_buckets;
+ALTER TABLE rate_limit_buckets ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE rate_limit_buckets DROP CONSTRAINT IF EXISTS rate_limit_buckets_pkey;
+ALTER TABLE rate_limit_buckets ADD PRIMARY KEY (tenant_id, key);
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+CREATE POLICY idempotency_keys_tenant_isolation ON idempotency_keys
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
+
+ALTER TABLE rate_limit_buckets ENABLE ROW LEVEL SECURITY;
+CREATE POLICY rate_limit_buckets_tenant_isolation ON rate_limit_buckets
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
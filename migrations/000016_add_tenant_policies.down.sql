@@ -0,0 +1,10 @@
+DROP POLICY IF EXISTS api_keys_tenant_isolation ON api_keys;
+ALTER TABLE api_keys DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS reminders_tenant_isolation ON reminders;
+ALTER TABLE reminders DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS outbox_tenant_isolation ON outbox;
+ALTER TABLE outbox DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS webhook_deliveries_tenant_isolation ON webhook_deliveries;
+ALTER TABLE webhook_deliveries DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS webhooks_tenant_isolation ON webhooks;
+ALTER TABLE webhooks DISABLE ROW LEVEL SECURITY;
@@ -0,0 +1,58 @@
+-- Остальные таблицы арендаторов получают ту же политику, что и subscriptions.
+-- Ключи API ищутся до того, как известен арендатор запроса, поэтому сервис
+-- читает их с app.all_tenants = 'on'.
+
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+CREATE POLICY webhooks_tenant_isolation ON webhooks
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
+
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+CREATE POLICY webhook_deliveries_tenant_isolation ON webhook_deliveries
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
+
+ALTER TABLE outbox ENABLE ROW LEVEL SECURITY;
+CREATE POLICY outbox_tenant_isolation ON outbox
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
+
+ALTER TABLE reminders ENABLE ROW LEVEL SECURITY;
+CREATE POLICY reminders_tenant_isolation ON reminders
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+CREATE POLICY api_keys_tenant_isolation ON api_keys
+    USING (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    )
+    WITH CHECK (
+        current_setting('app.all_tenants', true) = 'on'
+        OR tenant_id = current_setting('app.tenant_id', true)
+    );
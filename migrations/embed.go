@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var postgres embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+func Postgres() fs.FS {
+	return postgres
+}
+
+func SQLite() fs.FS {
+	sub, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
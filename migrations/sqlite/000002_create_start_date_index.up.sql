@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_subscriptions_start_date ON subscriptions(start_date);
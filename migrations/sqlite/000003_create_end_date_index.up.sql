@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_subscriptions_end_date ON subscriptions(end_date);
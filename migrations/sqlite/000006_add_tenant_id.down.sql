@@ -0,0 +1,20 @@
+ALTER TABLE api_keys DROP COLUMN tenant_id;
+
+CREATE TABLE subscriptions_old(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    UNIQUE (service_name, user_id)
+);
+
+INSERT INTO subscriptions_old (id, service_name, price, user_id, start_date, end_date)
+SELECT id, service_name, price, user_id, start_date, end_date FROM subscriptions;
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_old RENAME TO subscriptions;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_start_date ON subscriptions(start_date);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_end_date ON subscriptions(end_date);
@@ -0,0 +1,21 @@
+CREATE TABLE subscriptions_new(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    UNIQUE (tenant_id, service_name, user_id)
+);
+
+INSERT INTO subscriptions_new (id, service_name, price, user_id, start_date, end_date)
+SELECT id, service_name, price, user_id, start_date, end_date FROM subscriptions;
+
+DROP TABLE subscriptions;
+ALTER TABLE subscriptions_new RENAME TO subscriptions;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_start_date ON subscriptions(start_date);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_end_date ON subscriptions(end_date);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox(
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    occurred_at INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    next_attempt_at INTEGER NOT NULL,
+    published_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending
+    ON outbox(next_attempt_at, occurred_at) WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at
+    ON outbox(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,32 @@
+CREATE TABLE outbox_seq(
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL UNIQUE,
+    tenant_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    occurred_at INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    next_attempt_at INTEGER NOT NULL,
+    published_at INTEGER
+);
+
+INSERT INTO outbox_seq(
+    id, tenant_id, type, aggregate_id, payload, occurred_at,
+    attempts, error, next_attempt_at, published_at
+)
+SELECT
+    id, tenant_id, type, aggregate_id, payload, occurred_at,
+    attempts, error, next_attempt_at, published_at
+FROM outbox
+ORDER BY occurred_at;
+
+DROP TABLE outbox;
+ALTER TABLE outbox_seq RENAME TO outbox;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending
+    ON outbox(next_attempt_at, occurred_at) WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at
+    ON outbox(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE idempotency_keys_old(
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    content_type TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    expires_at INTEGER NOT NULL,
+    locked_until INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO idempotency_keys_old (
+    key, request_hash, status_code, response_body, content_type, location, expires_at, locked_until
+)
+SELECT tenant_id || ':' || key, request_hash, status_code, response_body, content_type, location, expires_at, locked_until
+FROM idempotency_keys;
+
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_old RENAME TO idempotency_keys;
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
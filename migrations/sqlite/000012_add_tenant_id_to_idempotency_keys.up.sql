@@ -0,0 +1,27 @@
+CREATE TABLE idempotency_keys_new(
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    content_type TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    expires_at INTEGER NOT NULL,
+    locked_until INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, key)
+);
+
+-- Ключи идемпотентности раньше хранили арендатора префиксом "tenant:".
+INSERT INTO idempotency_keys_new (
+    tenant_id, key, request_hash, status_code, response_body, content_type, location, expires_at, locked_until
+)
+SELECT
+    CASE WHEN instr(key, ':') > 0 THEN substr(key, 1, instr(key, ':') - 1) ELSE 'default' END,
+    CASE WHEN instr(key, ':') > 0 THEN substr(key, instr(key, ':') + 1) ELSE key END,
+    request_hash, status_code, response_body, content_type, location, expires_at, locked_until
+FROM idempotency_keys;
+
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_new RENAME TO idempotency_keys;
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,144 @@
+package httpext
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrUnsupportedMediaType возвращается DecodeJSON, если тело запроса
+// передано не в формате JSON.
+var ErrUnsupportedMediaType = errors.New("content type must be application/json")
+
+// Коды ошибок полей, которые возвращает DecodeJSON.
+const (
+	FieldErrorUnknown     = "unknown_field"
+	FieldErrorInvalidType = "invalid_type"
+)
+
+// DecodeError — тело запроса не удалось разобрать. Fields описывает ошибки
+// отдельных полей: лишние поля и значения неверного типа.
+type DecodeError struct {
+	Message string
+	Fields  []DecodeFieldError
+}
+
+// DecodeFieldError — ошибка поля тела запроса. Code - один из FieldError*,
+// Param - ожидаемый тип значения для FieldErrorInvalidType.
+type DecodeFieldError struct {
+	Field string
+	Code  string
+	Param string
+}
+
+func (e *DecodeError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s %s", e.Message, e.Fields[0].Field, e.Fields[0].Code)
+}
+
+// decodeMessages — тексты ошибок полей по умолчанию. {0} заменяется именем
+// поля, {1} - параметром. Переводы задаются через ProblemRegistry.Messages.
+var decodeMessages = map[string]string{
+	FieldErrorUnknown:     "{0} is not a known field",
+	FieldErrorInvalidType: "{0} must be of type {1}",
+}
+
+// DecodeJSON строго разбирает тело запроса в v. В отличие от c.BodyParser
+// принимаются только тела с Content-Type application/json (или +json),
+// неизвестные поля и значения неверного типа отклоняются с ошибкой поля,
+// а после JSON-значения не должно быть других данных.
+func DecodeJSON(c *fiber.Ctx, v any) error {
+	mediaType, _, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if err != nil || (mediaType != fiber.MIMEApplicationJSON && !strings.HasSuffix(mediaType, "+json")) {
+		return ErrUnsupportedMediaType
+	}
+
+	body := c.Body()
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &DecodeError{Message: "request body is empty"}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return &DecodeError{Message: "request body must contain a single JSON value"}
+	}
+
+	return nil
+}
+
+func decodeError(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &DecodeError{Message: fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &DecodeError{Message: "malformed JSON: unexpected end of input"}
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			return &DecodeError{Message: fmt.Sprintf("request body must be a JSON %s", jsonType(typeErr.Type))}
+		}
+		return &DecodeError{
+			Message: "invalid request body",
+			Fields: []DecodeFieldError{{
+				Field: field,
+				Code:  FieldErrorInvalidType,
+				Param: jsonType(typeErr.Type),
+			}},
+		}
+	}
+
+	// encoding/json не экспортирует тип ошибки неизвестного поля.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &DecodeError{
+			Message: "invalid request body",
+			Fields: []DecodeFieldError{{
+				Field: strings.Trim(field, `"`),
+				Code:  FieldErrorUnknown,
+			}},
+		}
+	}
+
+	return &DecodeError{Message: "invalid request body"}
+}
+
+// jsonType называет тип JSON, в который разбирается значение типа typ.
+func jsonType(typ reflect.Type) string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,253 @@
+package httpext_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/pkg/httpext"
+)
+
+const bodyLimit = 64
+
+type request struct {
+	Name  string   `json:"name"`
+	Price int      `json:"price"`
+	Tags  []string `json:"tags"`
+}
+
+// decode отправляет body на тестовый маршрут и возвращает результат разбора
+// и ошибку DecodeJSON.
+func decode(t *testing.T, contentType, body string) (req request, err error) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		err = httpext.DecodeJSON(c, &req)
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		httpReq.Header.Set(fiber.HeaderContentType, contentType)
+	}
+
+	resp, testErr := app.Test(httpReq)
+	if testErr != nil {
+		t.Fatalf("request: %v", testErr)
+	}
+	resp.Body.Close()
+
+	return req, err
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        request
+	}{
+		{
+			name:        "valid",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"Netflix","price":400,"tags":["video"]}`,
+			want:        request{Name: "Netflix", Price: 400, Tags: []string{"video"}},
+		},
+		{
+			name:        "charset parameter",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name":"Netflix"}`,
+			want:        request{Name: "Netflix"},
+		},
+		{
+			name:        "json suffix",
+			contentType: "application/merge-patch+json",
+			body:        `{"price":400}`,
+			want:        request{Price: 400},
+		},
+		{
+			name:        "trailing whitespace",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        "{\"name\":\"Netflix\"}\n\t ",
+			want:        request{Name: "Netflix"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decode(t, tt.contentType, tt.body)
+			if err != nil {
+				t.Fatalf("DecodeJSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decoded = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantMessage string
+		wantFields  []httpext.DecodeFieldError
+	}{
+		{
+			name:        "unknown field",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"Netflix","user":"admin"}`,
+			wantMessage: "invalid request body",
+			wantFields:  []httpext.DecodeFieldError{{Field: "user", Code: httpext.FieldErrorUnknown}},
+		},
+		{
+			name:        "invalid type",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"price":"400"}`,
+			wantMessage: "invalid request body",
+			wantFields: []httpext.DecodeFieldError{
+				{Field: "price", Code: httpext.FieldErrorInvalidType, Param: "integer"},
+			},
+		},
+		{
+			name:        "invalid element type",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"tags":[1]}`,
+			wantMessage: "invalid request body",
+			wantFields: []httpext.DecodeFieldError{
+				{Field: "tags.0", Code: httpext.FieldErrorInvalidType, Param: "string"},
+			},
+		},
+		{
+			name:        "not an object",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `[]`,
+			wantMessage: "request body must be a JSON object",
+		},
+		{
+			name:        "trailing object",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"Netflix"}{"name":"Spotify"}`,
+			wantMessage: "request body must contain a single JSON value",
+		},
+		{
+			name:        "trailing garbage",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"Netflix"} x`,
+			wantMessage: "request body must contain a single JSON value",
+		},
+		{
+			name:        "empty body",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        " \n",
+			wantMessage: "request body is empty",
+		},
+		{
+			name:        "malformed",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":}`,
+			wantMessage: "malformed JSON at offset 9",
+		},
+		{
+			name:        "truncated",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"Netflix"`,
+			wantMessage: "malformed JSON: unexpected end of input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decode(t, tt.contentType, tt.body)
+
+			var decodeErr *httpext.DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("DecodeJSON error = %v, want DecodeError", err)
+			}
+			if decodeErr.Message != tt.wantMessage {
+				t.Fatalf("message = %q, want %q", decodeErr.Message, tt.wantMessage)
+			}
+			if !reflect.DeepEqual(decodeErr.Fields, tt.wantFields) {
+				t.Fatalf("fields = %+v, want %+v", decodeErr.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONMediaType(t *testing.T) {
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded", "application/jsonx", ";"} {
+		t.Run(contentType, func(t *testing.T) {
+			_, err := decode(t, contentType, `{"name":"Netflix"}`)
+			if !errors.Is(err, httpext.ErrUnsupportedMediaType) {
+				t.Fatalf("DecodeJSON error = %v, want ErrUnsupportedMediaType", err)
+			}
+		})
+	}
+}
+
+// TestDecodeJSONBodyLimit проверяет ограничение тела через настоящий сервер:
+// app.Test не получает ответ 413, а возвращает ошибку соединения.
+func TestDecodeJSONBodyLimit(t *testing.T) {
+	var handled atomic.Bool
+
+	app := fiber.New(fiber.Config{
+		BodyLimit:    bodyLimit,
+		ErrorHandler: httpext.ErrorHandler,
+	})
+	app.Post("/", func(c *fiber.Ctx) error {
+		handled.Store(true)
+
+		var req request
+		if err := httpext.DecodeJSON(c, &req); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+
+	tests := []struct {
+		name        string
+		size        int
+		wantStatus  int
+		wantHandled bool
+	}{
+		{name: "at limit", size: bodyLimit, wantStatus: http.StatusNoContent, wantHandled: true},
+		{name: "over limit", size: bodyLimit + 1, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled.Store(false)
+
+			// {"name":"xxx..."} ровно tt.size байт.
+			body := `{"name":"` + strings.Repeat("x", tt.size-len(`{"name":""}`)) + `"}`
+
+			resp, err := http.Post("http://"+ln.Addr().String(), fiber.MIMEApplicationJSON, strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus || handled.Load() != tt.wantHandled {
+				t.Fatalf(
+					"status = %d, handled = %t, want %d, %t",
+					resp.StatusCode, handled.Load(), tt.wantStatus, tt.wantHandled,
+				)
+			}
+		})
+	}
+}
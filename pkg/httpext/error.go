@@ -1,16 +1,22 @@
 package httpext
 
 import (
-	"fmt"
-	"net/http"
+	"errors"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/pkg/validatorext"
 )
 
+// RequestIDHeader — заголовок с идентификатором запроса. Если он выставлен
+// в ответе, идентификатор повторяется в теле ошибки.
+const RequestIDHeader = "X-Request-ID"
+
 type FiberError struct {
-	Error  string       `json:"error"`
-	Fields []FieldError `json:"fields,omitempty"`
+	Error     string       `json:"error"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
 }
 
 type FieldError struct {
@@ -19,36 +25,44 @@ type FieldError struct {
 }
 
 func Error(c *fiber.Ctx, code int, err string) error {
-	return c.Status(code).JSON(FiberError{Error: err})
+	return Send(c, ErrorResponse{
+		Status:  code,
+		Body:    FiberError{Error: err},
+		Problem: StatusProblem(code),
+	})
 }
 
-func ValidationError(c *fiber.Ctx, vErrs validator.ValidationErrors) error {
+// NewValidationError описывает ошибки полей на языке переводчика trans.
+func NewValidationError(vErrs validator.ValidationErrors, trans ut.Translator) FiberError {
 	fieldErrors := make([]FieldError, 0)
 
 	for _, fErr := range vErrs {
 		fieldErrors = append(fieldErrors, FieldError{
 			Field:       fErr.Field(),
-			Description: mapTagToMessage(fErr),
+			Description: fErr.Translate(trans),
 		})
 	}
 
-	return c.Status(http.StatusUnprocessableEntity).JSON(FiberError{
+	return FiberError{
 		Error:  "validation error",
 		Fields: fieldErrors,
-	})
+	}
+}
+
+// Translator выбирает язык сообщений по заголовку Accept-Language. Если ни
+// один из поддерживаемых языков не подходит, используется язык по умолчанию.
+func Translator(c *fiber.Ctx, uni *ut.UniversalTranslator) ut.Translator {
+	trans, _ := uni.GetTranslator(c.AcceptsLanguages(validatorext.Locales...))
+	return trans
 }
 
-func mapTagToMessage(fErr validator.FieldError) string {
-	switch fErr.Tag() {
-	case "required":
-		return fmt.Sprintf("%s is required", fErr.Field())
-	case "gte":
-		return fmt.Sprintf("%s should be more than %s", fErr.Field(), fErr.Param())
-	case "uuid":
-		return fmt.Sprintf("%s should be uuid", fErr.Field())
-	case "date_format":
-		return fmt.Sprintf("%s must match 'MM-YYYY'", fErr.Field())
-	default:
-		return fErr.Tag()
+// ErrorHandler — обработчик ошибок для fiber.Config. Он отправляет ошибки,
+// которые не обработали хендлеры (нет маршрута, тело больше BodyLimit, паника),
+// в том же формате, что и Error, не раскрывая подробностей внутренних ошибок.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return Error(c, fiberErr.Code, fiberErr.Message)
 	}
+	return Error(c, fiber.StatusInternalServerError, "internal server error")
 }
@@ -0,0 +1,260 @@
+package httpext
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ContentTypeProblemJSON — тип содержимого ошибок в формате RFC 7807. Клиент
+// получает его, если перечислил в заголовке Accept; остальным ошибки
+// отправляются в прежнем формате FiberError.
+const ContentTypeProblemJSON = "application/problem+json"
+
+// Problem — документ ошибки по RFC 7807 с расширениями code, fields и request_id.
+type Problem struct {
+	Type      string       `json:"type" example:"/problems/subscription-not-found"`
+	Title     string       `json:"title" example:"Subscription not found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"subscription not found"`
+	Instance  string       `json:"instance,omitempty" example:"/subscriptions/42"`
+	Code      string       `json:"code" example:"subscription_not_found"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// ProblemType описывает класс ошибок: URI типа, заголовок, HTTP-статус и
+// машиночитаемый код.
+type ProblemType struct {
+	Type   string
+	Title  string
+	Status int
+	Code   string
+}
+
+// ErrorResponse — ошибка, готовая к отправке в любом из двух форматов.
+// Language - язык сообщения, он передаётся в заголовке Content-Language.
+type ErrorResponse struct {
+	Status   int
+	Body     FiberError
+	Problem  ProblemType
+	Language string
+}
+
+// Send отправляет ошибку в формате, выбранном по заголовку Accept.
+func Send(c *fiber.Ctx, resp ErrorResponse) error {
+	resp.Body.RequestID = c.GetRespHeader(RequestIDHeader)
+	if resp.Language != "" {
+		c.Set(fiber.HeaderContentLanguage, resp.Language)
+		c.Vary(fiber.HeaderAcceptLanguage)
+	}
+
+	if !AcceptsProblem(c) {
+		return c.Status(resp.Status).JSON(resp.Body)
+	}
+
+	return c.Status(resp.Status).JSON(Problem{
+		Type:      resp.Problem.Type,
+		Title:     resp.Problem.Title,
+		Status:    resp.Status,
+		Detail:    resp.Body.Error,
+		Instance:  c.OriginalURL(),
+		Code:      resp.Problem.Code,
+		Fields:    resp.Body.Fields,
+		RequestID: resp.Body.RequestID,
+	}, ContentTypeProblemJSON)
+}
+
+// AcceptsProblem сообщает, запросил ли клиент ошибки в формате problem+json.
+func AcceptsProblem(c *fiber.Ctx) bool {
+	for _, accepted := range strings.Split(c.Get(fiber.HeaderAccept), ",") {
+		mediaType, _, _ := strings.Cut(accepted, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), ContentTypeProblemJSON) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusProblem — тип проблемы без дополнительной семантики (about:blank),
+// который описывается одним HTTP-статусом.
+func StatusProblem(status int) ProblemType {
+	title := http.StatusText(status)
+
+	return ProblemType{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+		Code:   strings.ToLower(strings.ReplaceAll(title, " ", "_")),
+	}
+}
+
+// ProblemRegistry сопоставляет ошибки типам проблем. Ошибки сравниваются
+// через errors.Is, поэтому обёрнутые ошибки распознаются так же.
+type ProblemRegistry struct {
+	baseURI    string
+	uni        *ut.UniversalTranslator
+	entries    []problemEntry
+	messages   map[string]map[string]string
+	validation ProblemType
+	decode     ProblemType
+	media      ProblemType
+	internal   ProblemType
+}
+
+type problemEntry struct {
+	err     error
+	problem ProblemType
+}
+
+// NewProblemRegistry создаёт реестр, в котором URI типов строятся от baseURI,
+// например "/problems/" + "subscription-not-found". Сообщения об ошибках
+// переводятся на язык из Accept-Language с помощью uni.
+func NewProblemRegistry(baseURI string, uni *ut.UniversalTranslator) *ProblemRegistry {
+	registry := &ProblemRegistry{
+		baseURI:  baseURI,
+		uni:      uni,
+		messages: make(map[string]map[string]string),
+	}
+	registry.validation = registry.problemType(
+		http.StatusUnprocessableEntity, "validation_error", "Validation error",
+	)
+	registry.decode = registry.problemType(
+		http.StatusBadRequest, "invalid_body", "Invalid request body",
+	)
+	registry.media = registry.problemType(
+		http.StatusUnsupportedMediaType, "unsupported_media_type", "Unsupported media type",
+	)
+	registry.internal = registry.problemType(
+		http.StatusInternalServerError, "internal_error", "Internal server error",
+	)
+	return registry
+}
+
+// Register связывает ошибку с HTTP-статусом, кодом и заголовком проблемы.
+func (registry *ProblemRegistry) Register(
+	err error,
+	status int,
+	code string,
+	title string,
+) *ProblemRegistry {
+	registry.entries = append(registry.entries, problemEntry{
+		err:     err,
+		problem: registry.problemType(status, code, title),
+	})
+	return registry
+}
+
+// Messages задаёт сообщения об ошибках на языке locale по их кодам, а также
+// тексты ошибок полей DecodeJSON по кодам FieldError*. Для кодов без
+// сообщения используется текст ошибки.
+func (registry *ProblemRegistry) Messages(locale string, messages map[string]string) *ProblemRegistry {
+	if registry.messages[locale] == nil {
+		registry.messages[locale] = make(map[string]string, len(messages))
+	}
+	for code, msg := range messages {
+		registry.messages[locale][code] = msg
+	}
+	return registry
+}
+
+// Resolve описывает ошибку на языке запроса. Ошибки валидации превращаются
+// в 422 с ошибками полей, ошибки DecodeJSON - в 400 или 415, незарегистрированные
+// ошибки - в 500 без подробностей.
+func (registry *ProblemRegistry) Resolve(c *fiber.Ctx, err error) ErrorResponse {
+	return registry.ResolveIn(err, Translator(c, registry.uni))
+}
+
+// ResolveIn описывает ошибку на языке trans. Используется вне HTTP-запроса,
+// например в gRPC API, где язык выбирается по метаданным вызова.
+func (registry *ProblemRegistry) ResolveIn(err error, trans ut.Translator) ErrorResponse {
+	resp := registry.resolve(err, trans)
+	if msg, ok := registry.messages[trans.Locale()][resp.Problem.Code]; ok {
+		resp.Body.Error = msg
+	}
+	resp.Language = trans.Locale()
+
+	return resp
+}
+
+func (registry *ProblemRegistry) resolve(err error, trans ut.Translator) ErrorResponse {
+	var vErrs validator.ValidationErrors
+	if errors.As(err, &vErrs) {
+		return ErrorResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Body:    NewValidationError(vErrs, trans),
+			Problem: registry.validation,
+		}
+	}
+
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return ErrorResponse{
+			Status: http.StatusBadRequest,
+			Body: FiberError{
+				Error:  decodeErr.Message,
+				Fields: registry.decodeFields(trans.Locale(), decodeErr.Fields),
+			},
+			Problem: registry.decode,
+		}
+	}
+
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		return ErrorResponse{
+			Status:  http.StatusUnsupportedMediaType,
+			Body:    FiberError{Error: err.Error()},
+			Problem: registry.media,
+		}
+	}
+
+	for _, entry := range registry.entries {
+		if errors.Is(err, entry.err) {
+			return ErrorResponse{
+				Status:  entry.problem.Status,
+				Body:    FiberError{Error: err.Error()},
+				Problem: entry.problem,
+			}
+		}
+	}
+
+	return ErrorResponse{
+		Status:  http.StatusInternalServerError,
+		Body:    FiberError{Error: "internal server error"},
+		Problem: registry.internal,
+	}
+}
+
+// decodeFields описывает ошибки полей DecodeError на языке locale. Тексты
+// берутся из Messages по коду ошибки поля, а без перевода - из decodeMessages.
+func (registry *ProblemRegistry) decodeFields(locale string, fErrs []DecodeFieldError) []FieldError {
+	if len(fErrs) == 0 {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(fErrs))
+	for _, fErr := range fErrs {
+		text, ok := registry.messages[locale][fErr.Code]
+		if !ok {
+			text = decodeMessages[fErr.Code]
+		}
+
+		fields = append(fields, FieldError{
+			Field:       fErr.Field,
+			Description: strings.NewReplacer("{0}", fErr.Field, "{1}", fErr.Param).Replace(text),
+		})
+	}
+	return fields
+}
+
+func (registry *ProblemRegistry) problemType(status int, code, title string) ProblemType {
+	return ProblemType{
+		Type:   registry.baseURI + strings.ReplaceAll(code, "_", "-"),
+		Title:  title,
+		Status: status,
+		Code:   code,
+	}
+}
@@ -0,0 +1,202 @@
+package httpext_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/noredis/subscriptions/pkg/httpext"
+	"github.com/noredis/subscriptions/pkg/validatorext"
+)
+
+var errThingNotFound = errors.New("thing not found")
+
+func newProblemRegistry(t *testing.T) *httpext.ProblemRegistry {
+	t.Helper()
+
+	uni, err := validatorext.NewTranslator(validator.New())
+	if err != nil {
+		t.Fatalf("new translator: %v", err)
+	}
+
+	return httpext.NewProblemRegistry("/problems/", uni).
+		Register(errThingNotFound, http.StatusNotFound, "thing_not_found", "Thing not found").
+		Messages("ru", map[string]string{"thing_not_found": "вещь не найдена"})
+}
+
+// sendProblem отправляет ошибку err с тестового маршрута и возвращает ответ
+// и его тело.
+func sendProblem(
+	t *testing.T,
+	registry *httpext.ProblemRegistry,
+	accept string,
+	language string,
+	err error,
+) (*http.Response, string) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/things/:id", func(c *fiber.Ctx) error {
+		return httpext.Send(c, registry.Resolve(c, err))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/things/42", nil)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	if language != "" {
+		req.Header.Set(fiber.HeaderAcceptLanguage, language)
+	}
+
+	resp, testErr := app.Test(req)
+	if testErr != nil {
+		t.Fatalf("request: %v", testErr)
+	}
+	defer resp.Body.Close()
+
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		t.Fatalf("read body: %v", readErr)
+	}
+	return resp, string(body)
+}
+
+func TestSendNegotiation(t *testing.T) {
+	registry := newProblemRegistry(t)
+
+	tests := []struct {
+		name        string
+		accept      string
+		problem     bool
+		contentType string
+	}{
+		{
+			name:        "no accept",
+			contentType: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "json",
+			accept:      fiber.MIMEApplicationJSON,
+			contentType: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:        "problem json",
+			accept:      httpext.ContentTypeProblemJSON,
+			problem:     true,
+			contentType: httpext.ContentTypeProblemJSON,
+		},
+		{
+			name:        "problem json among others",
+			accept:      "application/json, Application/Problem+JSON;q=0.9",
+			problem:     true,
+			contentType: httpext.ContentTypeProblemJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := sendProblem(t, registry, tt.accept, "", errThingNotFound)
+
+			if resp.StatusCode != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", resp.StatusCode)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, tt.contentType) {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+
+			if !tt.problem {
+				var legacy httpext.FiberError
+				if err := json.Unmarshal([]byte(body), &legacy); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if legacy.Error != errThingNotFound.Error() {
+					t.Fatalf("error = %q, want %q", legacy.Error, errThingNotFound.Error())
+				}
+				return
+			}
+
+			var problem httpext.Problem
+			if err := json.Unmarshal([]byte(body), &problem); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			want := httpext.Problem{
+				Type:     "/problems/thing-not-found",
+				Title:    "Thing not found",
+				Status:   http.StatusNotFound,
+				Detail:   errThingNotFound.Error(),
+				Instance: "/things/42",
+				Code:     "thing_not_found",
+			}
+			if problem.Type != want.Type || problem.Title != want.Title || problem.Status != want.Status ||
+				problem.Detail != want.Detail || problem.Instance != want.Instance || problem.Code != want.Code {
+				t.Fatalf("problem = %+v, want %+v", problem, want)
+			}
+		})
+	}
+}
+
+func TestResolveLanguage(t *testing.T) {
+	registry := newProblemRegistry(t)
+
+	resp, body := sendProblem(t, registry, "", "ru-RU,ru;q=0.9", errThingNotFound)
+
+	if got := resp.Header.Get(fiber.HeaderContentLanguage); got != "ru" {
+		t.Fatalf("Content-Language = %q, want ru", got)
+	}
+	if !strings.Contains(body, "вещь не найдена") {
+		t.Fatalf("body = %s, want translated message", body)
+	}
+}
+
+func TestResolveWrapped(t *testing.T) {
+	registry := newProblemRegistry(t)
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "wrapped", err: fmt.Errorf("load thing 42: %w", errThingNotFound)},
+		{name: "joined", err: errors.Join(errors.New("cache miss"), errThingNotFound)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := sendProblem(t, registry, httpext.ContentTypeProblemJSON, "", tt.err)
+
+			if resp.StatusCode != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", resp.StatusCode)
+			}
+			if !strings.Contains(body, `"code":"thing_not_found"`) {
+				t.Fatalf("body = %s, want thing_not_found", body)
+			}
+		})
+	}
+}
+
+func TestResolveInternalError(t *testing.T) {
+	registry := newProblemRegistry(t)
+	err := fmt.Errorf("query things: %w", errors.New("connect to postgres://app:s3cret@db:5432 failed"))
+
+	for _, accept := range []string{"", httpext.ContentTypeProblemJSON} {
+		t.Run("accept "+accept, func(t *testing.T) {
+			resp, body := sendProblem(t, registry, accept, "", err)
+
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", resp.StatusCode)
+			}
+			if strings.Contains(body, "s3cret") || strings.Contains(body, "postgres") {
+				t.Fatalf("body leaks error details: %s", body)
+			}
+			if !strings.Contains(body, "internal server error") {
+				t.Fatalf("body = %s, want generic message", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+
+	// minRefreshInterval ограничивает перезагрузку JWKS при неизвестном kid.
+	minRefreshInterval = time.Minute
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type Config struct {
+	Algorithm   string
+	Secret      string
+	JWKSFile    string
+	JWKSURL     string
+	JWKSRefresh time.Duration
+	Issuer      string
+	Audience    string
+}
+
+type Verifier struct {
+	cfg    Config
+	parser *jwt.Parser
+	client *http.Client
+
+	// refreshMu пропускает к JWKS один запрос за раз.
+	refreshMu sync.Mutex
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+	// attemptedAt — время последней попытки загрузить ключи, в том числе
+	// неудачной: пока JWKS недоступен, попытки не должны учащаться.
+	attemptedAt time.Time
+}
+
+func NewVerifier(ctx context.Context, cfg Config) (*Verifier, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	v := &Verifier{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	switch cfg.Algorithm {
+	case AlgHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("secret is required for HS256")
+		}
+	case AlgRS256:
+		if err := v.loadKeys(ctx); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	return v, nil
+}
+
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		if v.cfg.Algorithm == AlgHS256 {
+			return []byte(v.cfg.Secret), nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	key, ok := v.lookup(kid)
+	if v.cfg.JWKSURL == "" {
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+		}
+		return key, nil
+	}
+
+	stale := v.cfg.JWKSRefresh > 0 && v.refreshDue(v.cfg.JWKSRefresh)
+	if ok && !stale {
+		return key, nil
+	}
+
+	// Набор ключей перезагружается по расписанию или при неизвестном kid,
+	// но не чаще minRefreshInterval, чтобы токены с чужим kid не нагружали JWKS.
+	interval := minRefreshInterval
+	if v.cfg.JWKSRefresh > 0 {
+		interval = min(interval, v.cfg.JWKSRefresh)
+	}
+	if err := v.refresh(ctx, interval); err != nil && !ok {
+		return nil, err
+	}
+
+	if key, ok = v.lookup(kid); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// refresh перезагружает ключи, если с прошлой попытки прошло не меньше interval.
+// Параллельные запросы ждут текущую попытку и используют её результат, а не
+// обращаются к JWKS сами.
+func (v *Verifier) refresh(ctx context.Context, interval time.Duration) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	if !v.refreshDue(interval) {
+		return nil
+	}
+
+	// Отмена запроса, который начал загрузку, не должна срывать её для остальных.
+	return v.loadKeys(context.WithoutCancel(ctx))
+}
+
+// lookup ищет ключ по kid. Токен без kid принимается, только если ключ единственный.
+func (v *Verifier) lookup(kid string) (*rsa.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *Verifier) refreshDue(interval time.Duration) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return time.Since(v.attemptedAt) >= interval
+}
+
+func (v *Verifier) loadKeys(ctx context.Context) error {
+	v.mu.Lock()
+	v.attemptedAt = time.Now()
+	v.mu.Unlock()
+
+	var (
+		keys map[string]*rsa.PublicKey
+		err  error
+	)
+
+	switch {
+	case v.cfg.JWKSFile != "":
+		keys, err = loadJWKSFile(v.cfg.JWKSFile)
+	case v.cfg.JWKSURL != "":
+		keys, err = loadJWKSURL(ctx, v.client, v.cfg.JWKSURL)
+	default:
+		return errors.New("jwks file or url is required for RS256")
+	}
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.keys = keys
+	return nil
+}
@@ -0,0 +1,417 @@
+package jwtauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/noredis/subscriptions/pkg/jwtauth"
+)
+
+const secret = "test-secret"
+
+func hsToken(t *testing.T, key string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func rsToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		"iss": "https://issuer.example",
+		"aud": "subscriptions",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func with(claims jwt.MapClaims, key string, value any) jwt.MapClaims {
+	claims[key] = value
+	return claims
+}
+
+func without(claims jwt.MapClaims, key string) jwt.MapClaims {
+	delete(claims, key)
+	return claims
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	return key
+}
+
+func jwksJSON(t *testing.T, keys map[string]*rsa.PrivateKey) []byte {
+	t.Helper()
+
+	set := map[string][]map[string]string{"keys": {}}
+	for kid, key := range keys {
+		set["keys"] = append(set["keys"], map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	return data
+}
+
+func writeFile(t *testing.T, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	return path
+}
+
+func TestNewVerifier(t *testing.T) {
+	key := newRSAKey(t)
+
+	tests := []struct {
+		name    string
+		cfg     jwtauth.Config
+		wantErr bool
+	}{
+		{name: "hs256", cfg: jwtauth.Config{Algorithm: jwtauth.AlgHS256, Secret: secret}},
+		{name: "hs256 without secret", cfg: jwtauth.Config{Algorithm: jwtauth.AlgHS256}, wantErr: true},
+		{name: "unsupported algorithm", cfg: jwtauth.Config{Algorithm: "none", Secret: secret}, wantErr: true},
+		{name: "rs256 without jwks", cfg: jwtauth.Config{Algorithm: jwtauth.AlgRS256}, wantErr: true},
+		{
+			name: "rs256 with jwks file",
+			cfg: jwtauth.Config{
+				Algorithm: jwtauth.AlgRS256,
+				JWKSFile:  writeFile(t, jwksJSON(t, map[string]*rsa.PrivateKey{"k1": key})),
+			},
+		},
+		{
+			name:    "invalid jwks",
+			cfg:     jwtauth.Config{Algorithm: jwtauth.AlgRS256, JWKSFile: writeFile(t, []byte("{"))},
+			wantErr: true,
+		},
+		{
+			name: "jwks without rsa signing keys",
+			cfg: jwtauth.Config{
+				Algorithm: jwtauth.AlgRS256,
+				JWKSFile:  writeFile(t, []byte(`{"keys":[{"kty":"EC","kid":"e1"},{"kty":"RSA","kid":"k2","use":"enc"}]}`)),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwtauth.NewVerifier(context.Background(), tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewVerifier error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyHS256(t *testing.T) {
+	verifier, err := jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+		Algorithm: jwtauth.AlgHS256,
+		Secret:    secret,
+		Issuer:    "https://issuer.example",
+		Audience:  "subscriptions",
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: hsToken(t, secret, validClaims())},
+		{
+			name:    "expired",
+			token:   hsToken(t, secret, with(validClaims(), "exp", time.Now().Add(-time.Minute).Unix())),
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "without exp",
+			token:   hsToken(t, secret, without(validClaims(), "exp")),
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:    "not valid yet",
+			token:   hsToken(t, secret, with(validClaims(), "nbf", time.Now().Add(time.Hour).Unix())),
+			wantErr: jwt.ErrTokenNotValidYet,
+		},
+		{
+			name:    "wrong secret",
+			token:   hsToken(t, "other-secret", validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "wrong issuer",
+			token:   hsToken(t, secret, with(validClaims(), "iss", "https://evil.example")),
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "wrong audience",
+			token:   hsToken(t, secret, with(validClaims(), "aud", "billing")),
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:    "other algorithm",
+			token:   rsToken(t, newRSAKey(t), "", validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "unsigned",
+			token:   unsignedToken(t, validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{name: "malformed", token: "not.a.token", wantErr: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				if sub, _ := claims.GetSubject(); sub != "60601fee-2bf1-4721-ae6f-7636e79a0cba" {
+					t.Fatalf("sub = %q", sub)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func unsignedToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestVerifyRS256File(t *testing.T) {
+	k1, k2, unknown := newRSAKey(t), newRSAKey(t), newRSAKey(t)
+
+	single, err := jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+		Algorithm: jwtauth.AlgRS256,
+		JWKSFile:  writeFile(t, jwksJSON(t, map[string]*rsa.PrivateKey{"k1": k1})),
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	multiple, err := jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+		Algorithm: jwtauth.AlgRS256,
+		JWKSFile:  writeFile(t, jwksJSON(t, map[string]*rsa.PrivateKey{"k1": k1, "k2": k2})),
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier *jwtauth.Verifier
+		token    string
+		wantErr  error
+	}{
+		{name: "kid k1", verifier: multiple, token: rsToken(t, k1, "k1", validClaims())},
+		{name: "kid k2", verifier: multiple, token: rsToken(t, k2, "k2", validClaims())},
+		{name: "without kid and single key", verifier: single, token: rsToken(t, k1, "", validClaims())},
+		{
+			name:     "without kid and several keys",
+			verifier: multiple,
+			token:    rsToken(t, k1, "", validClaims()),
+			wantErr:  jwtauth.ErrUnknownKey,
+		},
+		{
+			name:     "unknown kid",
+			verifier: multiple,
+			token:    rsToken(t, unknown, "k3", validClaims()),
+			wantErr:  jwtauth.ErrUnknownKey,
+		},
+		{
+			name:     "signed by other key",
+			verifier: multiple,
+			token:    rsToken(t, unknown, "k1", validClaims()),
+			wantErr:  jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:     "hs256 token",
+			verifier: multiple,
+			token:    hsToken(t, secret, validClaims()),
+			wantErr:  jwt.ErrTokenSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.verifier.Verify(context.Background(), tt.token)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRS256URLRotation(t *testing.T) {
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+
+	var (
+		current  atomic.Value
+		requests atomic.Int32
+	)
+	current.Store(jwksJSON(t, map[string]*rsa.PrivateKey{"old": oldKey}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Write(current.Load().([]byte))
+	}))
+	defer server.Close()
+
+	verifier, err := jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+		Algorithm:   jwtauth.AlgRS256,
+		JWKSURL:     server.URL,
+		JWKSRefresh: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	if _, err := verifier.Verify(context.Background(), rsToken(t, oldKey, "old", validClaims())); err != nil {
+		t.Fatalf("Verify(old): %v", err)
+	}
+
+	// После ротации набор ключей перечитывается, и токен с новым kid принимается.
+	current.Store(jwksJSON(t, map[string]*rsa.PrivateKey{"new": newKey}))
+	if _, err := verifier.Verify(context.Background(), rsToken(t, newKey, "new", validClaims())); err != nil {
+		t.Fatalf("Verify(new): %v", err)
+	}
+	if _, err := verifier.Verify(context.Background(), rsToken(t, oldKey, "old", validClaims())); !errors.Is(err, jwtauth.ErrUnknownKey) {
+		t.Fatalf("Verify(old) after rotation error = %v, want ErrUnknownKey", err)
+	}
+
+	if requests.Load() < 2 {
+		t.Fatalf("jwks requests = %d, want reload after rotation", requests.Load())
+	}
+}
+
+func TestVerifyRS256URLFailureThrottled(t *testing.T) {
+	const refresh = 200 * time.Millisecond
+
+	key := newRSAKey(t)
+
+	var (
+		failing  atomic.Bool
+		requests atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			// Медленный ответ, чтобы параллельные запросы застали загрузку.
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(jwksJSON(t, map[string]*rsa.PrivateKey{"current": key}))
+	}))
+	defer server.Close()
+
+	verifier, err := jwtauth.NewVerifier(context.Background(), jwtauth.Config{
+		Algorithm:   jwtauth.AlgRS256,
+		JWKSURL:     server.URL,
+		JWKSRefresh: refresh,
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	failing.Store(true)
+
+	unknown := rsToken(t, key, "unknown", validClaims())
+	verifyConcurrently := func() {
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Go(func() {
+				if _, err := verifier.Verify(context.Background(), unknown); err == nil {
+					t.Error("Verify(unknown) succeeded")
+				}
+			})
+		}
+		wg.Wait()
+	}
+
+	// Попытка, которую не выполнили, не должна повторяться до конца интервала.
+	verifyConcurrently()
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("jwks requests = %d, want 1 before interval", got)
+	}
+
+	time.Sleep(refresh)
+	verifyConcurrently()
+	verifyConcurrently()
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("jwks requests = %d, want 2 after one interval", got)
+	}
+
+	// Известный ключ продолжает работать, пока JWKS недоступен.
+	if _, err := verifier.Verify(context.Background(), rsToken(t, key, "current", validClaims())); err != nil {
+		t.Fatalf("Verify(current): %v", err)
+	}
+}
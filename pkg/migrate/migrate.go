@@ -0,0 +1,283 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// NilVersion означает, что ни одна миграция не применена.
+const NilVersion = -1
+
+var (
+	ErrDirty           = errors.New("database is dirty, fix it manually and run force")
+	ErrUnknownVersion  = errors.New("unknown migration version")
+	ErrNothingToRevert = errors.New("no migrations to revert")
+)
+
+var fileRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Driver хранит версию схемы в таблице schema_migrations
+// в формате, совместимом с golang-migrate.
+type Driver interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+	Version(ctx context.Context) (version int, dirty bool, err error)
+	SetVersion(ctx context.Context, version int, dirty bool) error
+	Exec(ctx context.Context, query string) error
+}
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version    int
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+type Migrator struct {
+	driver     Driver
+	migrations []Migration
+	logger     Logger
+}
+
+func New(driver Driver, fsys fs.FS, logger Logger) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		driver:     driver,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// Up применяет все неприменённые миграции.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down откатывает последнюю применённую миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.locked(ctx, func(current int) error {
+		idx := m.index(current)
+		if idx < 0 {
+			return ErrNothingToRevert
+		}
+		return m.down(ctx, idx)
+	})
+}
+
+// To применяет или откатывает миграции до указанной версии. Версия 0 откатывает все миграции.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	target := -1
+	if version != 0 {
+		target = m.index(version)
+		if target < 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+		}
+	}
+
+	return m.locked(ctx, func(current int) error {
+		idx := m.index(current)
+
+		for ; idx < target; idx++ {
+			if err := m.up(ctx, idx+1); err != nil {
+				return err
+			}
+		}
+
+		for ; idx > target; idx-- {
+			if err := m.down(ctx, idx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Force устанавливает версию без выполнения миграций и снимает флаг dirty.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version != NilVersion && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	if err := m.driver.Lock(ctx); err != nil {
+		return err
+	}
+	defer m.unlock(ctx)
+
+	return m.driver.SetVersion(ctx, version, false)
+}
+
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	version, dirty, err := m.driver.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Version:    version,
+		Dirty:      dirty,
+		Migrations: make([]MigrationStatus, 0, len(m.migrations)),
+	}
+
+	for _, migration := range m.migrations {
+		status.Migrations = append(status.Migrations, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: version != NilVersion && migration.Version <= version,
+		})
+	}
+
+	return status, nil
+}
+
+func (m *Migrator) locked(ctx context.Context, fn func(current int) error) error {
+	if err := m.driver.Lock(ctx); err != nil {
+		return err
+	}
+	defer m.unlock(ctx)
+
+	current, dirty, err := m.driver.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrDirty, current)
+	}
+	if current != NilVersion && m.index(current) < 0 {
+		return fmt.Errorf("%w: database is at %d", ErrUnknownVersion, current)
+	}
+
+	return fn(current)
+}
+
+func (m *Migrator) up(ctx context.Context, idx int) error {
+	migration := m.migrations[idx]
+	m.logger.Printf("applying migration %d_%s\n", migration.Version, migration.Name)
+
+	if err := m.driver.SetVersion(ctx, migration.Version, true); err != nil {
+		return err
+	}
+
+	if err := m.driver.Exec(ctx, migration.Up); err != nil {
+		return fmt.Errorf("migration %d failed: %w", migration.Version, err)
+	}
+
+	return m.driver.SetVersion(ctx, migration.Version, false)
+}
+
+func (m *Migrator) down(ctx context.Context, idx int) error {
+	migration := m.migrations[idx]
+	m.logger.Printf("reverting migration %d_%s\n", migration.Version, migration.Name)
+
+	prev := NilVersion
+	if idx > 0 {
+		prev = m.migrations[idx-1].Version
+	}
+
+	if err := m.driver.SetVersion(ctx, prev, true); err != nil {
+		return err
+	}
+
+	if err := m.driver.Exec(ctx, migration.Down); err != nil {
+		return fmt.Errorf("migration %d rollback failed: %w", migration.Version, err)
+	}
+
+	return m.driver.SetVersion(ctx, prev, false)
+}
+
+func (m *Migrator) unlock(ctx context.Context) {
+	if err := m.driver.Unlock(ctx); err != nil {
+		m.logger.Printf("failed to release migration lock: %v\n", err)
+	}
+}
+
+// index возвращает позицию миграции с указанной версией или -1.
+func (m *Migrator) index(version int) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := fileRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
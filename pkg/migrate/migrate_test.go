@@ -0,0 +1,132 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/noredis/subscriptions/pkg/migrate"
+	"github.com/noredis/subscriptions/pkg/sqlite"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_create_items.up.sql":   {Data: []byte("CREATE TABLE items(id INTEGER PRIMARY KEY);")},
+	"000001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+	"000002_add_name.up.sql":       {Data: []byte("ALTER TABLE items ADD COLUMN name TEXT;")},
+	"000002_add_name.down.sql":     {Data: []byte("ALTER TABLE items DROP COLUMN name;")},
+}
+
+func newMigrator(t *testing.T) (*migrate.Migrator, *sql.DB) {
+	t.Helper()
+
+	db, err := sqlite.New(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(migrate.NewSQLiteDriver(db), testMigrations, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	return migrator, db
+}
+
+func TestStatusDoesNotCreateTable(t *testing.T) {
+	migrator, db := newMigrator(t)
+
+	status, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Version != migrate.NilVersion || status.Dirty {
+		t.Fatalf("Status = %d (dirty %t), want NilVersion", status.Version, status.Dirty)
+	}
+
+	var tables int
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables)
+	if err != nil {
+		t.Fatalf("query sqlite_master: %v", err)
+	}
+	if tables != 0 {
+		t.Fatal("Status created schema_migrations")
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context, m *migrate.Migrator) error
+		version int
+		applied []bool
+	}{
+		{
+			name:    "up applies all",
+			run:     func(ctx context.Context, m *migrate.Migrator) error { return m.Up(ctx) },
+			version: 2,
+			applied: []bool{true, true},
+		},
+		{
+			name: "down reverts last",
+			run: func(ctx context.Context, m *migrate.Migrator) error {
+				if err := m.Up(ctx); err != nil {
+					return err
+				}
+				return m.Down(ctx)
+			},
+			version: 1,
+			applied: []bool{true, false},
+		},
+		{
+			name:    "to version",
+			run:     func(ctx context.Context, m *migrate.Migrator) error { return m.To(ctx, 1) },
+			version: 1,
+			applied: []bool{true, false},
+		},
+		{
+			name: "to zero reverts all",
+			run: func(ctx context.Context, m *migrate.Migrator) error {
+				if err := m.Up(ctx); err != nil {
+					return err
+				}
+				return m.To(ctx, 0)
+			},
+			version: migrate.NilVersion,
+			applied: []bool{false, false},
+		},
+		{
+			name:    "force sets version",
+			run:     func(ctx context.Context, m *migrate.Migrator) error { return m.Force(ctx, 2) },
+			version: 2,
+			applied: []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			migrator, _ := newMigrator(t)
+
+			if err := tt.run(ctx, migrator); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			status, err := migrator.Status(ctx)
+			if err != nil {
+				t.Fatalf("Status: %v", err)
+			}
+			if status.Version != tt.version || status.Dirty {
+				t.Fatalf("Status = %d (dirty %t), want %d", status.Version, status.Dirty, tt.version)
+			}
+			for i, migration := range status.Migrations {
+				if migration.Applied != tt.applied[i] {
+					t.Fatalf("migration %d applied = %t, want %t", migration.Version, migration.Applied, tt.applied[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	advisoryLockID = 7294823106
+
+	// undefinedTable — код ошибки PostgreSQL для несуществующей таблицы.
+	undefinedTable = "42P01"
+)
+
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type PostgresDriver struct {
+	pool *pgxpool.Pool
+	conn *pgxpool.Conn
+}
+
+func NewPostgresDriver(pool *pgxpool.Pool) *PostgresDriver {
+	return &PostgresDriver{pool: pool}
+}
+
+func (driver *PostgresDriver) Lock(ctx context.Context) error {
+	conn, err := driver.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		conn.Release()
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	driver.conn = conn
+	return nil
+}
+
+func (driver *PostgresDriver) Unlock(ctx context.Context) error {
+	if driver.conn == nil {
+		return nil
+	}
+	defer func() {
+		driver.conn.Release()
+		driver.conn = nil
+	}()
+
+	_, err := driver.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockID)
+	return err
+}
+
+// Version только читает таблицу schema_migrations: её вызывает проба готовности,
+// поэтому отсутствующая таблица означает, что миграции не применялись.
+func (driver *PostgresDriver) Version(ctx context.Context) (int, bool, error) {
+	var (
+		version int
+		dirty   bool
+	)
+	err := driver.querier().
+		QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&version, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == undefinedTable) {
+			return NilVersion, false, nil
+		}
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+func (driver *PostgresDriver) SetVersion(ctx context.Context, version int, dirty bool) error {
+	if err := driver.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, driver.querier(), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "TRUNCATE schema_migrations"); err != nil {
+			return err
+		}
+
+		if version >= 0 || (version == NilVersion && dirty) {
+			_, err := tx.Exec(
+				ctx,
+				"INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)",
+				version,
+				dirty,
+			)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (driver *PostgresDriver) Exec(ctx context.Context, query string) error {
+	_, err := driver.querier().Exec(ctx, query)
+	return err
+}
+
+func (driver *PostgresDriver) ensureTable(ctx context.Context) error {
+	_, err := driver.querier().Exec(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations "+
+			"(version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)",
+	)
+	return err
+}
+
+// querier возвращает соединение, удерживающее блокировку, чтобы миграции
+// не требовали второго соединения из пула.
+func (driver *PostgresDriver) querier() pgQuerier {
+	if driver.conn != nil {
+		return driver.conn
+	}
+	return driver.pool
+}
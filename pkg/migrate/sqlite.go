@@ -0,0 +1,99 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SQLiteDriver не использует блокировку: база SQLite принадлежит одному процессу.
+type SQLiteDriver struct {
+	db *sql.DB
+}
+
+func NewSQLiteDriver(db *sql.DB) *SQLiteDriver {
+	return &SQLiteDriver{db: db}
+}
+
+func (driver *SQLiteDriver) Lock(context.Context) error {
+	return nil
+}
+
+func (driver *SQLiteDriver) Unlock(context.Context) error {
+	return nil
+}
+
+// Version только читает таблицу schema_migrations: отсутствующая таблица
+// означает, что миграции не применялись.
+func (driver *SQLiteDriver) Version(ctx context.Context) (int, bool, error) {
+	var exists bool
+	err := driver.db.
+		QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')").
+		Scan(&exists)
+	if err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return NilVersion, false, nil
+	}
+
+	var (
+		version int
+		dirty   bool
+	)
+	err = driver.db.
+		QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NilVersion, false, nil
+		}
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+func (driver *SQLiteDriver) SetVersion(ctx context.Context, version int, dirty bool) error {
+	if err := driver.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	tx, err := driver.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+
+	if version >= 0 || (version == NilVersion && dirty) {
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)",
+			version,
+			dirty,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (driver *SQLiteDriver) Exec(ctx context.Context, query string) error {
+	_, err := driver.db.ExecContext(ctx, query)
+	return err
+}
+
+func (driver *SQLiteDriver) ensureTable(ctx context.Context) error {
+	_, err := driver.db.ExecContext(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version uint64, dirty bool);"+
+			"CREATE UNIQUE INDEX IF NOT EXISTS version_unique ON schema_migrations (version);",
+	)
+	return err
+}
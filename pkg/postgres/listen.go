@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const listenRetryDelay = time.Second
+
+// Listener выполняет LISTEN канала на отдельном соединении и передаёт
+// уведомления подписчикам. Соединение открывается при первой подписке и
+// восстанавливается после обрыва. Уведомления за время обрыва теряются,
+// поэтому после переподключения подписчики получают сигнал.
+type Listener struct {
+	pool    *pgxpool.Pool
+	channel string
+	logger  Logger
+
+	mu        sync.Mutex
+	listeners map[chan struct{}]struct{}
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closed    bool
+}
+
+func NewListener(pool *pgxpool.Pool, channel string, logger Logger) *Listener {
+	return &Listener{
+		pool:      pool,
+		channel:   channel,
+		logger:    logger,
+		listeners: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe возвращает канал сигналов об уведомлениях. Несколько
+// уведомлений могут прийти одним сигналом. Канал закрывается после отмены
+// ctx или вызова Close.
+func (l *Listener) Subscribe(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		close(ch)
+		return ch
+	}
+
+	if l.cancel == nil {
+		var listenCtx context.Context
+		listenCtx, l.cancel = context.WithCancel(context.Background())
+		l.done = make(chan struct{})
+		go l.run(listenCtx)
+	}
+
+	l.listeners[ch] = struct{}{}
+	context.AfterFunc(ctx, func() { l.unsubscribe(ch) })
+	return ch
+}
+
+// Close останавливает прослушивание и закрывает каналы подписчиков.
+func (l *Listener) Close() {
+	l.mu.Lock()
+	l.closed = true
+	cancel, done := l.cancel, l.done
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.listeners {
+		delete(l.listeners, ch)
+		close(ch)
+	}
+}
+
+func (l *Listener) unsubscribe(ch chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.listeners[ch]; ok {
+		delete(l.listeners, ch)
+		close(ch)
+	}
+}
+
+func (l *Listener) run(ctx context.Context) {
+	defer close(l.done)
+
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		l.logger.Printf("listen %s failed: %v. Reconnecting in %s...\n", l.channel, err, listenRetryDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Соединение с LISTEN не возвращается в пул: оно закрывается при выходе.
+	conn := pooled.Hijack()
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), listenRetryDelay)
+		defer cancel()
+		conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+	l.broadcast()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		l.broadcast()
+	}
+}
+
+func (l *Listener) broadcast() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LockKey переводит имя блокировки в ключ pg_advisory_lock.
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// TryAdvisoryLock захватывает сессионную advisory-блокировку key без
+// ожидания. Блокировка удерживается отдельным соединением из пула до вызова
+// unlock; если процесс завершится, PostgreSQL освободит её вместе с сессией.
+func TryAdvisoryLock(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	key int64,
+) (unlock func(ctx context.Context) error, ok bool, err error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	return func(ctx context.Context) error {
+		defer conn.Release()
+
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// Соединение с неснятой блокировкой нельзя возвращать в пул.
+			conn.Conn().Close(ctx)
+			return fmt.Errorf("failed to release advisory lock: %w", err)
+		}
+		return nil
+	}, true, nil
+}
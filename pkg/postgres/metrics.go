@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsCollector экспортирует статистику pgxpool в Prometheus.
+type StatsCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+}
+
+func NewStatsCollector(pool *pgxpool.Pool) *StatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("pgxpool", "", name), help, nil, nil)
+	}
+
+	return &StatsCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Number of currently acquired connections."),
+		idleConns:            desc("idle_conns", "Number of currently idle connections."),
+		totalConns:           desc("total_conns", "Total number of connections in the pool."),
+		maxConns:             desc("max_conns", "Maximum size of the pool."),
+		acquireCount:         desc("acquire_count_total", "Number of successful acquires."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquireCount:    desc("empty_acquire_count_total", "Number of acquires that waited for a connection."),
+		canceledAcquireCount: desc("canceled_acquire_count_total", "Number of acquires canceled by context."),
+	}
+}
+
+func (collector *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.acquiredConns
+	ch <- collector.idleConns
+	ch <- collector.totalConns
+	ch <- collector.maxConns
+	ch <- collector.acquireCount
+	ch <- collector.acquireDuration
+	ch <- collector.emptyAcquireCount
+	ch <- collector.canceledAcquireCount
+}
+
+func (collector *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := collector.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(collector.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(collector.idleConns, float64(stat.IdleConns()))
+	gauge(collector.totalConns, float64(stat.TotalConns()))
+	gauge(collector.maxConns, float64(stat.MaxConns()))
+	counter(collector.acquireCount, float64(stat.AcquireCount()))
+	counter(collector.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(collector.emptyAcquireCount, float64(stat.EmptyAcquireCount()))
+	counter(collector.canceledAcquireCount, float64(stat.CanceledAcquireCount()))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,18 +13,48 @@ type Logger interface {
 	Printf(format string, v ...any)
 }
 
+type Option func(cfg *pgxpool.Config)
+
+// WithSessionSetting перед каждой выдачей соединения из пула устанавливает параметр
+// сеанса name в значение, вычисленное по контексту запроса. Так политики
+// row-level security получают данные запроса через current_setting.
+// Несколько опций устанавливают параметры по очереди.
+func WithSessionSetting(name string, value func(ctx context.Context) string) Option {
+	return func(cfg *pgxpool.Config) {
+		prepare := cfg.PrepareConn
+		cfg.PrepareConn = func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+			if prepare != nil {
+				if ok, err := prepare(ctx, conn); !ok || err != nil {
+					return ok, err
+				}
+			}
+
+			_, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", name, value(ctx))
+			if err != nil {
+				return true, fmt.Errorf("failed to set %s: %w", name, err)
+			}
+			return true, nil
+		}
+	}
+}
+
 func New(
 	ctx context.Context,
 	dsn string,
 	attempts int,
 	delay time.Duration,
 	logger Logger,
+	opts ...Option,
 ) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
 
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/pkg/validatorext"
+)
+
+// dateLayout — формат дат MM-YYYY для time.Parse.
+const dateLayout = "01-2006"
+
+// MinYear и MaxYear ограничивают год в датах MM-YYYY.
+const (
+	MinYear = 2000
+	MaxYear = 2100
+)
+
+// DateRangeTag — тег ошибки периода, который заканчивается раньше, чем начинается.
+const DateRangeTag = "date_range"
+
+// DateYear проверяет, что год даты MM-YYYY лежит в пределах [MinYear, MaxYear].
+// Даты в другом формате пропускаются, их отклоняет DateFormat.
+func DateYear(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+
+	if !dateRegexp.MatchString(value) {
+		return true
+	}
+
+	year, _ := strconv.Atoi(value[3:])
+	return year >= MinYear && year <= MaxYear
+}
+
+// DateRange возвращает проверку уровня структуры: дата в поле endField не
+// раньше даты в поле startField. Ошибка относится к полю endField, её
+// параметр - имя поля startField. Пустые и некорректные даты пропускаются.
+func DateRange(startField, endField string) validator.StructLevelFunc {
+	return func(sl validator.StructLevel) {
+		current := sl.Current()
+
+		start, err := time.Parse(dateLayout, current.FieldByName(startField).String())
+		if err != nil {
+			return
+		}
+
+		end := current.FieldByName(endField).String()
+		endDate, err := time.Parse(dateLayout, end)
+		if err != nil {
+			return
+		}
+
+		if endDate.Before(start) {
+			sl.ReportError(
+				end,
+				fieldName(current.Type(), endField),
+				endField,
+				DateRangeTag,
+				fieldName(current.Type(), startField),
+			)
+		}
+	}
+}
+
+// fieldName возвращает имя поля в запросе, как его показывает validatorext.FieldTag.
+func fieldName(typ reflect.Type, name string) string {
+	field, ok := typ.FieldByName(name)
+	if !ok {
+		return name
+	}
+
+	if tag := validatorext.FieldTag(field); tag != "" {
+		return tag
+	}
+	return name
+}
@@ -0,0 +1,96 @@
+package rules_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/noredis/subscriptions/pkg/rules"
+	"github.com/noredis/subscriptions/pkg/validatorext"
+)
+
+type period struct {
+	StartDate string `json:"start_date" validate:"omitempty,date_year"`
+	EndDate   string `json:"end_date,omitempty" validate:"omitempty,date_year"`
+}
+
+func newValidator(t *testing.T) *validator.Validate {
+	t.Helper()
+
+	validate := validator.New()
+	if err := validate.RegisterValidation("date_year", rules.DateYear); err != nil {
+		t.Fatalf("register date_year: %v", err)
+	}
+	validate.RegisterStructValidation(rules.DateRange("StartDate", "EndDate"), period{})
+	validate.RegisterTagNameFunc(validatorext.FieldTag)
+	return validate
+}
+
+func TestDateRange(t *testing.T) {
+	validate := newValidator(t)
+
+	tests := []struct {
+		name    string
+		period  period
+		wantErr bool
+	}{
+		{name: "end after start", period: period{StartDate: "01-2025", EndDate: "03-2025"}},
+		{name: "same month", period: period{StartDate: "07-2025", EndDate: "07-2025"}},
+		{name: "next year", period: period{StartDate: "12-2024", EndDate: "01-2025"}},
+		{name: "without end", period: period{StartDate: "07-2025"}},
+		{name: "invalid start is skipped", period: period{StartDate: "2025-07", EndDate: "01-2025"}},
+		{name: "invalid end is skipped", period: period{StartDate: "07-2025", EndDate: "13-2024"}},
+		{name: "end before start", period: period{StartDate: "07-2025", EndDate: "06-2025"}, wantErr: true},
+		// Сравнение строк MM-YYYY сочло бы такой период корректным.
+		{name: "previous year", period: period{StartDate: "01-2025", EndDate: "12-2024"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.period)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Struct: %v", err)
+				}
+				return
+			}
+
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("Struct error = %v, want one field error", err)
+			}
+
+			fieldErr := errs[0]
+			if fieldErr.Tag() != rules.DateRangeTag {
+				t.Fatalf("tag = %q, want %q", fieldErr.Tag(), rules.DateRangeTag)
+			}
+			if fieldErr.Field() != "end_date" || fieldErr.Param() != "start_date" {
+				t.Fatalf("field = %q, param = %q, want end_date and start_date", fieldErr.Field(), fieldErr.Param())
+			}
+		})
+	}
+}
+
+func TestDateYear(t *testing.T) {
+	validate := newValidator(t)
+
+	tests := []struct {
+		date    string
+		wantErr bool
+	}{
+		{date: "01-2000"},
+		{date: "12-2100"},
+		{date: "2025-07"},
+		{date: "12-1999", wantErr: true},
+		{date: "01-2101", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			err := validate.Struct(period{StartDate: tt.date})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Struct error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package rules
+
+const requestIDMaxLength = 128
+
+// IsRequestID отбрасывает пустые, слишком длинные и непечатаемые
+// идентификаторы запроса, чтобы клиент не мог испортить журнал.
+func IsRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package rules
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var tenantIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// IsTenantID проверяет, что идентификатор арендатора состоит из латинских букв,
+// цифр, '-' и '_' и не длиннее 64 символов.
+func IsTenantID(value string) bool {
+	return tenantIDRegexp.MatchString(value)
+}
+
+func TenantID(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+
+	if value == "" {
+		return true
+	}
+	return IsTenantID(value)
+}
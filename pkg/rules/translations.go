@@ -0,0 +1,19 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/noredis/subscriptions/pkg/validatorext"
+)
+
+// Translations — сообщения об ошибках правил пакета для validatorext.NewTranslator.
+var Translations = []validatorext.Translation{
+	{Locale: "en", Tag: "date_format", Text: "{0} must match the MM-YYYY format"},
+	{Locale: "ru", Tag: "date_format", Text: "{0} должен быть в формате ММ-ГГГГ"},
+	{Locale: "en", Tag: "date_year", Text: fmt.Sprintf("{0} must have a year between %d and %d", MinYear, MaxYear)},
+	{Locale: "ru", Tag: "date_year", Text: fmt.Sprintf("год в {0} должен быть от %d до %d", MinYear, MaxYear)},
+	{Locale: "en", Tag: DateRangeTag, Text: "{0} must not be earlier than {1}"},
+	{Locale: "ru", Tag: DateRangeTag, Text: "{0} не может быть раньше {1}"},
+	{Locale: "en", Tag: "tenant_id", Text: "{0} must contain only latin letters, digits, '-' and '_'"},
+	{Locale: "ru", Tag: "tenant_id", Text: "{0} может содержать только латинские буквы, цифры, '-' и '_'"},
+}
@@ -0,0 +1,36 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Заголовки подписанного запроса вебхука.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+const prefix = "sha256="
+
+// Sign подписывает тело запроса: "sha256=" и HMAC-SHA256 от строки
+// "<timestamp>.<body>" в hex. Временная метка в подписи не даёт повторно
+// отправить перехваченный запрос позже.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify сравнивает подпись за постоянное время. Проверять, что timestamp
+// достаточно свежий, должен получатель.
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
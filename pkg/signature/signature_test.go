@@ -0,0 +1,78 @@
+package signature_test
+
+import (
+	"testing"
+
+	"github.com/noredis/subscriptions/pkg/signature"
+)
+
+const (
+	secret    = "whsec_test"
+	timestamp = int64(1700000000)
+	body      = `{"id":"evt-1"}`
+	// want посчитан независимо:
+	// printf '1700000000.{"id":"evt-1"}' | openssl dgst -sha256 -hmac whsec_test
+	want = "sha256=5056f09710e0bebdbcd623bb1a7714db4eac94f18745b31b96dd55a69f444e14"
+)
+
+func TestSign(t *testing.T) {
+	if got := signature.Sign(secret, timestamp, []byte(body)); got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp int64
+		body      string
+		want      bool
+	}{
+		{
+			name:      "valid",
+			secret:    secret,
+			signature: want,
+			timestamp: timestamp,
+			body:      body,
+			want:      true,
+		},
+		{
+			name:      "wrong secret",
+			secret:    "whsec_other",
+			signature: want,
+			timestamp: timestamp,
+			body:      body,
+		},
+		{
+			name:      "other timestamp",
+			secret:    secret,
+			signature: want,
+			timestamp: timestamp + 1,
+			body:      body,
+		},
+		{
+			name:      "modified body",
+			secret:    secret,
+			signature: want,
+			timestamp: timestamp,
+			body:      `{"id":"evt-2"}`,
+		},
+		{
+			name:      "without prefix",
+			secret:    secret,
+			signature: want[len("sha256="):],
+			timestamp: timestamp,
+			body:      body,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signature.Verify(tt.secret, tt.signature, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Fatalf("Verify = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
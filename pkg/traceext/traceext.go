@@ -0,0 +1,61 @@
+package traceext
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// End завершает span и отмечает его ошибкой, если err не nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// StartQuery открывает span SQL-запроса. Текст запроса сохраняется в атрибуте
+// db.query.text, а первое слово запроса - в db.operation.name.
+func StartQuery(
+	ctx context.Context,
+	tracer trace.Tracer,
+	name string,
+	system attribute.KeyValue,
+	query string,
+) (context.Context, trace.Span) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			system,
+			semconv.DBOperationName(strings.ToUpper(operation)),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// LogHook добавляет trace_id и span_id в записи zerolog, к которым привязан
+// контекст с активным span (см. zerolog.Event.Ctx).
+type LogHook struct{}
+
+func (LogHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	ctx := e.GetCtx()
+	if ctx == nil {
+		return
+	}
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return
+	}
+
+	e.Str("trace_id", spanCtx.TraceID().String()).
+		Str("span_id", spanCtx.SpanID().String())
+}
@@ -0,0 +1,90 @@
+package validatorext
+
+import (
+	"fmt"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	rutranslations "github.com/go-playground/validator/v10/translations/ru"
+)
+
+// Locales — поддерживаемые языки сообщений. Первый используется по умолчанию.
+var Locales = []string{"en", "ru"}
+
+// Translation — текст ошибки правила валидации на одном языке.
+// {0} заменяется именем поля, {1} - параметром правила.
+type Translation struct {
+	Locale string
+	Tag    string
+	Text   string
+}
+
+// translations дополняют стандартные переводы validator правилами,
+// которых в них нет.
+var translations = []Translation{
+	{Locale: "ru", Tag: "required_unless", Text: "{0} обязательное поле"},
+	{Locale: "en", Tag: "http_url", Text: "{0} must be a valid HTTP or HTTPS URL"},
+	{Locale: "ru", Tag: "http_url", Text: "{0} должен быть HTTP или HTTPS URL"},
+}
+
+// NewTranslator регистрирует в validate стандартные переводы en и ru и
+// переводы custom, которые переопределяют стандартные для тех же правил.
+func NewTranslator(
+	validate *validator.Validate,
+	custom ...Translation,
+) (*ut.UniversalTranslator, error) {
+	english := en.New()
+	uni := ut.New(english, english, ru.New())
+
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": entranslations.RegisterDefaultTranslations,
+		"ru": rutranslations.RegisterDefaultTranslations,
+	}
+
+	for _, locale := range Locales {
+		trans, _ := uni.GetTranslator(locale)
+		if err := defaults[locale](validate, trans); err != nil {
+			return nil, fmt.Errorf("failed to register %s translations: %w", locale, err)
+		}
+	}
+
+	all := make([]Translation, 0, len(translations)+len(custom))
+	all = append(all, translations...)
+	all = append(all, custom...)
+
+	for _, translation := range all {
+		trans, found := uni.FindTranslator(translation.Locale)
+		if !found {
+			return nil, fmt.Errorf("unsupported locale %q", translation.Locale)
+		}
+
+		if err := register(validate, trans, translation); err != nil {
+			return nil, fmt.Errorf(
+				"failed to register %s translation for %q: %w",
+				translation.Locale, translation.Tag, err,
+			)
+		}
+	}
+
+	return uni, nil
+}
+
+func register(validate *validator.Validate, trans ut.Translator, translation Translation) error {
+	return validate.RegisterTranslation(
+		translation.Tag,
+		trans,
+		func(trans ut.Translator) error {
+			return trans.Add(translation.Tag, translation.Text, true)
+		},
+		func(trans ut.Translator, fErr validator.FieldError) string {
+			msg, err := trans.T(fErr.Tag(), fErr.Field(), fErr.Param())
+			if err != nil {
+				return fErr.Error()
+			}
+			return msg
+		},
+	)
+}